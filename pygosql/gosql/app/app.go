@@ -0,0 +1,236 @@
+// app.go
+package app
+
+import (
+    "context"
+    "fmt"
+    "gosql/database"
+    "gosql/server"
+    "gosql/setup"
+    "log/slog"
+    "net/http"
+    "os"
+    "sync"
+)
+
+// Options configures an App
+type Options struct {
+    Config   setup.Config       // Server configuration, usually starting from setup.DefaultConfig()
+    DB       *database.Database // Database to serve; opened from Config, with its schema applied, when nil
+    Scaffold bool               // Whether to create the SQL directory layout and a folder per table first
+}
+
+// App is a PyGoSQL instance: the database, the endpoints built from the SQL files and
+// the server routing requests to them
+// Its Handler can be mounted in another router, or Start serves it on the configured
+// listeners
+type App struct {
+    config setup.Config       // Configuration the app was built with
+    db     *database.Database // Database the endpoints run against
+    ownsDB bool               // Whether the app opened db and closes it
+    lock   *server.Lock       // Lock file on the database, released by Close
+    server *server.Server     // Routes requests to the endpoints
+    reload sync.Mutex         // Serializes Reload
+}
+
+// New validates the configuration, locks and opens the database, loads the endpoints
+// under Config.SQLRoot and builds the server
+// A database passed in Options.DB is neither locked nor opened
+func New(opts Options) (*App, error) {
+    cfg := opts.Config
+    if err := cfg.Validate(); err != nil {
+        return nil, fmt.Errorf("invalid configuration: %w", err)
+    }
+
+    a := &App{config: cfg, db: opts.DB}
+    if a.db == nil {
+        // Lock the database before anything opens it, so a second server leaves it alone
+        lock, err := server.AcquireLock(cfg)
+        if err != nil {
+            return nil, err
+        }
+        a.lock = lock
+    }
+
+    if opts.Scaffold {
+        if err := Scaffold(cfg); err != nil {
+            a.Close()
+            return nil, err
+        }
+    }
+
+    if a.db == nil {
+        db, err := OpenDatabase(cfg)
+        if err != nil {
+            a.Close()
+            return nil, err
+        }
+        a.db, a.ownsDB = db, true
+    }
+
+    endpoints, err := LoadEndpoints(cfg, a.db)
+    if err != nil {
+        a.Close()
+        return nil, err
+    }
+
+    srv, err := server.NewServer(cfg, endpoints, a.db)
+    if err != nil {
+        a.Close()
+        return nil, err
+    }
+    a.server = srv
+    return a, nil
+}
+
+// Scaffold creates the SQL directory layout and a folder with default SQL files for
+// each table declared in the schema
+func Scaffold(cfg setup.Config) error {
+    dir := setup.NewDir(cfg.SQLRoot)
+    if err := dir.MakeDirs(); err != nil {
+        return fmt.Errorf("failed to create directories: %w", err)
+    }
+
+    tables, err := dir.DiscoverTables()
+    if err != nil {
+        return fmt.Errorf("failed to discover tables: %w", err)
+    }
+    if len(tables) == 0 {
+        slog.Warn("no tables found in schema", "schema", cfg.SchemaPath)
+        return nil
+    }
+    slog.Info("found tables", "count", len(tables), "tables", tables)
+    if err := dir.CreateTableDirs(tables); err != nil {
+        return fmt.Errorf("failed to create table directories: %w", err)
+    }
+    return nil
+}
+
+// OpenDatabase opens the configured database and applies the schema file
+// A missing or empty schema file is logged and skipped
+func OpenDatabase(cfg setup.Config) (*database.Database, error) {
+    var schemaContent string
+    if cfg.SchemaPath == "" {
+        slog.Warn("no schema path configured")
+    } else if _, err := os.Stat(cfg.SchemaPath); os.IsNotExist(err) {
+        slog.Warn("schema file does not exist", "schema", cfg.SchemaPath)
+    } else if schemaFile, err := database.LoadSQL(cfg.SchemaPath); err != nil {
+        return nil, fmt.Errorf("failed to load schema file: %w", err)
+    } else if schemaFile.IsEmpty() {
+        slog.Warn("schema file is empty", "schema", cfg.SchemaPath)
+    } else {
+        schemaContent = schemaFile.Content
+        slog.Debug("loaded schema file", "schema", schemaFile.Path, "length", len(schemaContent))
+    }
+
+    db, err := database.NewDatabase(database.Config{
+        Path:              cfg.DatabasePath,
+        CreateIfNotExists: true,
+        Schema:            schemaContent,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to initialize database: %w", err)
+    }
+    return db, nil
+}
+
+// LoadEndpoints builds an endpoint for every SQL file under cfg.SQLRoot and checks
+// that their routes can be served together
+func LoadEndpoints(cfg setup.Config, db *database.Database) ([]server.Endpoint, error) {
+    slog.Debug("discovering SQL files", "sql_root", cfg.SQLRoot)
+    sqlFiles, err := server.GlobSQLFiles(cfg.SQLRoot)
+    if err != nil {
+        return nil, err
+    }
+
+    endpoints := make([]server.Endpoint, 0, len(sqlFiles))
+    for _, sqlFile := range sqlFiles {
+        endpoints = append(endpoints, server.AssembleEndpoint(sqlFile, db, cfg.BaseURL))
+    }
+
+    // Refuse SQL files that map to the same route
+    if err := server.ValidateEndpoints(cfg, endpoints); err != nil {
+        return nil, fmt.Errorf("invalid endpoint routes: %w", err)
+    }
+    return endpoints, nil
+}
+
+// Config returns the configuration the app was built with
+func (a *App) Config() setup.Config {
+    return a.config
+}
+
+// DB returns the database the endpoints run against
+func (a *App) DB() *database.Database {
+    return a.db
+}
+
+// Server returns the underlying server, e.g. to register readiness checks
+func (a *App) Server() *server.Server {
+    return a.server
+}
+
+// Handler returns the HTTP handler serving the API, documentation and system endpoints
+// It keeps serving the current endpoints across Reload
+func (a *App) Handler() http.Handler {
+    return a.server.Handler()
+}
+
+// Endpoints returns the SQL endpoints being served
+func (a *App) Endpoints() []server.Endpoint {
+    return a.server.Endpoints()
+}
+
+// Start serves the app on the configured listeners until ctx is cancelled or the server
+// fails, then shuts down and closes the database the app opened
+// It installs no signal handlers; cancel ctx to stop
+func (a *App) Start(ctx context.Context) error {
+    err := a.server.Start(ctx)
+    if closeErr := a.Close(); err == nil {
+        err = closeErr
+    }
+    return err
+}
+
+// Shutdown stops the server, waiting for requests in flight until ctx is done, and
+// closes the database the app opened
+func (a *App) Shutdown(ctx context.Context) error {
+    err := a.server.Shutdown(ctx)
+    if closeErr := a.Close(); err == nil {
+        err = closeErr
+    }
+    return err
+}
+
+// Close closes the database when the app opened it and releases the lock on it; it is
+// safe to call more than once
+func (a *App) Close() error {
+    a.lock.Release()
+    if !a.ownsDB || a.db == nil {
+        return nil
+    }
+    return a.db.Close()
+}
+
+// Reload rescans the SQL files and swaps in the new endpoints, and reads the TLS
+// certificate again when serving HTTPS
+// Requests in flight finish on the endpoints they started with; on error the current
+// endpoints keep being served
+func (a *App) Reload() error {
+    a.reload.Lock()
+    defer a.reload.Unlock()
+
+    endpoints, err := LoadEndpoints(a.config, a.db)
+    if err != nil {
+        return err
+    }
+    if err := a.server.SetEndpoints(endpoints); err != nil {
+        return err
+    }
+    if a.config.Scheme() == "https" {
+        if err := a.server.ReloadCertificate(); err != nil {
+            return fmt.Errorf("failed to reload TLS certificate: %w", err)
+        }
+    }
+    return nil
+}
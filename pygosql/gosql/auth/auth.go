@@ -0,0 +1,117 @@
+// auth.go
+package auth
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+)
+
+// ErrMissingCredentials is returned when a request carries no API key or bearer token
+var ErrMissingCredentials = errors.New("missing credentials: send an X-API-Key header or an Authorization: Bearer token")
+
+// ErrInvalidCredentials is returned when the presented key or token is not recognized
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+    Name   string                 // Key name, or the subject of a token
+    Scopes []string               // Granted scopes, "*" grants every scope
+    Claims map[string]interface{} // Verified JWT claims, nil for API keys
+}
+
+// HasScopes reports whether the principal was granted every required scope
+func (p *Principal) HasScopes(required []string) bool {
+    for _, scope := range required {
+        if !p.hasScope(scope) {
+            return false
+        }
+    }
+    return true
+}
+
+// hasScope reports whether a single scope was granted
+func (p *Principal) hasScope(scope string) bool {
+    for _, granted := range p.Scopes {
+        if granted == "*" || granted == scope {
+            return true
+        }
+    }
+    return false
+}
+
+// MissingScopes returns the required scopes the principal was not granted
+func (p *Principal) MissingScopes(required []string) []string {
+    var missing []string
+    for _, scope := range required {
+        if !p.hasScope(scope) {
+            missing = append(missing, scope)
+        }
+    }
+    return missing
+}
+
+// Authenticator checks request credentials against a key store and, when configured, as JWTs
+type Authenticator struct {
+    Store Store        // Where hashed keys are looked up
+    JWT   *JWTVerifier // Verifies bearer JWTs, nil to accept only stored keys
+}
+
+// NewAuthenticator creates an authenticator backed by the given store
+func NewAuthenticator(store Store) *Authenticator {
+    return &Authenticator{Store: store}
+}
+
+// Authenticate returns the principal for the request's API key or bearer token
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+    credential := Credential(r)
+    if credential == "" {
+        return nil, ErrMissingCredentials
+    }
+
+    if a.JWT != nil && LooksLikeJWT(credential) {
+        claims, err := a.JWT.Verify(credential)
+        if err != nil {
+            return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+        }
+        subject, _ := claims["sub"].(string)
+        return &Principal{Name: subject, Scopes: ClaimScopes(claims), Claims: claims}, nil
+    }
+
+    key, err := a.Store.Lookup(HashKey(credential))
+    if err != nil {
+        return nil, err
+    }
+    if key == nil {
+        return nil, ErrInvalidCredentials
+    }
+    return &Principal{Name: key.Name, Scopes: key.Scopes}, nil
+}
+
+// Credential returns the API key from X-API-Key or the token from an Authorization: Bearer header
+func Credential(r *http.Request) string {
+    if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+        return key
+    }
+    scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+    if ok && strings.EqualFold(scheme, "Bearer") {
+        return strings.TrimSpace(token)
+    }
+    return ""
+}
+
+// contextKey is the type of the request context key holding the principal
+type contextKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+    return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the authenticated principal of a request, or nil
+func FromContext(ctx context.Context) *Principal {
+    principal, _ := ctx.Value(contextKey{}).(*Principal)
+    return principal
+}
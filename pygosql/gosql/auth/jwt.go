@@ -0,0 +1,339 @@
+// jwt.go
+package auth
+
+import (
+    "crypto"
+    "crypto/ed25519"
+    "crypto/hmac"
+    "crypto/rsa"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/json"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "math/big"
+    "os"
+    "strings"
+    "time"
+)
+
+// JWTKey is a key accepted for verifying token signatures
+type JWTKey struct {
+    ID  string      // Key ID matched against the token's kid header, empty matches any token
+    Key interface{} // []byte for HS256, *rsa.PublicKey for RS256, ed25519.PublicKey for EdDSA
+}
+
+// algorithm returns the JWS algorithm the key verifies
+func (k JWTKey) algorithm() string {
+    switch k.Key.(type) {
+    case []byte:
+        return "HS256"
+    case *rsa.PublicKey:
+        return "RS256"
+    case ed25519.PublicKey:
+        return "EdDSA"
+    }
+    return ""
+}
+
+// JWTVerifier validates signed JWTs and their registered claims
+type JWTVerifier struct {
+    Keys       []JWTKey      // Accepted verification keys
+    Issuer     string        // Required iss claim, empty to accept any issuer
+    Audience   string        // Required aud entry, empty to accept any audience
+    Leeway     time.Duration // Allowed clock skew for exp and nbf
+    AllowNoExp bool          // Whether tokens without an exp claim are accepted
+    Now        func() time.Time
+}
+
+// NewJWTVerifier creates a verifier for the given keys with a one minute leeway
+func NewJWTVerifier(keys []JWTKey, issuer, audience string) *JWTVerifier {
+    return &JWTVerifier{Keys: keys, Issuer: issuer, Audience: audience, Leeway: time.Minute, Now: time.Now}
+}
+
+// LooksLikeJWT reports whether a credential has the three dot separated parts of a compact JWS
+func LooksLikeJWT(credential string) bool {
+    return strings.Count(credential, ".") == 2
+}
+
+// Verify checks the token's signature and its sub, time, issuer and audience claims and
+// returns its claims
+func (v *JWTVerifier) Verify(token string) (map[string]interface{}, error) {
+    parts := strings.Split(token, ".")
+    if len(parts) != 3 {
+        return nil, errors.New("token is not a compact JWS")
+    }
+
+    var header struct {
+        Alg string `json:"alg"`
+        Kid string `json:"kid"`
+    }
+    if err := decodeSegment(parts[0], &header); err != nil {
+        return nil, fmt.Errorf("invalid token header: %w", err)
+    }
+    signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+    if err != nil {
+        return nil, fmt.Errorf("invalid token signature encoding: %w", err)
+    }
+
+    signed := []byte(parts[0] + "." + parts[1])
+    verified := false
+    for _, key := range v.Keys {
+        // The key decides the algorithm so a token cannot pick a weaker one
+        if key.algorithm() != header.Alg || (key.ID != "" && header.Kid != "" && key.ID != header.Kid) {
+            continue
+        }
+        if verifySignature(key, signed, signature) {
+            verified = true
+            break
+        }
+    }
+    if !verified {
+        return nil, fmt.Errorf("token signature could not be verified for alg %q", header.Alg)
+    }
+
+    var claims map[string]interface{}
+    if err := decodeSegment(parts[1], &claims); err != nil {
+        return nil, fmt.Errorf("invalid token claims: %w", err)
+    }
+    if err := v.validateClaims(claims); err != nil {
+        return nil, err
+    }
+    return claims, nil
+}
+
+// verifySignature checks a signature with one key
+func verifySignature(key JWTKey, signed, signature []byte) bool {
+    switch k := key.Key.(type) {
+    case []byte:
+        mac := hmac.New(sha256.New, k)
+        mac.Write(signed)
+        return hmac.Equal(mac.Sum(nil), signature)
+    case *rsa.PublicKey:
+        digest := sha256.Sum256(signed)
+        return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+    case ed25519.PublicKey:
+        return ed25519.Verify(k, signed, signature)
+    }
+    return false
+}
+
+// validateClaims checks sub, exp, nbf, iss and aud
+// The subject names the caller in auth_sub and the logs, so it is required, as is exp
+// unless AllowNoExp is set
+func (v *JWTVerifier) validateClaims(claims map[string]interface{}) error {
+    if sub, _ := claims["sub"].(string); sub == "" {
+        return errors.New("token has no sub claim")
+    }
+
+    now := v.Now()
+    switch exp := claims["exp"].(type) {
+    case float64:
+        if now.After(time.Unix(int64(exp), 0).Add(v.Leeway)) {
+            return errors.New("token has expired")
+        }
+    case nil:
+        if !v.AllowNoExp {
+            return errors.New("token has no exp claim")
+        }
+    default:
+        return errors.New("token exp claim is not a number")
+    }
+    switch nbf := claims["nbf"].(type) {
+    case float64:
+        if now.Add(v.Leeway).Before(time.Unix(int64(nbf), 0)) {
+            return errors.New("token is not valid yet")
+        }
+    case nil:
+    default:
+        return errors.New("token nbf claim is not a number")
+    }
+    if v.Issuer != "" && claims["iss"] != v.Issuer {
+        return fmt.Errorf("token issuer %v is not %q", claims["iss"], v.Issuer)
+    }
+    if v.Audience != "" {
+        matched := false
+        switch aud := claims["aud"].(type) {
+        case string:
+            matched = aud == v.Audience
+        case []interface{}:
+            for _, item := range aud {
+                matched = matched || item == v.Audience
+            }
+        }
+        if !matched {
+            return fmt.Errorf("token audience does not include %q", v.Audience)
+        }
+    }
+    return nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, target interface{}) error {
+    data, err := base64.RawURLEncoding.DecodeString(segment)
+    if err != nil {
+        return err
+    }
+    return json.Unmarshal(data, target)
+}
+
+// ClaimScopes returns the scopes granted by a token's scope, scp or scopes claim
+func ClaimScopes(claims map[string]interface{}) []string {
+    for _, name := range []string{"scope", "scp", "scopes"} {
+        switch value := claims[name].(type) {
+        case string:
+            return strings.Fields(value)
+        case []interface{}:
+            scopes := make([]string, 0, len(value))
+            for _, item := range value {
+                if scope, ok := item.(string); ok {
+                    scopes = append(scopes, scope)
+                }
+            }
+            return scopes
+        }
+    }
+    return nil
+}
+
+// LoadJWTKeys reads verification keys from a JWKS or JWK JSON file, or from PEM encoded
+// public keys and certificates
+func LoadJWTKeys(path string) ([]JWTKey, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read JWT key file: %w", err)
+    }
+
+    if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+        keys, err := parseJWKS(data)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse JWKS file %s: %w", path, err)
+        }
+        return keys, nil
+    }
+
+    var keys []JWTKey
+    for rest := data; ; {
+        var block *pem.Block
+        block, rest = pem.Decode(rest)
+        if block == nil {
+            break
+        }
+        key, err := parsePEMKey(block)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse %s in %s: %w", block.Type, path, err)
+        }
+        keys = append(keys, JWTKey{Key: key})
+    }
+    if len(keys) == 0 {
+        return nil, fmt.Errorf("no keys found in %s", path)
+    }
+    return keys, nil
+}
+
+// parsePEMKey parses a PEM public key or certificate into an RSA or Ed25519 public key
+func parsePEMKey(block *pem.Block) (interface{}, error) {
+    var key interface{}
+    var err error
+    switch block.Type {
+    case "PUBLIC KEY":
+        key, err = x509.ParsePKIXPublicKey(block.Bytes)
+    case "RSA PUBLIC KEY":
+        key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+    case "CERTIFICATE":
+        var cert *x509.Certificate
+        if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+            key = cert.PublicKey
+        }
+    default:
+        return nil, errors.New("unsupported PEM block, expected a public key or certificate")
+    }
+    if err != nil {
+        return nil, err
+    }
+    switch key.(type) {
+    case *rsa.PublicKey, ed25519.PublicKey:
+        return key, nil
+    }
+    return nil, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", key)
+}
+
+// jwk is a JSON Web Key as found in a JWKS document
+type jwk struct {
+    Kty string `json:"kty"`
+    Kid string `json:"kid"`
+    Use string `json:"use"`
+    Crv string `json:"crv"`
+    N   string `json:"n"`
+    E   string `json:"e"`
+    X   string `json:"x"`
+    K   string `json:"k"`
+}
+
+// parseJWKS parses a {"keys": [...]} document or a single JWK, skipping keys not used for signing
+func parseJWKS(data []byte) ([]JWTKey, error) {
+    var set struct {
+        Keys []jwk `json:"keys"`
+    }
+    if err := json.Unmarshal(data, &set); err != nil {
+        return nil, err
+    }
+    if set.Keys == nil {
+        var single jwk
+        if err := json.Unmarshal(data, &single); err != nil {
+            return nil, err
+        }
+        set.Keys = []jwk{single}
+    }
+
+    var keys []JWTKey
+    for i, k := range set.Keys {
+        if k.Use != "" && k.Use != "sig" {
+            continue
+        }
+        key, err := k.publicKey()
+        if err != nil {
+            return nil, fmt.Errorf("key %d (%s): %w", i, k.Kid, err)
+        }
+        keys = append(keys, JWTKey{ID: k.Kid, Key: key})
+    }
+    if len(keys) == 0 {
+        return nil, errors.New("no signing keys found")
+    }
+    return keys, nil
+}
+
+// publicKey converts a JWK into a verification key
+func (k jwk) publicKey() (interface{}, error) {
+    decode := base64.RawURLEncoding.DecodeString
+    switch k.Kty {
+    case "RSA":
+        n, err := decode(k.N)
+        if err != nil {
+            return nil, fmt.Errorf("invalid modulus: %w", err)
+        }
+        e, err := decode(k.E)
+        if err != nil {
+            return nil, fmt.Errorf("invalid exponent: %w", err)
+        }
+        return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+    case "OKP":
+        if k.Crv != "Ed25519" {
+            return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+        }
+        x, err := decode(k.X)
+        if err != nil || len(x) != ed25519.PublicKeySize {
+            return nil, errors.New("invalid Ed25519 public key")
+        }
+        return ed25519.PublicKey(x), nil
+    case "oct":
+        secret, err := decode(k.K)
+        if err != nil || len(secret) == 0 {
+            return nil, errors.New("invalid symmetric key")
+        }
+        return secret, nil
+    }
+    return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
@@ -0,0 +1,187 @@
+// jwt_test.go
+package auth
+
+import (
+    "crypto"
+    "crypto/ed25519"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+)
+
+// testNow is the fixed clock the verifiers in these tests run at
+var testNow = time.Unix(1700000000, 0)
+
+// signToken builds a compact JWS over the header and claims, signed with key for alg
+// A nil key leaves the signature empty, as in alg "none" tokens
+func signToken(t *testing.T, header, claims map[string]interface{}, key interface{}) string {
+    t.Helper()
+    encode := func(value interface{}) string {
+        data, err := json.Marshal(value)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return base64.RawURLEncoding.EncodeToString(data)
+    }
+    signed := encode(header) + "." + encode(claims)
+
+    var signature []byte
+    switch k := key.(type) {
+    case []byte:
+        mac := hmac.New(sha256.New, k)
+        mac.Write([]byte(signed))
+        signature = mac.Sum(nil)
+    case *rsa.PrivateKey:
+        digest := sha256.Sum256([]byte(signed))
+        var err error
+        if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+            t.Fatal(err)
+        }
+    case ed25519.PrivateKey:
+        signature = ed25519.Sign(k, []byte(signed))
+    }
+    return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validClaims returns claims every verifier in these tests accepts
+func validClaims() map[string]interface{} {
+    return map[string]interface{}{
+        "sub": "alice",
+        "exp": float64(testNow.Add(time.Hour).Unix()),
+    }
+}
+
+func TestVerifySignatures(t *testing.T) {
+    secret := []byte("test-secret")
+    rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatal(err)
+    }
+    otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatal(err)
+    }
+    edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    verifier := NewJWTVerifier([]JWTKey{
+        {ID: "hs", Key: secret},
+        {ID: "rs", Key: &rsaKey.PublicKey},
+        {ID: "ed", Key: edPublic},
+    }, "", "")
+    verifier.Now = func() time.Time { return testNow }
+
+    tests := []struct {
+        name   string
+        header map[string]interface{}
+        key    interface{}
+        ok     bool
+    }{
+        {"HS256", map[string]interface{}{"alg": "HS256"}, secret, true},
+        {"HS256 with kid", map[string]interface{}{"alg": "HS256", "kid": "hs"}, secret, true},
+        {"RS256", map[string]interface{}{"alg": "RS256", "kid": "rs"}, rsaKey, true},
+        {"EdDSA", map[string]interface{}{"alg": "EdDSA"}, edPrivate, true},
+        {"HS256 wrong secret", map[string]interface{}{"alg": "HS256"}, []byte("other"), false},
+        {"RS256 unknown key", map[string]interface{}{"alg": "RS256"}, otherRSAKey, false},
+        {"kid of another key", map[string]interface{}{"alg": "RS256", "kid": "ed"}, rsaKey, false},
+        {"unknown kid", map[string]interface{}{"alg": "HS256", "kid": "missing"}, secret, false},
+        {"alg does not match key", map[string]interface{}{"alg": "RS256", "kid": "hs"}, secret, false},
+        {"EdDSA signature as HS256", map[string]interface{}{"alg": "HS256"}, edPrivate, false},
+        {"alg none", map[string]interface{}{"alg": "none"}, nil, false},
+        {"alg none with kid", map[string]interface{}{"alg": "none", "kid": "hs"}, nil, false},
+        {"empty alg", map[string]interface{}{}, nil, false},
+    }
+    for _, test := range tests {
+        token := signToken(t, test.header, validClaims(), test.key)
+        claims, err := verifier.Verify(token)
+        if test.ok && (err != nil || claims["sub"] != "alice") {
+            t.Errorf("%s: expected the token to verify, got %v", test.name, err)
+        }
+        if !test.ok && err == nil {
+            t.Errorf("%s: expected the token to be rejected", test.name)
+        }
+    }
+}
+
+func TestVerifyClaims(t *testing.T) {
+    secret := []byte("test-secret")
+    at := func(offset time.Duration) float64 { return float64(testNow.Add(offset).Unix()) }
+
+    tests := []struct {
+        name       string
+        claims     map[string]interface{}
+        issuer     string
+        audience   string
+        allowNoExp bool
+        err        string
+    }{
+        {"valid", validClaims(), "", "", false, ""},
+        {"missing sub", map[string]interface{}{"exp": at(time.Hour)}, "", "", false, "no sub claim"},
+        {"empty sub", map[string]interface{}{"sub": "", "exp": at(time.Hour)}, "", "", false, "no sub claim"},
+        {"numeric sub", map[string]interface{}{"sub": 7, "exp": at(time.Hour)}, "", "", false, "no sub claim"},
+        {"missing exp", map[string]interface{}{"sub": "alice"}, "", "", false, "no exp claim"},
+        {"missing exp allowed", map[string]interface{}{"sub": "alice"}, "", "", true, ""},
+        {"string exp", map[string]interface{}{"sub": "alice", "exp": "never"}, "", "", true, "exp claim is not a number"},
+        {"expired", map[string]interface{}{"sub": "alice", "exp": at(-2 * time.Minute)}, "", "", false, "expired"},
+        {"expired within leeway", map[string]interface{}{"sub": "alice", "exp": at(-30 * time.Second)}, "", "", false, ""},
+        {"not valid yet", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "nbf": at(2 * time.Minute)}, "", "", false, "not valid yet"},
+        {"nbf within leeway", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "nbf": at(30 * time.Second)}, "", "", false, ""},
+        {"string nbf", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "nbf": "later"}, "", "", false, "nbf claim is not a number"},
+        {"issuer matches", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "iss": "https://issuer"}, "https://issuer", "", false, ""},
+        {"issuer differs", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "iss": "https://other"}, "https://issuer", "", false, "issuer"},
+        {"issuer missing", validClaims(), "https://issuer", "", false, "issuer"},
+        {"audience string", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "aud": "gosql"}, "", "gosql", false, ""},
+        {"audience list", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "aud": []string{"other", "gosql"}}, "", "gosql", false, ""},
+        {"audience differs", map[string]interface{}{"sub": "alice", "exp": at(time.Hour), "aud": []string{"other"}}, "", "gosql", false, "audience"},
+        {"audience missing", validClaims(), "", "gosql", false, "audience"},
+    }
+    for _, test := range tests {
+        verifier := NewJWTVerifier([]JWTKey{{Key: secret}}, test.issuer, test.audience)
+        verifier.AllowNoExp = test.allowNoExp
+        verifier.Now = func() time.Time { return testNow }
+
+        _, err := verifier.Verify(signToken(t, map[string]interface{}{"alg": "HS256"}, test.claims, secret))
+        switch {
+        case test.err == "" && err != nil:
+            t.Errorf("%s: unexpected error %v", test.name, err)
+        case test.err != "" && err == nil:
+            t.Errorf("%s: expected an error containing %q", test.name, test.err)
+        case test.err != "" && !strings.Contains(err.Error(), test.err):
+            t.Errorf("%s: error %q does not contain %q", test.name, err, test.err)
+        }
+    }
+}
+
+func TestVerifyRejectsMalformedTokens(t *testing.T) {
+    verifier := NewJWTVerifier([]JWTKey{{Key: []byte("test-secret")}}, "", "")
+    for _, token := range []string{"", "a.b", "a.b.c.d", "!!.e30.", "e30.e30.!!"} {
+        if _, err := verifier.Verify(token); err == nil {
+            t.Errorf("Verify(%q): expected an error", token)
+        }
+    }
+}
+
+func TestClaimScopes(t *testing.T) {
+    tests := []struct {
+        claims map[string]interface{}
+        want   string
+    }{
+        {map[string]interface{}{"scope": "read write"}, "read write"},
+        {map[string]interface{}{"scp": []interface{}{"read", 7, "write"}}, "read write"},
+        {map[string]interface{}{"scopes": []interface{}{"admin"}}, "admin"},
+        {map[string]interface{}{"sub": "alice"}, ""},
+    }
+    for _, test := range tests {
+        if got := strings.Join(ClaimScopes(test.claims), " "); got != test.want {
+            t.Errorf("ClaimScopes(%v) = %q, want %q", test.claims, got, test.want)
+        }
+    }
+}
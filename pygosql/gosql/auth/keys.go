@@ -0,0 +1,115 @@
+// keys.go
+package auth
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "gosql/database"
+    "os"
+    "strings"
+)
+
+// KeysTable is the database table holding hashed API keys
+//
+//  CREATE TABLE gosql_keys (
+//      name     TEXT PRIMARY KEY,
+//      key_hash TEXT NOT NULL UNIQUE,  -- sha256:<hex>
+//      scopes   TEXT NOT NULL DEFAULT '', -- space separated
+//      revoked  INTEGER NOT NULL DEFAULT 0
+//  );
+const KeysTable = "gosql_keys"
+
+// Key is a stored API key or static bearer token
+type Key struct {
+    Name   string   `json:"name"`   // Identifies the key in logs
+    Hash   string   `json:"hash"`   // HashKey of the secret, never the secret itself
+    Scopes []string `json:"scopes"` // Granted scopes, "*" grants every scope
+}
+
+// HashKey returns the stored form of a secret: "sha256:" followed by the hex digest
+func HashKey(secret string) string {
+    sum := sha256.Sum256([]byte(secret))
+    return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Store looks up keys by hash, returning nil when no key matches
+type Store interface {
+    Lookup(hash string) (*Key, error)
+}
+
+// FileStore holds keys loaded from a JSON key file
+type FileStore struct {
+    keys map[string]*Key
+}
+
+// LoadKeyFile reads a JSON key file of the form {"keys": [{"name", "hash", "scopes"}]}
+func LoadKeyFile(path string) (*FileStore, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read key file: %w", err)
+    }
+
+    var file struct {
+        Keys []*Key `json:"keys"`
+    }
+    if err := json.Unmarshal(data, &file); err != nil {
+        return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+    }
+
+    store := &FileStore{keys: make(map[string]*Key)}
+    for i, key := range file.Keys {
+        if !strings.HasPrefix(key.Hash, "sha256:") || len(key.Hash) != len("sha256:")+64 {
+            return nil, fmt.Errorf("key file %s: key %d (%s) must have a hash of the form sha256:<64 hex digits>", path, i, key.Name)
+        }
+        key.Hash = strings.ToLower(key.Hash)
+        store.keys[key.Hash] = key
+    }
+    return store, nil
+}
+
+// Lookup returns the key with the given hash
+func (s *FileStore) Lookup(hash string) (*Key, error) {
+    return s.keys[hash], nil
+}
+
+// TableStore looks keys up in the gosql_keys table on every request, so keys
+// added or revoked in the database take effect immediately
+type TableStore struct {
+    DB *database.Database
+}
+
+// Lookup returns the unrevoked key with the given hash, or nil when the table does not exist
+func (s *TableStore) Lookup(hash string) (*Key, error) {
+    rows, err := s.DB.QueryRows("SELECT name, scopes FROM "+KeysTable+" WHERE key_hash = ? AND revoked = 0", hash)
+    if err != nil {
+        if strings.Contains(err.Error(), "no such table") {
+            return nil, nil
+        }
+        return nil, fmt.Errorf("failed to look up key: %w", err)
+    }
+    if len(rows) == 0 {
+        return nil, nil
+    }
+
+    key := &Key{Name: fmt.Sprint(rows[0]["name"]), Hash: hash}
+    if scopes, ok := rows[0]["scopes"].(string); ok {
+        key.Scopes = strings.Fields(scopes)
+    }
+    return key, nil
+}
+
+// MultiStore checks several stores in order
+type MultiStore []Store
+
+// Lookup returns the first matching key
+func (m MultiStore) Lookup(hash string) (*Key, error) {
+    for _, store := range m {
+        key, err := store.Lookup(hash)
+        if err != nil || key != nil {
+            return key, err
+        }
+    }
+    return nil, nil
+}
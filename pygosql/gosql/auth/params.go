@@ -0,0 +1,52 @@
+// params.go
+package auth
+
+import (
+    "encoding/json"
+    "strings"
+)
+
+// ReservedPrefix starts the names of SQL parameters bound from the authenticated caller
+// Clients cannot set these through the query string, path or body
+const ReservedPrefix = "auth_"
+
+// IsReserved reports whether a parameter name is bound from the caller's identity
+func IsReserved(name string) bool {
+    return strings.HasPrefix(strings.ToLower(name), ReservedPrefix)
+}
+
+// Params returns the reserved SQL parameters for a principal:
+// auth_sub and auth_scopes, plus auth_<claim> for every JWT claim (e.g. auth_role)
+// Nested claim values are bound as JSON text
+func Params(principal *Principal) map[string]interface{} {
+    params := make(map[string]interface{})
+    if principal == nil {
+        return params
+    }
+
+    for name, value := range principal.Claims {
+        switch value.(type) {
+        case string, float64, bool, nil:
+        default:
+            encoded, _ := json.Marshal(value)
+            value = string(encoded)
+        }
+        params[ReservedPrefix+claimParamName(name)] = value
+    }
+    params[ReservedPrefix+"sub"] = principal.Name
+    params[ReservedPrefix+"scopes"] = strings.Join(principal.Scopes, " ")
+    return params
+}
+
+// claimParamName maps a claim name to the characters allowed in a SQL parameter name
+func claimParamName(claim string) string {
+    var b strings.Builder
+    for _, c := range claim {
+        if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+            b.WriteRune(c)
+        } else {
+            b.WriteByte('_')
+        }
+    }
+    return b.String()
+}
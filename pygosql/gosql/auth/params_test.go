@@ -0,0 +1,58 @@
+// params_test.go
+package auth
+
+import "testing"
+
+func TestIsReserved(t *testing.T) {
+    tests := []struct {
+        name     string
+        reserved bool
+    }{
+        {"auth_sub", true},
+        {"AUTH_role", true},
+        {"Auth_Scopes", true},
+        {"author", false},
+        {"user_auth_id", false},
+        {"id", false},
+    }
+    for _, test := range tests {
+        if got := IsReserved(test.name); got != test.reserved {
+            t.Errorf("IsReserved(%q) = %v, want %v", test.name, got, test.reserved)
+        }
+    }
+}
+
+func TestParams(t *testing.T) {
+    principal := &Principal{
+        Name:   "alice",
+        Scopes: []string{"read", "write"},
+        Claims: map[string]interface{}{
+            "sub":          "spoofed",
+            "role":         "admin",
+            "tenant-id":    float64(7),
+            "groups":       []interface{}{"a", "b"},
+            "https://x/ns": true,
+        },
+    }
+    params := Params(principal)
+
+    want := map[string]interface{}{
+        "auth_sub":          "alice",
+        "auth_scopes":       "read write",
+        "auth_role":         "admin",
+        "auth_tenant_id":    float64(7),
+        "auth_groups":       `["a","b"]`,
+        "auth_https___x_ns": true,
+    }
+    if len(params) != len(want) {
+        t.Errorf("Params returned %v, want %v", params, want)
+    }
+    for name, value := range want {
+        if params[name] != value {
+            t.Errorf("%s = %v, want %v", name, params[name], value)
+        }
+    }
+    if len(Params(nil)) != 0 {
+        t.Errorf("Params(nil) = %v, want no parameters", Params(nil))
+    }
+}
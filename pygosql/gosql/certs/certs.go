@@ -0,0 +1,161 @@
+// certs.go
+package certs
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/tls"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "fmt"
+    "math/big"
+    "net"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+// Reloader serves a certificate loaded from files and reloads it on request,
+// so certificates can be rotated without restarting the server
+type Reloader struct {
+    certFile string
+    keyFile  string
+
+    mu   sync.RWMutex
+    cert *tls.Certificate
+}
+
+// NewReloader loads the certificate and key pair once and returns a reloader for them
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+    r := &Reloader{certFile: certFile, keyFile: keyFile}
+    if err := r.Reload(); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+// Reload reads the certificate files again, keeping the current certificate on failure
+func (r *Reloader) Reload() error {
+    cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+    if err != nil {
+        return fmt.Errorf("failed to load TLS certificate %s: %w", r.certFile, err)
+    }
+
+    r.mu.Lock()
+    r.cert = &cert
+    r.mu.Unlock()
+    return nil
+}
+
+// GetCertificate returns the current certificate for tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.cert, nil
+}
+
+// ParseVersion converts "1.0" through "1.3" to a crypto/tls version constant
+func ParseVersion(version string) (uint16, error) {
+    switch version {
+    case "", "1.2":
+        return tls.VersionTLS12, nil
+    case "1.3":
+        return tls.VersionTLS13, nil
+    case "1.1":
+        return tls.VersionTLS11, nil
+    case "1.0":
+        return tls.VersionTLS10, nil
+    }
+    return 0, fmt.Errorf("unsupported TLS version %q (use 1.0, 1.1, 1.2 or 1.3)", version)
+}
+
+// LoadClientCAs reads PEM certificates trusted to sign client certificates
+func LoadClientCAs(path string) (*x509.CertPool, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read client CA file: %w", err)
+    }
+    pool := x509.NewCertPool()
+    if !pool.AppendCertsFromPEM(data) {
+        return nil, fmt.Errorf("no certificates found in client CA file %s", path)
+    }
+    return pool, nil
+}
+
+// EnsureSelfSigned returns the paths of a self-signed localhost certificate in dir,
+// generating one when it is missing or expires within a week
+func EnsureSelfSigned(dir string) (certFile, keyFile string, generated bool, err error) {
+    certFile = filepath.Join(dir, "localhost.crt")
+    keyFile = filepath.Join(dir, "localhost.key")
+
+    if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+        if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && time.Until(leaf.NotAfter) > 7*24*time.Hour {
+            return certFile, keyFile, false, nil
+        }
+    }
+
+    if err := os.MkdirAll(dir, 0700); err != nil {
+        return "", "", false, fmt.Errorf("failed to create certificate directory: %w", err)
+    }
+    if err := GenerateSelfSigned(certFile, keyFile, []string{"localhost", "127.0.0.1", "::1"}); err != nil {
+        return "", "", false, err
+    }
+    return certFile, keyFile, true, nil
+}
+
+// GenerateSelfSigned writes a one year ECDSA P-256 certificate valid for the given host names and IPs
+func GenerateSelfSigned(certFile, keyFile string, hosts []string) error {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return fmt.Errorf("failed to generate key: %w", err)
+    }
+
+    serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+    if err != nil {
+        return fmt.Errorf("failed to generate serial number: %w", err)
+    }
+
+    template := &x509.Certificate{
+        SerialNumber:          serial,
+        Subject:               pkix.Name{CommonName: hosts[0], Organization: []string{"GoSQL self-signed"}},
+        NotBefore:             time.Now().Add(-time.Hour),
+        NotAfter:              time.Now().AddDate(1, 0, 0),
+        KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+        ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+        BasicConstraintsValid: true,
+        IsCA:                  true,
+    }
+    for _, host := range hosts {
+        if ip := net.ParseIP(host); ip != nil {
+            template.IPAddresses = append(template.IPAddresses, ip)
+        } else {
+            template.DNSNames = append(template.DNSNames, host)
+        }
+    }
+
+    der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+    if err != nil {
+        return fmt.Errorf("failed to create certificate: %w", err)
+    }
+    keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+    if err != nil {
+        return fmt.Errorf("failed to encode key: %w", err)
+    }
+
+    if err := writePEM(keyFile, "PRIVATE KEY", keyDER, 0600); err != nil {
+        return err
+    }
+    return writePEM(certFile, "CERTIFICATE", der, 0644)
+}
+
+// writePEM writes a single PEM block to a file with the given permissions
+func writePEM(path, blockType string, der []byte, mode os.FileMode) error {
+    data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+    if err := os.WriteFile(path, data, mode); err != nil {
+        return fmt.Errorf("failed to write %s: %w", path, err)
+    }
+    return nil
+}
@@ -0,0 +1,320 @@
+// check.go
+package cli
+
+import (
+    "errors"
+    "fmt"
+    "gosql/database"
+    "gosql/server"
+    "gosql/setup"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// Problem is something check found wrong with a SQL, schema or migration file
+type Problem struct {
+    File    string `json:"file"`           // File the problem is in
+    Line    int    `json:"line,omitempty"` // 1-based line, 0 when it applies to the whole file
+    Message string `json:"message"`        // What is wrong
+}
+
+// String formats the problem as "file:line: message"
+func (p Problem) String() string {
+    if p.Line > 0 {
+        return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+    }
+    return fmt.Sprintf("%s: %s", p.File, p.Message)
+}
+
+// runCheck applies the schema and migrations to a scratch in-memory database and checks
+// every SQL file against it, reporting each problem instead of stopping at the first
+// The configured database is never opened
+func runCheck(args []string) int {
+    flags := newFlagSet("check")
+    config := addConfigFlags(flags)
+    asJSON := flags.Bool("json", false, "Print the problems as a JSON array")
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+    if len(positional) > 0 {
+        return usageError("check", "unexpected argument %q", positional[0])
+    }
+
+    problems, checked, err := checkProject(cfg)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+
+    if *asJSON {
+        if problems == nil {
+            problems = []Problem{}
+        }
+        if code := printJSON(problems); code != ExitOK {
+            return code
+        }
+    } else {
+        for _, problem := range problems {
+            fmt.Println(problem)
+        }
+    }
+    fmt.Fprintf(os.Stderr, "checked %d SQL file(s): %d problem(s)\n", checked, len(problems))
+    if len(problems) > 0 {
+        return ExitFailure
+    }
+    return ExitOK
+}
+
+
+// checkProject returns the problems in the project and the number of SQL files checked
+// The error is only set when checking itself failed, e.g. the SQL root is unreadable
+func checkProject(cfg setup.Config) ([]Problem, int, error) {
+    db, err := database.NewDatabase(database.Config{Path: database.MemoryPath})
+    if err != nil {
+        return nil, 0, err
+    }
+    defer db.Close()
+
+    // The scratch database gets the schema and then every migration, as a new install would
+    var problems []Problem
+    if cfg.SchemaPath != "" {
+        content, err := os.ReadFile(cfg.SchemaPath)
+        switch {
+        case os.IsNotExist(err):
+        case err != nil:
+            problems = append(problems, Problem{File: cfg.SchemaPath, Message: err.Error()})
+        default:
+            problems = append(problems, applyStatements(db, cfg.SchemaPath, string(content))...)
+        }
+    }
+    migrations, err := database.LoadMigrations(cfg.MigrationsPath())
+    if err != nil {
+        problems = append(problems, Problem{File: cfg.MigrationsPath(), Message: err.Error()})
+    }
+    for _, migration := range migrations {
+        content, err := os.ReadFile(migration.UpPath)
+        if err != nil {
+            problems = append(problems, Problem{File: migration.UpPath, Message: err.Error()})
+            continue
+        }
+        problems = append(problems, applyStatements(db, migration.UpPath, string(content))...)
+    }
+
+    sqlFiles, err := server.GlobSQLFiles(cfg.SQLRoot)
+    if err != nil {
+        return nil, 0, err
+    }
+    endpoints := make([]server.Endpoint, 0, len(sqlFiles))
+    for _, sqlFile := range sqlFiles {
+        // Described without a database, so statements that do not compile are reported
+        // once, by checkSQLFile
+        endpoint := server.AssembleEndpoint(sqlFile, nil, cfg.BaseURL)
+        endpoints = append(endpoints, endpoint)
+        if endpoint.LoadError != "" {
+            problems = append(problems, Problem{File: sqlFile, Message: endpoint.LoadError})
+            continue
+        }
+        if cfg.SchemaPath != "" && filepath.Clean(sqlFile) == filepath.Clean(cfg.SchemaPath) {
+            problems = append(problems, Problem{
+                File:    sqlFile,
+                Message: fmt.Sprintf("the schema is under the SQL root, so it is also served as %s %s", endpoint.Method, endpoint.Path),
+            })
+            continue
+        }
+        problems = append(problems, checkSQLFile(db, endpoint)...)
+    }
+
+    var conflicts *server.RouteConflictError
+    if errors.As(server.ValidateEndpoints(cfg, endpoints), &conflicts) {
+        for _, conflict := range conflicts.Conflicts {
+            for i, source := range conflict.Sources {
+                method, file, _ := strings.Cut(source, " ")
+                if strings.HasSuffix(file, "(system endpoint)") {
+                    continue
+                }
+                others := append(append([]string{}, conflict.Sources[:i]...), conflict.Sources[i+1:]...)
+                problems = append(problems, Problem{
+                    File:    file,
+                    Message: fmt.Sprintf("%s %s: %s with %s", method, conflict.Path, conflict.Reason, strings.Join(others, ", ")),
+                })
+            }
+        }
+    }
+    return problems, len(sqlFiles), nil
+}
+
+// applyStatements runs each statement of a schema or migration file on the scratch
+// database, reporting the ones that fail
+func applyStatements(db *database.Database, path, content string) []Problem {
+    var problems []Problem
+    for _, statement := range database.SplitStatements(content) {
+        if _, err := db.GetConnection().Exec(statement.SQL); err != nil {
+            message := database.ErrorMessage(err)
+            problems = append(problems, Problem{File: path, Line: statement.Line + errorLine(statement.SQL, message), Message: message})
+        }
+    }
+    return problems
+}
+
+var templatePattern = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// checkSQLFile expands an endpoint's SQL file with server.ProcessSQLTemplate, as the
+// server does, and compiles each statement against the scratch database; it also checks
+// that GET files do not write, that {{variables}} are built in or declared, and that
+// declared and path parameters are used
+func checkSQLFile(db *database.Database, endpoint server.Endpoint) []Problem {
+    var problems []Problem
+    report := func(line int, format string, args ...interface{}) {
+        problems = append(problems, Problem{File: endpoint.SQLPath, Line: line, Message: fmt.Sprintf(format, args...)})
+    }
+    content, err := os.ReadFile(endpoint.SQLPath)
+    if err != nil {
+        report(0, "%v", err)
+        return problems
+    }
+    text := string(content)
+
+    // The request is stood in for by a value for every parameter and, when the file
+    // builds {{columns}} and friends from the body, for every column the client may send
+    params := make(map[string]interface{})
+    for _, param := range endpoint.Params {
+        params[param.Name] = "1"
+    }
+    var columnNames []string
+    if endpoint.BodyColumns && endpoint.TableName != "" {
+        columns, _ := db.TableColumns(endpoint.TableName)
+        for _, column := range columns {
+            columnNames = append(columnNames, column.Name)
+            if !column.PrimaryKey || !strings.Contains(strings.ToUpper(column.Type), "INT") {
+                params[column.Name] = "1"
+            }
+        }
+    }
+
+    declared := make(map[string]bool)
+    for _, param := range declaredParams(text) {
+        declared[param.name] = true
+    }
+    used := make(map[string]bool)
+    for _, match := range templatePattern.FindAllStringSubmatchIndex(text, -1) {
+        name := text[match[2]:match[3]]
+        if used[name] {
+            continue
+        }
+        used[name] = true
+        if !server.IsTemplateVariable(name) && !declared[name] {
+            report(lineOf(text, match[0]), "{{%s}} is neither a built-in template variable nor declared with @param", name)
+        }
+    }
+
+    // Statements the server cannot fully expand fail at runtime, so they are reported
+    // rather than compiled
+    expanded, _ := server.ProcessSQLTemplate(text, endpoint.TableName, columnNames, params)
+    unexpanded := make(map[string]bool)
+    for _, statement := range database.SplitStatements(expanded) {
+        if left := templatePattern.FindAllStringSubmatch(statement.SQL, -1); len(left) > 0 {
+            for _, match := range left {
+                if unexpanded[match[1]] {
+                    continue
+                }
+                unexpanded[match[1]] = true
+                if match[1] == "table" && endpoint.TableName == "" {
+                    report(statement.Line, "{{table}} is only filled in for files under Tables/<table>/")
+                } else {
+                    report(statement.Line, "{{%s}} is not filled in by the server", match[1])
+                }
+            }
+            continue
+        }
+        writes, err := db.CompileSQL(statement.SQL)
+        if err != nil {
+            message := database.ErrorMessage(err)
+            report(statement.Line+errorLine(statement.SQL, message), "%s", message)
+            continue
+        }
+        if writes && endpoint.Method == "GET" {
+            report(statement.Line, "GET endpoint writes to the database; serve it as POST, PUT or DELETE")
+        }
+    }
+
+    // Parameters that feed {{columns}}, {{values}} and {{updates}} need not appear in the SQL
+    if !endpoint.BodyColumns {
+        problems = append(problems, unusedParams(text, endpoint, used)...)
+    }
+    sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+    return problems
+}
+
+// unusedParams reports @param declarations and path parameters that the SQL never
+// references; used holds the {{variables}} already found
+func unusedParams(text string, endpoint server.Endpoint, used map[string]bool) []Problem {
+    var problems []Problem
+    for _, name := range database.FindPlaceholders(text).Named {
+        used[name] = true
+    }
+    for _, param := range declaredParams(text) {
+        if !used[param.name] {
+            problems = append(problems, Problem{File: endpoint.SQLPath, Line: param.line, Message: fmt.Sprintf("@param %s is declared but not used in the SQL", param.name)})
+        }
+    }
+    for _, name := range server.PathParamNames(endpoint.Path) {
+        if !used[name] {
+            problems = append(problems, Problem{File: endpoint.SQLPath, Message: fmt.Sprintf("path parameter {%s} is not used in the SQL", name)})
+        }
+    }
+    return problems
+}
+
+// declaredParam is an @param declaration and the line it is on
+type declaredParam struct {
+    name string
+    line int
+}
+
+// declaredParams returns the @param declarations in a SQL file's metadata header
+func declaredParams(content string) []declaredParam {
+    var params []declaredParam
+    for i, line := range strings.Split(content, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" {
+            continue
+        }
+        if !strings.HasPrefix(trimmed, "--") {
+            break
+        }
+        fields := strings.Fields(strings.TrimPrefix(trimmed, "--"))
+        if len(fields) > 1 && strings.EqualFold(fields[0], "@param") {
+            params = append(params, declaredParam{name: strings.TrimLeft(fields[1], ":@$"), line: i + 1})
+        }
+    }
+    return params
+}
+
+var errorToken = regexp.MustCompile(`near "(.+?)": syntax error|no such (?:table|column|function): (\S+)`)
+
+// errorLine returns how many lines into a statement the token named by a SQLite error
+// is, or 0 when the error names none
+func errorLine(statement, message string) int {
+    match := errorToken.FindStringSubmatch(message)
+    if match == nil {
+        return 0
+    }
+    token := match[1] + match[2]
+    index := strings.Index(statement, token)
+    if index == -1 {
+        // A qualified column such as u.email may be written with spaces or quotes
+        index = strings.Index(statement, token[strings.LastIndex(token, ".")+1:])
+    }
+    if index == -1 {
+        return 0
+    }
+    return strings.Count(statement[:index], "\n")
+}
+
+// lineOf returns the 1-based line of a byte offset in content
+func lineOf(content string, offset int) int {
+    return strings.Count(content[:offset], "\n") + 1
+}
@@ -0,0 +1,315 @@
+// cli.go
+package cli
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "gosql/logging"
+    "gosql/server"
+    "gosql/setup"
+    "io"
+    "log/slog"
+    "os"
+    "sort"
+    "strings"
+)
+
+// Exit codes shared by every command
+const (
+    ExitOK      = 0 // The command succeeded
+    ExitFailure = 1 // The command ran and failed, e.g. check found problems or a query errored
+    ExitUsage   = 2 // Bad arguments or an invalid configuration
+)
+
+// Command is a gosql subcommand
+type Command struct {
+    Name    string                  // Word that selects the command
+    Args    string                  // Positional arguments shown in usage, e.g. "<up|down|status>"
+    Summary string                  // One-line description for help output
+    Run     func(args []string) int // Parses the arguments after the name and returns an exit code
+}
+
+// commands lists the subcommands in help order; filled in by init because help refers to it
+var commands []Command
+
+func init() {
+    commands = []Command{
+        {Name: "serve", Args: "", Summary: "Serve the SQL files as an HTTP API (the default command)", Run: runServe},
+        {Name: "init", Args: "", Summary: "Create the SQL directory layout and a folder per schema table", Run: runInit},
+        {Name: "new", Args: "<endpoint <table> <METHOD> <name> | table <name>>", Summary: "Generate endpoint SQL files for a table in the live schema", Run: runNew},
+        {Name: "migrate", Args: "<up|down|status>", Summary: "Apply, revert or list the numbered migrations", Run: runMigrate},
+        {Name: "check", Args: "", Summary: "Check the schema, migrations and SQL files against a scratch database", Run: runCheck},
+        {Name: "routes", Args: "", Summary: "List the routes the SQL files map to", Run: runRoutes},
+        {Name: "query", Args: "<endpoint> [key=value ...]", Summary: "Run one endpoint's SQL file and print the result", Run: runQuery},
+        {Name: "export", Args: "", Summary: "Write the OpenAPI document describing the API", Run: runExport},
+        {Name: "gen", Args: "<python|ts|go>", Summary: "Generate a typed client for the API", Run: runGen},
+        {Name: "help", Args: "[command]", Summary: "Show help for gosql or one command", Run: runHelp},
+    }
+}
+
+// Run runs the command named by the first argument and returns the process exit code
+// Arguments that start with a flag run serve, so "gosql -port 8080" keeps working
+func Run(args []string) int {
+    if len(args) == 0 {
+        return runServe(args)
+    }
+    switch args[0] {
+    case "-h", "-help", "--help":
+        return runHelp(nil)
+    }
+    if strings.HasPrefix(args[0], "-") {
+        return runServe(args)
+    }
+
+    command, ok := lookupCommand(args[0])
+    if !ok {
+        fmt.Fprintf(os.Stderr, "❌ Unknown command %q; run gosql help for the list of commands\n", args[0])
+        return ExitUsage
+    }
+    return command.Run(args[1:])
+}
+
+// lookupCommand finds a command by name
+func lookupCommand(name string) (Command, bool) {
+    for _, command := range commands {
+        if command.Name == name {
+            return command, true
+        }
+    }
+    return Command{}, false
+}
+
+// newFlagSet creates the flag set of a command; errors and help are reported by parseArgs
+func newFlagSet(name string) *flag.FlagSet {
+    fs := flag.NewFlagSet("gosql "+name, flag.ContinueOnError)
+    fs.SetOutput(os.Stderr)
+    fs.Usage = func() {}
+    return fs
+}
+
+// parseArgs parses flags given before, between and after the positional arguments and
+// returns the positional arguments
+// When ok is false the command should return code: help was shown or the flags were invalid
+func parseArgs(fs *flag.FlagSet, args []string) (positional []string, code int, ok bool) {
+    for {
+        err := fs.Parse(args)
+        if errors.Is(err, flag.ErrHelp) {
+            printCommandHelp(os.Stdout, strings.TrimPrefix(fs.Name(), "gosql "), fs)
+            return nil, ExitOK, false
+        }
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Run '%s -help' for usage\n", fs.Name())
+            return nil, ExitUsage, false
+        }
+        args = fs.Args()
+        if len(args) == 0 {
+            return positional, ExitOK, true
+        }
+        // A lone -- ends flag parsing; everything after it is positional
+        if args[0] == "--" {
+            return append(positional, args[1:]...), ExitOK, true
+        }
+        positional = append(positional, args[0])
+        args = args[1:]
+    }
+}
+
+// usageError reports a misused command and returns ExitUsage
+func usageError(name string, format string, args ...interface{}) int {
+    fmt.Fprintf(os.Stderr, "❌ %s\nRun 'gosql %s -help' for usage\n", fmt.Sprintf(format, args...), name)
+    return ExitUsage
+}
+
+// fail reports an error and returns code
+func fail(code int, err error) int {
+    fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+    return code
+}
+
+// warnIfServed warns when a running server holds the lock on the configured database,
+// since the command then works on the database alongside it
+func warnIfServed(cfg setup.Config) {
+    if holder := server.LockHolder(cfg); holder != nil {
+        slog.Warn("the database is being served; changes made here are not coordinated with the server",
+            "database", cfg.DatabasePath, "pid", holder.PID)
+    }
+}
+
+// configFlags are the configuration flags every command accepts
+type configFlags struct {
+    fs          *flag.FlagSet // Flag set the settings are registered on
+    file        *string       // -config
+    portShort   *string       // -p, shorthand for -port
+    printConfig *bool         // -print-config
+}
+
+// addConfigFlags registers a flag for every setup.Settings entry plus -config, -p and
+// -print-config
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+    defaults := setup.DefaultConfig()
+    for _, setting := range setup.Settings {
+        if setting.Bool {
+            fs.Bool(setting.Name, setting.Get(&defaults) == "true", setting.Usage)
+        } else {
+            fs.String(setting.Name, setting.Get(&defaults), setting.Usage)
+        }
+    }
+    return &configFlags{
+        fs:          fs,
+        file:        fs.String("config", "", "Config file (default: $GOSQL_CONFIG, else gosql.yaml, gosql.yml, gosql.toml or gosql.json)"),
+        portShort:   fs.String("p", "", "HTTP server port (shorthand for -port)"),
+        printConfig: fs.Bool("print-config", false, "Print the effective configuration and the source of each value, then exit"),
+    }
+}
+
+// load merges the defaults, config file, GOSQL_* environment variables and the flags
+// actually given into a Config
+// When ok is false the command should return code: the configuration was printed or
+// is invalid
+func (f *configFlags) load() (cfg setup.Config, sources setup.Sources, code int, ok bool) {
+    // Only flags actually given override the config file and environment, so a flag
+    // set to its default value still takes effect
+    given := make(map[string]string)
+    f.fs.Visit(func(fl *flag.Flag) {
+        if _, ok := setup.LookupSetting(fl.Name); ok {
+            given[fl.Name] = fl.Value.String()
+        }
+    })
+    if *f.portShort != "" {
+        if port, ok := given["port"]; ok && port != *f.portShort {
+            return cfg, nil, fail(ExitUsage, fmt.Errorf("-p %s and -port %s disagree; give one of them", *f.portShort, port)), false
+        }
+        given["port"] = *f.portShort
+    }
+
+    cfg, sources, err := setup.Load(setup.LoadOptions{File: *f.file, Env: os.Environ(), Flags: given})
+    if *f.printConfig {
+        setup.PrintConfig(os.Stdout, cfg, sources)
+    }
+    if err != nil {
+        return cfg, sources, fail(ExitUsage, fmt.Errorf("Invalid configuration:\n  %s", strings.ReplaceAll(err.Error(), "\n", "\n  "))), false
+    }
+    if *f.printConfig {
+        return cfg, sources, ExitOK, false
+    }
+    return cfg, sources, ExitOK, true
+}
+
+// setupLogging installs the configured logger on stderr
+// Commands other than serve pass quiet so only warnings are logged unless a log level
+// was configured, keeping their output easy to script
+func setupLogging(cfg setup.Config, sources setup.Sources, quiet bool) error {
+    level := cfg.LogLevel
+    if quiet && sources["log-level"] == "default" {
+        level = "warn"
+    }
+    logger, err := logging.New(logging.Options{
+        Level:  level,
+        Format: cfg.LogFormat,
+        Redact: cfg.LogRedact,
+        Output: os.Stderr,
+    })
+    if err != nil {
+        return err
+    }
+    slog.SetDefault(logger)
+    return nil
+}
+
+// loadCommandConfig parses a command's arguments, then loads the configuration and sets
+// up quiet logging
+// When ok is false the command should return code
+func loadCommandConfig(fs *flag.FlagSet, config *configFlags, args []string) (cfg setup.Config, positional []string, code int, ok bool) {
+    positional, code, ok = parseArgs(fs, args)
+    if !ok {
+        return cfg, nil, code, false
+    }
+    cfg, sources, code, ok := config.load()
+    if !ok {
+        return cfg, nil, code, false
+    }
+    if err := setupLogging(cfg, sources, true); err != nil {
+        return cfg, nil, fail(ExitUsage, err), false
+    }
+    return cfg, positional, ExitOK, true
+}
+
+// printCommandHelp writes the usage of one command
+// Configuration flags are only listed in full for serve; other commands mention them
+func printCommandHelp(w io.Writer, name string, fs *flag.FlagSet) {
+    command, _ := lookupCommand(name)
+    fmt.Fprintf(w, "Usage: gosql %s [flags]", command.Name)
+    if command.Args != "" {
+        fmt.Fprintf(w, " %s", command.Args)
+    }
+    fmt.Fprintf(w, "\n\n%s\n", command.Summary)
+    if help, ok := commandHelp[command.Name]; ok {
+        fmt.Fprintf(w, "\n%s\n", help)
+    }
+
+    var own, settings []*flag.Flag
+    fs.VisitAll(func(f *flag.Flag) {
+        if _, ok := setup.LookupSetting(f.Name); ok {
+            settings = append(settings, f)
+        } else {
+            own = append(own, f)
+        }
+    })
+    if len(own) > 0 {
+        fmt.Fprintln(w, "\nFlags:")
+        printFlags(w, own)
+    }
+    if len(settings) > 0 {
+        if name == "serve" {
+            fmt.Fprintln(w, "\nSettings (also GOSQL_<SETTING> variables and config file keys):")
+            printFlags(w, settings)
+        } else {
+            fmt.Fprintln(w, "\nEvery configuration setting is also accepted as a flag; see gosql serve -help.")
+        }
+    }
+    fmt.Fprintf(w, "\nExit status: %d on success, %d on failure, %d for invalid arguments or configuration.\n", ExitOK, ExitFailure, ExitUsage)
+}
+
+// printFlags writes one line per flag with its default
+func printFlags(w io.Writer, flags []*flag.Flag) {
+    sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+    for _, f := range flags {
+        name := "-" + f.Name
+        if _, isBool := f.Value.(interface{ IsBoolFlag() bool }); !isBool {
+            name += " <value>"
+        }
+        line := fmt.Sprintf("  %-28s %s", name, f.Usage)
+        if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+            line += fmt.Sprintf(" (default: %s)", f.DefValue)
+        }
+        fmt.Fprintln(w, line)
+    }
+}
+
+// commandHelp holds extra help text for commands whose arguments need explaining
+var commandHelp = map[string]string{
+    "migrate": `Migrations are files under <sql>/migrations named <version>_<name>.up.sql, with an
+optional <version>_<name>.down.sql; a plain <version>_<name>.sql cannot be reverted.
+
+  up      Apply pending migrations in version order (-to stops at a version)
+  down    Revert the most recently applied migrations (-steps, default 1)
+  status  List every migration as applied, pending or missing (applied but no file)`,
+    "query": `The endpoint is a SQL file path (Tables/users/GET/by_email.sql, relative to the SQL
+root or not) or a route (/api/v1/users/by_email or users/by_email). Parameters are given
+as key=value and sent the way a client would: path parameters in the URL, the rest in the
+query string for GET and DELETE or as a JSON body for POST and PUT, where values that
+parse as JSON (numbers, true, null) are sent as JSON. The request then goes through the
+same parameter extraction, template expansion and execution as over HTTP, against the
+configured database. Authentication is not applied; auth_ parameters bind as NULL.
+
+-dry-run prints the expanded SQL and the bound arguments without running it.`,
+    "check": `The schema and migrations are applied to a scratch in-memory database, and every SQL
+file is compiled against it with template variables filled in by placeholder values.
+check reports syntax errors, unknown tables and columns, GET files that write, route
+conflicts, and @param declarations or path parameters the SQL never uses. The configured
+database is not opened.
+
+Problems are printed one per line as "<file>:<line>: <message>", or as a JSON array with
+-json. The exit status is 1 when any problem is found, so it can gate CI.`,
+}
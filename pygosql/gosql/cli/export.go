@@ -0,0 +1,51 @@
+// export.go
+package cli
+
+import (
+    "encoding/json"
+    "fmt"
+    "gosql/server"
+    "os"
+    "path/filepath"
+)
+
+// runExport writes the OpenAPI document served at /openapi.json, without serving
+func runExport(args []string) int {
+    flags := newFlagSet("export")
+    config := addConfigFlags(flags)
+    out := flags.String("o", "", "Output file (default: stdout)")
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+    if len(positional) > 0 {
+        return usageError("export", "unexpected argument %q", positional[0])
+    }
+
+    db, endpoints, err := openEndpoints(cfg)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    defer db.Close()
+
+    document, err := json.MarshalIndent(server.BuildOpenAPI(cfg, endpoints), "", "  ")
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    document = append(document, '\n')
+
+    if *out == "" {
+        if _, err := os.Stdout.Write(document); err != nil {
+            return fail(ExitFailure, err)
+        }
+        return ExitOK
+    }
+    if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+        return fail(ExitFailure, fmt.Errorf("failed to create output directory: %w", err))
+    }
+    if err := os.WriteFile(*out, document, 0644); err != nil {
+        return fail(ExitFailure, err)
+    }
+    fmt.Fprintf(os.Stderr, "wrote %s (%d endpoints)\n", *out, len(endpoints))
+    return ExitOK
+}
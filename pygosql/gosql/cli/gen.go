@@ -0,0 +1,87 @@
+// gen.go
+package cli
+
+import (
+    "fmt"
+    "gosql/codegen"
+    "gosql/server"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// runGen generates a typed client for the endpoints, without serving
+func runGen(args []string) int {
+    flags := newFlagSet("gen")
+    config := addConfigFlags(flags)
+    out := flags.String("o", "", "Output file (default: stdout); a .pyi path produces a Python stub")
+    goPackage := flags.String("package", "gosqlclient", "Package name for gen go")
+    check := flags.Bool("check", false, "Fail if the -o file differs from the generated output")
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+    if len(positional) != 1 {
+        return usageError("gen", "expected one language: python, ts or go")
+    }
+
+    db, endpoints, err := openEndpoints(cfg)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    defer db.Close()
+
+    spec, err := server.NewCodegenSpec(cfg, endpoints)
+    if err != nil {
+        return fail(ExitFailure, fmt.Errorf("Code generation failed: %w", err))
+    }
+    if err := WriteGenerated(positional[0], *out, spec, *goPackage, *check); err != nil {
+        return fail(ExitFailure, fmt.Errorf("Code generation failed: %w", err))
+    }
+    return ExitOK
+}
+
+// WriteGenerated generates client code for the given language and writes it to path (stdout if empty)
+// A path ending in .pyi produces a Python stub instead of a runnable module. With check set the
+// file is left untouched and an error is returned when it differs from the generated output
+func WriteGenerated(language string, path string, spec codegen.Spec, goPackage string, check bool) error {
+    var output string
+    switch language {
+    case "python", "py":
+        output = codegen.Python(spec, codegen.PythonOptions{Stub: strings.HasSuffix(path, ".pyi")})
+    case "ts", "typescript":
+        output = codegen.TypeScript(spec)
+    case "go":
+        generated, err := codegen.Go(spec, codegen.GoOptions{Package: goPackage})
+        if err != nil {
+            return err
+        }
+        output = generated
+    default:
+        return fmt.Errorf("unsupported language %q (expected python, ts or go)", language)
+    }
+
+    if check {
+        if path == "" {
+            return fmt.Errorf("-check requires -o <path>")
+        }
+        existing, err := os.ReadFile(path)
+        if err != nil {
+            return fmt.Errorf("failed to read %s: %w", path, err)
+        }
+        if string(existing) != output {
+            return fmt.Errorf("%s is out of date, run gosql gen %s -o %s", path, language, path)
+        }
+        return nil
+    }
+
+    if path == "" {
+        _, err := os.Stdout.WriteString(output)
+        return err
+    }
+
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return fmt.Errorf("failed to create output directory: %w", err)
+    }
+    return os.WriteFile(path, []byte(output), 0644)
+}
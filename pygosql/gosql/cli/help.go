@@ -0,0 +1,81 @@
+// help.go
+package cli
+
+import "fmt"
+
+// runHelp shows the overview, or one command's help when named
+func runHelp(args []string) int {
+    if len(args) > 1 {
+        return usageError("help", "expected at most one command")
+    }
+    if len(args) == 1 {
+        command, ok := lookupCommand(args[0])
+        if !ok || command.Name == "help" {
+            return usageError("help", "unknown command %q", args[0])
+        }
+        return command.Run([]string{"-help"})
+    }
+    ShowHelp()
+    return ExitOK
+}
+
+// ShowHelp displays the commands, configuration sources and project layout
+func ShowHelp() {
+    fmt.Println("GoSQL - HTTP API Server for SQL Files")
+    fmt.Println()
+    fmt.Println("USAGE:")
+    fmt.Println("  gosql <command> [flags] [arguments]")
+    fmt.Println("  gosql [flags]                  # Same as gosql serve [flags]")
+    fmt.Println()
+    fmt.Println("COMMANDS:")
+    for _, command := range commands {
+        fmt.Printf("  %-8s %s\n", command.Name, command.Summary)
+    }
+    fmt.Println()
+    fmt.Println("  Run gosql <command> -help for a command's arguments and flags.")
+    fmt.Println()
+    fmt.Println("CONFIGURATION:")
+    fmt.Println("  Every command accepts the same settings. They come from, in increasing precedence:")
+    fmt.Println("  defaults, the config file, GOSQL_<SETTING> environment variables (e.g. GOSQL_TLS_CERT)")
+    fmt.Println("  and flags. gosql serve -help lists every setting.")
+    fmt.Println("  -config <file>         YAML, TOML or JSON config file (default: $GOSQL_CONFIG, else")
+    fmt.Println("                         gosql.yaml, gosql.yml, gosql.toml or gosql.json if present)")
+    fmt.Println("  -print-config          Print each setting's effective value and source, then exit")
+    fmt.Println()
+    fmt.Println("OUTPUT AND EXIT STATUS:")
+    fmt.Println("  Results are written to stdout and logs and errors to stderr. Commands that list")
+    fmt.Println("  things accept -json. The exit status is 0 on success, 1 when the command fails")
+    fmt.Println("  and 2 for invalid arguments or configuration.")
+    fmt.Println()
+    fmt.Println("EXAMPLES:")
+    fmt.Println("  gosql init                          # Create the SQL directory layout")
+    fmt.Println("  gosql serve -port 3000              # Serve on port 3000")
+    fmt.Println("  gosql migrate up                    # Apply pending migrations")
+    fmt.Println("  gosql check -json                   # Report problems in the SQL files")
+    fmt.Println("  gosql routes                        # List the routes")
+    fmt.Println("  gosql query users/select            # Run an endpoint and print its rows")
+    fmt.Println("  gosql export -o openapi.json        # Write the OpenAPI document")
+    fmt.Println("  gosql gen ts -o client.ts -check    # Fail if the TypeScript client is stale")
+    fmt.Println()
+    fmt.Println("DIRECTORY STRUCTURE:")
+    fmt.Println("  gosql_dir/")
+    fmt.Println("  ├── app.db                     # SQLite database")
+    fmt.Println("  └── db/")
+    fmt.Println("      ├── schema.sql             # Database schema")
+    fmt.Println("      ├── migrations/            # Numbered schema changes for gosql migrate")
+    fmt.Println("      ├── GET/                   # Universal GET endpoints")
+    fmt.Println("      ├── POST/                  # Universal POST endpoints")
+    fmt.Println("      ├── PUT/                   # Universal PUT endpoints")
+    fmt.Println("      ├── DELETE/                # Universal DELETE endpoints")
+    fmt.Println("      └── Tables/")
+    fmt.Println("          └── users/             # Table-specific endpoints")
+    fmt.Println("              ├── GET/")
+    fmt.Println("              ├── POST/")
+    fmt.Println("              ├── PUT/")
+    fmt.Println("              └── DELETE/")
+    fmt.Println()
+    fmt.Println("API ENDPOINTS:")
+    fmt.Println("  GET  /                         # API documentation")
+    fmt.Println("  GET  /health                   # Health check")
+    fmt.Println("  *    /api/v1/{table}/{action}  # Generated from SQL files")
+}
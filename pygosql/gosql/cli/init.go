@@ -0,0 +1,111 @@
+// init.go
+package cli
+
+import (
+    "fmt"
+    "gosql/app"
+    "gosql/setup"
+    "io/fs"
+    "os"
+    "path/filepath"
+)
+
+// runInit scaffolds the SQL directory layout, the database directory and a folder of
+// default SQL files for each table in the schema, printing every path it created
+func runInit(args []string) int {
+    flags := newFlagSet("init")
+    config := addConfigFlags(flags)
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+    if len(positional) > 0 {
+        return usageError("init", "unexpected argument %q", positional[0])
+    }
+
+    roots := []string{cfg.SQLRoot, filepath.Dir(cfg.DatabasePath)}
+    before := existingPaths(roots)
+    if err := RunSetup(cfg); err != nil {
+        return fail(ExitFailure, err)
+    }
+    if err := app.Scaffold(cfg); err != nil {
+        return fail(ExitFailure, err)
+    }
+
+    created := 0
+    for _, path := range walkPaths(roots) {
+        if before[path] {
+            continue
+        }
+        created++
+        if info, err := os.Stat(path); err == nil && info.IsDir() {
+            path += string(filepath.Separator)
+        }
+        fmt.Println("created", path)
+    }
+    if created == 0 {
+        fmt.Fprintf(os.Stderr, "%s is already initialized\n", cfg.SQLRoot)
+    }
+    return ExitOK
+}
+
+// existingPaths returns the set of paths below roots that exist
+func existingPaths(roots []string) map[string]bool {
+    paths := make(map[string]bool)
+    for _, path := range walkPaths(roots) {
+        paths[path] = true
+    }
+    return paths
+}
+
+// walkPaths lists roots and everything below them in lexical order, once each
+func walkPaths(roots []string) []string {
+    var paths []string
+    seen := make(map[string]bool)
+    for _, root := range roots {
+        filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+            if err != nil {
+                return nil
+            }
+            if !seen[path] {
+                seen[path] = true
+                paths = append(paths, path)
+            }
+            return nil
+        })
+    }
+    return paths
+}
+
+// IsSetupComplete checks if all required directories and files exist for the application to run
+func IsSetupComplete(cfg setup.Config) bool {
+    requiredPaths := []string{
+        cfg.SQLRoot,
+        filepath.Dir(cfg.DatabasePath),
+    }
+
+    for _, path := range requiredPaths {
+        if _, err := os.Stat(path); os.IsNotExist(err) {
+            return false
+        }
+    }
+
+    return true
+}
+
+// RunSetup performs initial setup of directories and files
+func RunSetup(cfg setup.Config) error {
+    dir := setup.NewDir(cfg.SQLRoot)
+
+    // Create directory structure
+    if err := dir.MakeDirs(); err != nil {
+        return fmt.Errorf("failed to create directories: %w", err)
+    }
+
+    // Create database directory
+    if err := os.MkdirAll(filepath.Dir(cfg.DatabasePath), 0755); err != nil {
+        return fmt.Errorf("failed to create database directory: %w", err)
+    }
+
+    return nil
+}
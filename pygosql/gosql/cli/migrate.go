@@ -0,0 +1,175 @@
+// migrate.go
+package cli
+
+import (
+    "encoding/json"
+    "fmt"
+    "gosql/app"
+    "gosql/database"
+    "gosql/server"
+    "os"
+    "sort"
+    "strconv"
+    "text/tabwriter"
+)
+
+// MigrationStatus is one line of migrate status
+type MigrationStatus struct {
+    Version    int64  `json:"version"`    // Version from the file name
+    Name       string `json:"name"`       // Name from the file name, empty when the file is missing
+    Status     string `json:"status"`     // applied, pending or missing (applied but no file)
+    Reversible bool   `json:"reversible"` // Whether a down file exists
+}
+
+// runMigrate applies, reverts or lists the migrations under <sql>/migrations
+func runMigrate(args []string) int {
+    flags := newFlagSet("migrate")
+    config := addConfigFlags(flags)
+    to := flags.Int64("to", 0, "For up: apply migrations up to and including this version; for down: revert down to, not including, it")
+    steps := flags.Int("steps", 1, "For down: number of migrations to revert, when -to is not given")
+    asJSON := flags.Bool("json", false, "For status: print a JSON array")
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+    if len(positional) != 1 {
+        return usageError("migrate", "expected one of up, down or status")
+    }
+    action := positional[0]
+    if action != "up" && action != "down" && action != "status" {
+        return usageError("migrate", "unknown migrate action %q, expected up, down or status", action)
+    }
+    if *steps < 1 {
+        return usageError("migrate", "-steps must be at least 1")
+    }
+
+    migrations, err := database.LoadMigrations(cfg.MigrationsPath())
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    // Changing the schema under a running server is refused; listing it is not
+    if action == "status" {
+        warnIfServed(cfg)
+    } else {
+        lock, err := server.AcquireLock(cfg)
+        if err != nil {
+            return fail(ExitFailure, fmt.Errorf("%w; stop the server before migrating", err))
+        }
+        defer lock.Release()
+    }
+    db, err := app.OpenDatabase(cfg)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    defer db.Close()
+    applied, err := db.AppliedMigrations()
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+
+    switch action {
+    case "up":
+        count := 0
+        for _, migration := range migrations {
+            if applied[migration.Version] || (*to > 0 && migration.Version > *to) {
+                continue
+            }
+            if err := db.ApplyMigration(migration); err != nil {
+                return fail(ExitFailure, err)
+            }
+            fmt.Println("applied", migration)
+            count++
+        }
+        if count == 0 {
+            fmt.Fprintln(os.Stderr, "no pending migrations")
+        }
+
+    case "down":
+        byVersion := make(map[int64]database.Migration, len(migrations))
+        for _, migration := range migrations {
+            byVersion[migration.Version] = migration
+        }
+        versions := make([]int64, 0, len(applied))
+        for version := range applied {
+            versions = append(versions, version)
+        }
+        sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+        count := 0
+        for _, version := range versions {
+            if *to > 0 && version <= *to || *to == 0 && count == *steps {
+                break
+            }
+            migration, ok := byVersion[version]
+            if !ok {
+                return fail(ExitFailure, fmt.Errorf("migration %d is applied but its files are missing from %s", version, cfg.MigrationsPath()))
+            }
+            if err := db.RevertMigration(migration); err != nil {
+                return fail(ExitFailure, err)
+            }
+            fmt.Println("reverted", migration)
+            count++
+        }
+        if count == 0 {
+            fmt.Fprintln(os.Stderr, "no migrations to revert")
+        }
+
+    case "status":
+        statuses := migrationStatuses(migrations, applied)
+        if *asJSON {
+            return printJSON(statuses)
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tREVERSIBLE")
+        for _, status := range statuses {
+            fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, orDash(status.Name), status.Status, strconv.FormatBool(status.Reversible))
+        }
+        w.Flush()
+    }
+    return ExitOK
+}
+
+// migrationStatuses lists the migration files and any applied versions without files,
+// in version order
+func migrationStatuses(migrations []database.Migration, applied map[int64]bool) []MigrationStatus {
+    statuses := make([]MigrationStatus, 0, len(migrations))
+    known := make(map[int64]bool, len(migrations))
+    for _, migration := range migrations {
+        known[migration.Version] = true
+        status := "pending"
+        if applied[migration.Version] {
+            status = "applied"
+        }
+        statuses = append(statuses, MigrationStatus{
+            Version:    migration.Version,
+            Name:       migration.Name,
+            Status:     status,
+            Reversible: migration.DownPath != "",
+        })
+    }
+    for version := range applied {
+        if !known[version] {
+            statuses = append(statuses, MigrationStatus{Version: version, Status: "missing"})
+        }
+    }
+    sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+    return statuses
+}
+
+// printJSON writes value to stdout as indented JSON
+func printJSON(value interface{}) int {
+    encoder := json.NewEncoder(os.Stdout)
+    encoder.SetIndent("", "  ")
+    if err := encoder.Encode(value); err != nil {
+        return fail(ExitFailure, err)
+    }
+    return ExitOK
+}
+
+// orDash returns value, or "-" when it is empty, for table output
+func orDash(value string) string {
+    if value == "" {
+        return "-"
+    }
+    return value
+}
@@ -0,0 +1,154 @@
+// new.go
+package cli
+
+import (
+    "errors"
+    "fmt"
+    "gosql/app"
+    "gosql/server"
+    "gosql/setup"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// generatedFile is an endpoint SQL file about to be written
+type generatedFile struct {
+    path    string // Where the file goes
+    method  string // HTTP method directory
+    content string // SQL with its metadata header
+}
+
+// runNew generates endpoint SQL files for a table in the live schema
+func runNew(args []string) int {
+    flags := newFlagSet("new")
+    config := addConfigFlags(flags)
+    by := flags.String("by", "", "Comma separated key columns for the WHERE clause (default: from a by_<column> name, else the primary key)")
+    description := flags.String("description", "", "@description of the endpoint (default: generated from the table and keys)")
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+
+    var kind string
+    if len(positional) > 0 {
+        kind = positional[0]
+    }
+    switch {
+    case kind == "endpoint" && len(positional) == 4:
+    case kind == "table" && len(positional) == 2:
+    case kind == "endpoint" || kind == "table":
+        return usageError("new", "expected gosql new endpoint <table> <METHOD> <name> or gosql new table <name>")
+    default:
+        return usageError("new", "expected endpoint or table")
+    }
+
+    warnIfServed(cfg)
+    db, err := app.OpenDatabase(cfg)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    defer db.Close()
+
+    // The table must exist in the live schema, which includes applied migrations
+    tables, err := db.Tables()
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    table := ""
+    for _, name := range tables {
+        if strings.EqualFold(name, positional[1]) {
+            table = name
+        }
+    }
+    if table == "" {
+        return fail(ExitFailure, fmt.Errorf("table %q is not in %s; add it to %s or a migration first", positional[1], cfg.DatabasePath, cfg.SchemaPath))
+    }
+    columns, err := db.TableColumns(table)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+
+    specs := make([]setup.EndpointSpec, 0, len(setup.TableEndpoints))
+    if kind == "endpoint" {
+        specs = append(specs, setup.EndpointSpec{Method: strings.ToUpper(positional[2]), Name: positional[3], By: setup.SplitList(*by), Description: *description})
+    } else {
+        for _, endpoint := range setup.TableEndpoints {
+            specs = append(specs, setup.EndpointSpec{Method: endpoint.Method, Name: endpoint.Name, By: setup.SplitList(*by), Description: *description})
+        }
+    }
+
+    // Generate everything before writing anything, so a bad spec writes no files
+    dir := setup.NewDir(cfg.SQLRoot)
+    var files []generatedFile
+    var existing []string
+    for _, spec := range specs {
+        spec.Table, spec.Columns = table, columns
+        content, err := setup.GenerateEndpointSQL(spec)
+        if kind == "table" && errors.Is(err, setup.ErrNothingToUpdate) {
+            fmt.Fprintf(os.Stderr, "skipped %s %s: %v\n", spec.Method, spec.Name, err)
+            continue
+        }
+        if err != nil {
+            return fail(ExitFailure, err)
+        }
+        path := dir.EndpointFile(table, spec.Method, spec.Name)
+        if _, err := os.Stat(path); err == nil {
+            existing = append(existing, path)
+        }
+        files = append(files, generatedFile{path: path, method: spec.Method, content: content})
+    }
+    if len(existing) > 0 {
+        return fail(ExitFailure, fmt.Errorf("refusing to overwrite %s", strings.Join(existing, ", ")))
+    }
+    if err := checkNewRoutes(cfg, files); err != nil {
+        return fail(ExitFailure, err)
+    }
+
+    for _, file := range files {
+        if err := writeNewFile(file.path, file.content); err != nil {
+            return fail(ExitFailure, err)
+        }
+        fmt.Printf("created %s -> %s %s\n", file.path, file.method, server.RouteFromPath(file.path, cfg.BaseURL))
+    }
+    return ExitOK
+}
+
+// checkNewRoutes refuses files whose routes would conflict with the existing SQL files
+func checkNewRoutes(cfg setup.Config, files []generatedFile) error {
+    sqlFiles, err := server.GlobSQLFiles(cfg.SQLRoot)
+    if err != nil && !errors.Is(err, os.ErrNotExist) {
+        return err
+    }
+    for _, file := range files {
+        sqlFiles = append(sqlFiles, file.path)
+    }
+    endpoints := make([]server.Endpoint, len(sqlFiles))
+    for i, sqlFile := range sqlFiles {
+        endpoints[i] = server.Endpoint{
+            Path:    server.RouteFromPath(sqlFile, cfg.BaseURL),
+            Method:  server.MethodFromPath(sqlFile),
+            SQLPath: sqlFile,
+        }
+    }
+    if err := server.ValidateEndpoints(cfg, endpoints); err != nil {
+        return fmt.Errorf("refusing to create conflicting routes: %w", err)
+    }
+    return nil
+}
+
+// writeNewFile creates path and its directories, failing if the file already exists
+func writeNewFile(path, content string) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return fmt.Errorf("failed to create directory: %w", err)
+    }
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+    if err != nil {
+        return err
+    }
+    if _, err := f.WriteString(content); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
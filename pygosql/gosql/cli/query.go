@@ -0,0 +1,338 @@
+// query.go
+package cli
+
+import (
+    "bytes"
+    "database/sql"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "gosql/auth"
+    "gosql/database"
+    "gosql/server"
+    "gosql/setup"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "text/tabwriter"
+)
+
+// Query output formats
+const (
+    FormatTable = "table" // Aligned columns with a header row
+    FormatJSON  = "json"  // The endpoint's response data, as served over HTTP
+    FormatCSV   = "csv"   // RFC 4180 CSV with a header row
+)
+
+// runQuery runs one endpoint offline: the request goes through the same parameter
+// extraction, template expansion and execution as over HTTP, against the configured
+// database, and the result is printed
+func runQuery(args []string) int {
+    flags := newFlagSet("query")
+    config := addConfigFlags(flags)
+    method := flags.String("method", "", "HTTP method of the endpoint, when a route has more than one")
+    format := flags.String("format", FormatTable, "Output format: table, json or csv")
+    dryRun := flags.Bool("dry-run", false, "Print the expanded SQL and bound arguments instead of running it")
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+    if len(positional) == 0 {
+        return usageError("query", "expected an endpoint")
+    }
+    if *format != FormatTable && *format != FormatJSON && *format != FormatCSV {
+        return usageError("query", "-format %q: use table, json or csv", *format)
+    }
+    params, err := parseParams(positional[1:])
+    if err != nil {
+        return usageError("query", "%v", err)
+    }
+
+    warnIfServed(cfg)
+    db, endpoints, err := openEndpoints(cfg)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    defer db.Close()
+
+    endpoint, err := findEndpoint(cfg, endpoints, positional[0], strings.ToUpper(*method))
+    if err != nil {
+        return usageError("query", "%v", err)
+    }
+    request, err := endpointRequest(endpoint, params)
+    if err != nil {
+        return usageError("query", "%v", err)
+    }
+
+    if *dryRun {
+        prepared, err := prepareEndpoint(db, endpoint, request)
+        if err != nil {
+            return fail(ExitFailure, err)
+        }
+        return printPrepared(endpoint, prepared, *format)
+    }
+
+    status, body := serveEndpoint(endpoint, endpoint.Handler, request)
+    var response struct {
+        Success bool            `json:"success"`
+        Data    json.RawMessage `json:"data"`
+        Error   string          `json:"error"`
+    }
+    if err := json.Unmarshal(body, &response); err != nil {
+        return fail(ExitFailure, fmt.Errorf("%s %s: unexpected response with status %d: %s", endpoint.Method, endpoint.Path, status, bytes.TrimSpace(body)))
+    }
+    if status >= 400 || !response.Success {
+        return fail(ExitFailure, fmt.Errorf("%s %s: %s", endpoint.Method, endpoint.Path, response.Error))
+    }
+    if err := printResult(os.Stdout, response.Data, *format); err != nil {
+        return fail(ExitFailure, err)
+    }
+    return ExitOK
+}
+
+// parseParams parses key=value arguments
+func parseParams(args []string) (map[string]string, error) {
+    params := make(map[string]string, len(args))
+    for _, arg := range args {
+        key, value, ok := strings.Cut(arg, "=")
+        if !ok || key == "" {
+            return nil, fmt.Errorf("parameter %q must look like key=value", arg)
+        }
+        params[key] = value
+    }
+    return params, nil
+}
+
+// findEndpoint resolves a SQL file path or route to an endpoint
+// Paths may be given relative to the SQL root, and routes without the base URL
+func findEndpoint(cfg setup.Config, endpoints []server.Endpoint, name string, method string) (server.Endpoint, error) {
+    name = filepath.ToSlash(name)
+    root := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(cfg.SQLRoot)), "/") + "/"
+    route := "/" + strings.TrimPrefix(name, "/")
+
+    var matches []server.Endpoint
+    for _, endpoint := range endpoints {
+        if method != "" && endpoint.Method != method {
+            continue
+        }
+        sqlPath := filepath.ToSlash(filepath.Clean(endpoint.SQLPath))
+        switch {
+        case sqlPath == filepath.ToSlash(filepath.Clean(name)),
+            strings.TrimPrefix(sqlPath, root) == name,
+            endpoint.Path == route,
+            endpoint.Path == cfg.BaseURL+route:
+            matches = append(matches, endpoint)
+        }
+    }
+
+    switch len(matches) {
+    case 0:
+        return server.Endpoint{}, fmt.Errorf("no endpoint matches %q; run gosql routes to list them", name)
+    case 1:
+        return matches[0], nil
+    }
+    var candidates []string
+    for _, endpoint := range matches {
+        candidates = append(candidates, endpoint.Method+" "+endpoint.SQLPath)
+    }
+    return server.Endpoint{}, fmt.Errorf("%q matches %s; pick one with -method", name, strings.Join(candidates, ", "))
+}
+
+// endpointRequest builds the request a client would send with params: path parameters
+// in the URL, the rest in the query string for GET and DELETE or as a JSON body for POST
+// and PUT, where values that parse as JSON are sent as JSON values
+func endpointRequest(endpoint server.Endpoint, params map[string]string) (*http.Request, error) {
+    rest := make(map[string]string, len(params))
+    for key, value := range params {
+        rest[key] = value
+    }
+
+    // Fill in {name} and {name...} route segments
+    segments := strings.Split(endpoint.Path, "/")
+    for i, segment := range segments {
+        if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+            continue
+        }
+        name := strings.Trim(segment, "{}")
+        remainder := strings.HasSuffix(name, "...")
+        name = strings.TrimSuffix(name, "...")
+        value, ok := rest[name]
+        if !ok {
+            return nil, fmt.Errorf("%s %s needs the path parameter %s=<value>", endpoint.Method, endpoint.Path, name)
+        }
+        delete(rest, name)
+        if remainder {
+            segments[i] = value
+        } else {
+            segments[i] = url.PathEscape(value)
+        }
+    }
+    target := strings.Join(segments, "/")
+
+    var body bytes.Buffer
+    if endpoint.Method == "POST" || endpoint.Method == "PUT" {
+        values := make(map[string]interface{}, len(rest))
+        for key, value := range rest {
+            var parsed interface{}
+            if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+                values[key] = parsed
+            } else {
+                values[key] = value
+            }
+        }
+        if err := json.NewEncoder(&body).Encode(values); err != nil {
+            return nil, err
+        }
+    } else if len(rest) > 0 {
+        query := make(url.Values, len(rest))
+        for key, value := range rest {
+            query.Set(key, value)
+        }
+        target += "?" + query.Encode()
+    }
+
+    request := httptest.NewRequest(endpoint.Method, target, &body)
+    request.Header.Set("Content-Type", "application/json")
+    return request, nil
+}
+
+// serveEndpoint routes the request to handler on the endpoint's pattern, as the server
+// does, so path parameters are extracted the same way
+func serveEndpoint(endpoint server.Endpoint, handler http.HandlerFunc, request *http.Request) (int, []byte) {
+    mux := http.NewServeMux()
+    mux.HandleFunc(endpoint.Path, handler)
+    recorder := httptest.NewRecorder()
+    mux.ServeHTTP(recorder, request)
+    return recorder.Code, recorder.Body.Bytes()
+}
+
+// prepareEndpoint extracts the request's parameters and expands the endpoint's SQL file
+// as the handler would, without running it
+// Authentication is not applied, so auth_ parameters bind as NULL
+func prepareEndpoint(db *database.Database, endpoint server.Endpoint, request *http.Request) (server.PreparedSQL, error) {
+    var prepared server.PreparedSQL
+    var err error
+    serveEndpoint(endpoint, func(w http.ResponseWriter, r *http.Request) {
+        var params map[string]interface{}
+        params, err = server.ExtractRequestParams(r)
+        if err != nil {
+            return
+        }
+        prepared, err = server.PrepareSQL(r.Context(), db, endpoint.SQLPath, params, auth.Params(nil))
+    }, request)
+    return prepared, err
+}
+
+// BoundArg is one bound argument in dry-run output
+type BoundArg struct {
+    Name  string      `json:"name,omitempty"` // Parameter name, empty for positional arguments
+    Value interface{} `json:"value"`          // Bound value
+}
+
+// printPrepared writes the expanded SQL and its arguments; as a JSON object for -format json
+func printPrepared(endpoint server.Endpoint, prepared server.PreparedSQL, format string) int {
+    args := make([]BoundArg, len(prepared.Args))
+    for i, arg := range prepared.Args {
+        if named, ok := arg.(sql.NamedArg); ok {
+            args[i] = BoundArg{Name: named.Name, Value: named.Value}
+        } else {
+            args[i] = BoundArg{Value: arg}
+        }
+    }
+
+    if format == FormatJSON {
+        return printJSON(map[string]interface{}{
+            "method": endpoint.Method,
+            "path":   endpoint.Path,
+            "file":   endpoint.SQLPath,
+            "sql":    prepared.SQL,
+            "args":   args,
+        })
+    }
+
+    fmt.Printf("-- %s %s (%s)\n", endpoint.Method, endpoint.Path, endpoint.SQLPath)
+    fmt.Println(strings.TrimRight(prepared.SQL, "\n"))
+    fmt.Println("-- args:")
+    if len(args) == 0 {
+        fmt.Println("--   (none)")
+    }
+    for i, arg := range args {
+        value, _ := json.Marshal(arg.Value)
+        if arg.Name != "" {
+            fmt.Printf("--   :%s = %s\n", arg.Name, value)
+        } else {
+            fmt.Printf("--   ?%d = %s\n", i+1, value)
+        }
+    }
+    return ExitOK
+}
+
+// printResult writes an endpoint's response data in the chosen format
+// Row results are a header row followed by the rows; other statements report the
+// affected row count and last insert id
+func printResult(w io.Writer, data json.RawMessage, format string) error {
+    if format == FormatJSON {
+        var out bytes.Buffer
+        if err := json.Indent(&out, data, "", "  "); err != nil {
+            return err
+        }
+        out.WriteByte('\n')
+        _, err := w.Write(out.Bytes())
+        return err
+    }
+
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    decoder.UseNumber()
+    var result []interface{}
+    if err := decoder.Decode(&result); err != nil {
+        return fmt.Errorf("unexpected result: %w", err)
+    }
+    var rows [][]interface{}
+    returnsRows := len(result) > 0
+    for _, row := range result {
+        values, ok := row.([]interface{})
+        returnsRows = returnsRows && ok
+        rows = append(rows, values)
+    }
+    if !returnsRows {
+        rows = [][]interface{}{{"rows_affected", "last_insert_id"}, result}
+    }
+
+    if format == FormatCSV {
+        out := csv.NewWriter(w)
+        for _, row := range rows {
+            record := make([]string, len(row))
+            for i, value := range row {
+                if value != nil {
+                    record[i] = fmt.Sprint(value)
+                }
+            }
+            out.Write(record)
+        }
+        out.Flush()
+        return out.Error()
+    }
+
+    out := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+    for _, row := range rows {
+        cells := make([]string, len(row))
+        for i, value := range row {
+            cells[i] = "NULL"
+            if value != nil {
+                cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(fmt.Sprint(value))
+            }
+        }
+        fmt.Fprintln(out, strings.Join(cells, "\t"))
+    }
+    if err := out.Flush(); err != nil {
+        return err
+    }
+    if returnsRows {
+        fmt.Fprintf(os.Stderr, "(%d row(s))\n", max(len(rows)-1, 0))
+    }
+    return nil
+}
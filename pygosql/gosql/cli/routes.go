@@ -0,0 +1,88 @@
+// routes.go
+package cli
+
+import (
+    "fmt"
+    "gosql/app"
+    "gosql/database"
+    "gosql/server"
+    "gosql/setup"
+    "os"
+    "strings"
+    "text/tabwriter"
+)
+
+// RouteInfo is one line of the routes listing
+type RouteInfo struct {
+    Method      string   `json:"method"`           // HTTP method
+    Path        string   `json:"path"`             // HTTP route path
+    File        string   `json:"file,omitempty"`   // SQL file, empty for system endpoints
+    Description string   `json:"description"`      // From the @description header or the system route
+    Scopes      []string `json:"scopes,omitempty"` // Scopes a caller needs
+}
+
+// runRoutes lists the route of every SQL file, and of the system endpoints with -system
+func runRoutes(args []string) int {
+    flags := newFlagSet("routes")
+    config := addConfigFlags(flags)
+    asJSON := flags.Bool("json", false, "Print a JSON array")
+    system := flags.Bool("system", false, "Also list the built-in system endpoints")
+    cfg, positional, code, ok := loadCommandConfig(flags, config, args)
+    if !ok {
+        return code
+    }
+    if len(positional) > 0 {
+        return usageError("routes", "unexpected argument %q", positional[0])
+    }
+
+    db, endpoints, err := openEndpoints(cfg)
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    defer db.Close()
+
+    var routes []RouteInfo
+    if *system {
+        for _, route := range server.EnabledSystemRoutes(cfg) {
+            routes = append(routes, RouteInfo{Method: route.Method, Path: route.Path, Description: route.Description})
+        }
+    }
+    for _, endpoint := range endpoints {
+        routes = append(routes, RouteInfo{
+            Method:      endpoint.Method,
+            Path:        endpoint.Path,
+            File:        endpoint.SQLPath,
+            Description: endpoint.Description,
+            Scopes:      endpoint.Scopes,
+        })
+    }
+
+    if *asJSON {
+        if routes == nil {
+            routes = []RouteInfo{}
+        }
+        return printJSON(routes)
+    }
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "METHOD\tPATH\tFILE\tSCOPES")
+    for _, route := range routes {
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", route.Method, route.Path, orDash(route.File), orDash(strings.Join(route.Scopes, " ")))
+    }
+    w.Flush()
+    return ExitOK
+}
+
+// openEndpoints opens the configured database and loads the endpoints without building
+// a server; the caller closes the database
+func openEndpoints(cfg setup.Config) (*database.Database, []server.Endpoint, error) {
+    db, err := app.OpenDatabase(cfg)
+    if err != nil {
+        return nil, nil, err
+    }
+    endpoints, err := app.LoadEndpoints(cfg, db)
+    if err != nil {
+        db.Close()
+        return nil, nil, err
+    }
+    return db, endpoints, nil
+}
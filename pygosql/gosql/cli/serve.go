@@ -0,0 +1,168 @@
+// serve.go
+package cli
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "gosql/app"
+    "gosql/database"
+    "gosql/server"
+    "gosql/setup"
+    "log/slog"
+    "net/http"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+)
+
+// runServe sets up the configuration, discovers SQL files, creates endpoints and serves
+// them until interrupted
+func runServe(args []string) int {
+    fs := newFlagSet("serve")
+    config := addConfigFlags(fs)
+    genPython := fs.String("gen-python", "", "Write the typed Python module to this path on startup and reload (.pyi for a stub)")
+    positional, code, ok := parseArgs(fs, args)
+    if !ok {
+        return code
+    }
+    if len(positional) > 0 {
+        return usageError("serve", "unexpected argument %q", positional[0])
+    }
+    cfg, sources, code, ok := config.load()
+    if !ok {
+        return code
+    }
+
+    // Configure logging first so every later record honours the level and format
+    if err := setupLogging(cfg, sources, false); err != nil {
+        return fail(ExitUsage, err)
+    }
+    slog.Info("starting PyGoSQL")
+
+    slog.Info("configuration",
+        "port", cfg.Port,
+        "bind", cfg.BindAddresses,
+        "socket", cfg.UnixSocket,
+        "database", cfg.DatabasePath,
+        "sql_root", cfg.SQLRoot,
+        "schema", cfg.SchemaPath,
+        "base_url", cfg.BaseURL,
+        "debug", cfg.DebugMode,
+        "cors", cfg.EnableCORS,
+        "graphql", cfg.EnableGraphQL,
+        "auth", cfg.EnableAuth,
+        "rate_limit", cfg.RateLimit,
+        "scheme", cfg.Scheme())
+
+    // Check if SQL root directory exists
+    if _, err := os.Stat(cfg.SQLRoot); os.IsNotExist(err) {
+        slog.Warn("SQL root directory does not exist", "sql_root", cfg.SQLRoot)
+    }
+
+    // Run setup if it is incomplete
+    if !IsSetupComplete(cfg) {
+        slog.Info("running initial setup")
+        if err := RunSetup(cfg); err != nil {
+            return fail(ExitFailure, fmt.Errorf("Setup failed: %w", err))
+        }
+        slog.Info("setup completed")
+    }
+
+    // Open the database, load the SQL endpoints and build the server
+    a, err := app.New(app.Options{Config: cfg, Scaffold: true})
+    if err != nil {
+        return fail(ExitFailure, err)
+    }
+    endpoints := a.Endpoints()
+
+    // Keep the typed Python module in sync with the schema and SQL files
+    if *genPython != "" {
+        if err := writePythonModule(cfg, endpoints, *genPython); err != nil {
+            a.Close()
+            return fail(ExitFailure, err)
+        }
+    }
+
+    if len(endpoints) == 0 {
+        slog.Warn("no endpoints found, creating example endpoints")
+        // Create a minimal example if no endpoints exist
+        endpoints = createExampleEndpoints(a.DB(), cfg.BaseURL)
+        if err := a.Server().SetEndpoints(endpoints); err != nil {
+            a.Close()
+            return fail(ExitFailure, err)
+        }
+    }
+
+    slog.Info("loaded endpoints", "count", len(endpoints))
+
+    // Serve until interrupted; SIGHUP reloads the SQL files and TLS certificate
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    go reloadOnHangup(a, *genPython)
+
+    if err := a.Start(ctx); err != nil {
+        return fail(ExitFailure, fmt.Errorf("Server failed: %w", err))
+    }
+    return ExitOK
+}
+
+// reloadOnHangup reloads the app each time the process receives SIGHUP, and writes the
+// typed Python module again when genPython is set
+func reloadOnHangup(a *app.App, genPython string) {
+    hangup := make(chan os.Signal, 1)
+    signal.Notify(hangup, syscall.SIGHUP)
+    for range hangup {
+        if err := a.Reload(); err != nil {
+            slog.Error("reload failed, keeping the current endpoints", "error", err)
+            continue
+        }
+        slog.Info("reloaded", "endpoints", len(a.Endpoints()))
+        if genPython != "" {
+            if err := writePythonModule(a.Config(), a.Endpoints(), genPython); err != nil {
+                slog.Error("reload could not update the Python module", "error", err)
+            }
+        }
+    }
+}
+
+// writePythonModule writes the typed Python module for the endpoints to path
+func writePythonModule(cfg setup.Config, endpoints []server.Endpoint, path string) error {
+    spec, err := server.NewCodegenSpec(cfg, endpoints)
+    if err == nil {
+        err = WriteGenerated("python", path, spec, "", false)
+    }
+    if err != nil {
+        return fmt.Errorf("Failed to write Python module: %w", err)
+    }
+    slog.Info("wrote typed Python module", "path", path)
+    return nil
+}
+
+// createExampleEndpoints creates minimal example endpoints when none are found
+func createExampleEndpoints(db *database.Database, baseURL string) []server.Endpoint {
+    return []server.Endpoint{
+        {
+            Path:        baseURL + "/example",
+            Method:      "GET",
+            Handler:     createExampleHandler(),
+            SQLPath:     "example.sql",
+            TableName:   "",
+            IsUniversal: true,
+        },
+    }
+}
+
+// createExampleHandler creates a simple example handler
+func createExampleHandler() http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        response := map[string]interface{}{
+            "success": true,
+            "message": "GoSQL is running! Add SQL files to create real endpoints.",
+            "timestamp": time.Now().Format(time.RFC3339),
+        }
+        json.NewEncoder(w).Encode(response)
+    }
+}
@@ -0,0 +1,190 @@
+// codegen_test.go
+package codegen
+
+import (
+    "flag"
+    "fmt"
+    "go/ast"
+    "go/importer"
+    "go/parser"
+    "go/token"
+    "go/types"
+    "gosql/database"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata")
+
+// testSpec covers table and universal endpoints, nested routes whose last segments
+// match, path parameters, optional, nullable and typed parameters, row and exec results
+func testSpec() Spec {
+    return Spec{
+        Version: "1.0.0",
+        BaseURL: "/api/v1",
+        Endpoints: []Endpoint{
+            {
+                Namespace:   "users",
+                Name:        "by_email",
+                Method:      "GET",
+                Path:        "/api/v1/users/by_email",
+                Description: `Fetch users by "email"`,
+                SQLPath:     "sql/Tables/users/GET/by_email.sql",
+                Params: []database.Param{
+                    {Name: "email", Type: "string", Required: true, In: "query", Declared: true},
+                    {Name: "limit", Type: "integer", In: "query", Description: "Maximum number of rows", Declared: true},
+                },
+                Columns: []database.Column{
+                    {Name: "id", Type: "INTEGER", NotNull: true},
+                    {Name: "name", Type: "TEXT", NotNull: true},
+                    {Name: "score", Type: "REAL"},
+                },
+                ReturnsRows: true,
+            },
+            {
+                Namespace: "users",
+                Name:      "id_posts",
+                Method:    "GET",
+                Path:      "/api/v1/users/{id}/posts",
+                SQLPath:   "sql/Tables/users/GET/{id}/posts.sql",
+                Params: []database.Param{
+                    {Name: "id", Type: "integer", Required: true, In: "path"},
+                },
+                ReturnsRows: true,
+            },
+            {
+                Namespace:    "users",
+                Name:         "insert",
+                Method:       "POST",
+                Path:         "/api/v1/users/insert",
+                SQLPath:      "sql/Tables/users/POST/insert.sql",
+                Params: []database.Param{
+                    {Name: "active", Type: "boolean", In: "body"},
+                    {Name: "nickname", Type: "string", Required: true, Nullable: true, In: "body", Declared: true},
+                },
+                AcceptsExtra: true,
+            },
+            {
+                Namespace:   "system",
+                Name:        "reports_daily",
+                Method:      "GET",
+                Path:        "/api/v1/reports/daily",
+                SQLPath:     "sql/GET/reports/daily.sql",
+                Columns:     []database.Column{{Name: "day", Type: "TEXT"}, {Name: "total", Type: "NUMERIC"}},
+                ReturnsRows: true,
+            },
+            {
+                Namespace:  "system",
+                Name:       "daily",
+                Method:     "POST",
+                Path:       "/api/v1/daily",
+                SQLPath:    "sql/POST/daily.sql",
+                Positional: 1,
+            },
+        },
+    }
+}
+
+func TestGolden(t *testing.T) {
+    spec := testSpec()
+    goClient, err := Go(spec, GoOptions{Package: "gosqlclient"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    outputs := map[string]string{
+        "client.py.golden":  Python(spec, PythonOptions{}),
+        "client.pyi.golden": Python(spec, PythonOptions{Stub: true}),
+        "client.ts.golden":  TypeScript(spec),
+        "client.go.golden":  goClient,
+    }
+
+    for name, got := range outputs {
+        path := filepath.Join("testdata", name)
+        if *update {
+            if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+                t.Fatal(err)
+            }
+            continue
+        }
+        want, err := os.ReadFile(path)
+        if err != nil {
+            t.Fatalf("%v; run go test ./codegen -update to create it", err)
+        }
+        if got != string(want) {
+            t.Errorf("%s differs from the generated output; run go test ./codegen -update and review the diff\n%s", path, firstDifference(string(want), got))
+        }
+    }
+}
+
+// Every endpoint must appear in every client, including ones whose last route segments match
+func TestGoldenKeepsEveryEndpoint(t *testing.T) {
+    spec := testSpec()
+    goClient, err := Go(spec, GoOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    python, typescript := Python(spec, PythonOptions{}), TypeScript(spec)
+    for _, endpoint := range spec.Endpoints {
+        if !strings.Contains(python, "async def "+endpoint.Name+"(") {
+            t.Errorf("Python client is missing %s.%s", endpoint.Namespace, endpoint.Name)
+        }
+        if !strings.Contains(typescript, Camel(endpoint.Name)+": (") {
+            t.Errorf("TypeScript client is missing %s.%s", endpoint.Namespace, Camel(endpoint.Name))
+        }
+        if !strings.Contains(goClient, ") "+goName(endpoint.Namespace+"_"+endpoint.Name)+"(ctx") {
+            t.Errorf("Go client is missing %s", goName(endpoint.Namespace+"_"+endpoint.Name))
+        }
+    }
+}
+
+func TestGoClientTypeChecks(t *testing.T) {
+    source, err := Go(testSpec(), GoOptions{Package: "gosqlclient"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    fset := token.NewFileSet()
+    file, err := parser.ParseFile(fset, "client.go", source, 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+    if _, err := config.Check("gosqlclient", fset, []*ast.File{file}, nil); err != nil {
+        t.Fatalf("generated Go client does not type-check: %v", err)
+    }
+}
+
+func TestValidateReportsCollisions(t *testing.T) {
+    spec := testSpec()
+    if err := spec.Validate(); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    spec.Endpoints = append(spec.Endpoints, Endpoint{Namespace: "system", Name: "reports_daily", Method: "GET", Path: "/api/v1/reports_daily"})
+    err := spec.Validate()
+    if err == nil {
+        t.Fatal("expected a collision error")
+    }
+    if !strings.Contains(err.Error(), "/api/v1/reports/daily") || !strings.Contains(err.Error(), "/api/v1/reports_daily") {
+        t.Errorf("error does not name both routes: %v", err)
+    }
+}
+
+// firstDifference shows the first line where want and got differ
+func firstDifference(want, got string) string {
+    wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+    for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+        var w, g string
+        if i < len(wantLines) {
+            w = wantLines[i]
+        }
+        if i < len(gotLines) {
+            g = gotLines[i]
+        }
+        if w != g {
+            return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, w, g)
+        }
+    }
+    return ""
+}
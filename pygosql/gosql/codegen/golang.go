@@ -0,0 +1,307 @@
+// golang.go
+package codegen
+
+import (
+    "fmt"
+    "go/format"
+    "strings"
+)
+
+// goInitialisms are kept upper case in generated Go identifiers
+var goInitialisms = map[string]string{
+    "Id": "ID", "Url": "URL", "Uri": "URI", "Api": "API", "Http": "HTTP",
+    "Json": "JSON", "Sql": "SQL", "Uuid": "UUID", "Ip": "IP",
+}
+
+// GoOptions controls the Go client generator
+type GoOptions struct {
+    Package string // Package name of the generated file (default: gosqlclient)
+}
+
+// Go generates a typed net/http client for a Spec
+// Each endpoint becomes a Client method named after its namespace and function name
+func Go(spec Spec, opts GoOptions) (string, error) {
+    pkg := opts.Package
+    if pkg == "" {
+        pkg = "gosqlclient"
+    }
+
+    var b strings.Builder
+    fmt.Fprintf(&b, "// Code generated by gosql gen go. DO NOT EDIT.\n")
+    fmt.Fprintf(&b, "// API version %s, base URL %s\n\n", spec.Version, spec.BaseURL)
+    fmt.Fprintf(&b, "package %s\n\n", pkg)
+    b.WriteString(`import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the GoSQL HTTP API
+type Client struct {
+	BaseURL    string      // Server address, e.g. "http://localhost:8080"
+	HTTPClient *http.Client // HTTP client used for requests (default: http.DefaultClient)
+	Header     http.Header  // Extra headers sent with every request
+}
+
+// New creates a Client for the server at baseURL
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient, Header: http.Header{}}
+}
+
+// Error is returned for every non-2xx response
+type Error struct {
+	StatusCode int    // HTTP status code
+	Message    string // Error message from the response body
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("gosql: %d %s", e.StatusCode, e.Message)
+}
+
+// ExecResult is the result of a statement that does not return rows
+type ExecResult struct {
+	RowsAffected int64 // Number of rows changed by the statement
+	LastInsertID int64 // Row id of the last inserted row
+}
+
+// envelope is the response body shared by all endpoints
+type envelope struct {
+	Success bool            ` + "`json:\"success\"`" + `
+	Data    json.RawMessage ` + "`json:\"data\"`" + `
+	Error   string          ` + "`json:\"error\"`" + `
+}
+
+// do sends a request and returns the raw data field of a successful response
+func (c *Client) do(ctx context.Context, method, route string, params map[string]interface{}, pathParams []string) (json.RawMessage, error) {
+	for _, name := range pathParams {
+		value := fmt.Sprint(params[name])
+		route = strings.Replace(route, "{"+name+"...}", url.PathEscape(value), 1)
+		route = strings.Replace(route, "{"+name+"}", url.PathEscape(value), 1)
+		delete(params, name)
+	}
+
+	var body *bytes.Reader
+	if method == "POST" || method == "PUT" {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("gosql: encode body: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	} else {
+		query := url.Values{}
+		for key, value := range params {
+			query.Set(key, fmt.Sprint(value))
+		}
+		if len(query) > 0 {
+			route += "?" + query.Encode()
+		}
+		body = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+route, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if method == "POST" || method == "PUT" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result envelope
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, &Error{StatusCode: resp.StatusCode, Message: "invalid JSON response"}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || !result.Success {
+		return nil, &Error{StatusCode: resp.StatusCode, Message: result.Error}
+	}
+	return result.Data, nil
+}
+
+// decodeExec decodes the [rows_affected, last_insert_id] data of a write statement
+func decodeExec(data json.RawMessage) (ExecResult, error) {
+	var values [2]int64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return ExecResult{}, fmt.Errorf("gosql: decode result: %w", err)
+	}
+	return ExecResult{RowsAffected: values[0], LastInsertID: values[1]}, nil
+}
+
+// decodeRows splits [header, ...rows] data into rows of raw cells
+func decodeRows(data json.RawMessage) ([][]json.RawMessage, error) {
+	var table [][]json.RawMessage
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("gosql: decode rows: %w", err)
+	}
+	if len(table) == 0 {
+		return nil, nil
+	}
+	return table[1:], nil
+}
+
+`)
+
+    order, groups := spec.Namespaces()
+    for _, namespace := range order {
+        for _, endpoint := range groups[namespace] {
+            writeGoEndpoint(&b, endpoint)
+        }
+    }
+
+    formatted, err := format.Source([]byte(b.String()))
+    if err != nil {
+        return b.String(), fmt.Errorf("generated Go code does not compile: %w", err)
+    }
+    return string(formatted), nil
+}
+
+// writeGoEndpoint writes the params struct, row struct and method of one endpoint
+func writeGoEndpoint(b *strings.Builder, endpoint Endpoint) {
+    typeName := goName(endpoint.Namespace + "_" + endpoint.Name)
+    extra := endpoint.AcceptsExtra || endpoint.Positional > 0
+
+    // Params struct
+    fmt.Fprintf(b, "// %sParams holds the parameters of %s %s\n", typeName, endpoint.Method, endpoint.Path)
+    fmt.Fprintf(b, "type %sParams struct {\n", typeName)
+    for _, param := range endpoint.Params {
+        fieldType := goType(param.Type)
+        if (!param.Required || param.Nullable) && fieldType != "interface{}" {
+            fieldType = "*" + fieldType
+        }
+        comment := param.Description
+        if comment == "" {
+            comment = fmt.Sprintf("%s parameter %q", param.In, param.Name)
+        }
+        fmt.Fprintf(b, "\t%s %s // %s\n", goName(param.Name), fieldType, comment)
+    }
+    if extra {
+        b.WriteString("\tExtra map[string]interface{} // Additional values sent as-is\n")
+    }
+    b.WriteString("}\n\n")
+
+    // Params map conversion
+    fmt.Fprintf(b, "func (p %sParams) values() map[string]interface{} {\n", typeName)
+    b.WriteString("\tvalues := make(map[string]interface{})\n")
+    if extra {
+        b.WriteString("\tfor key, value := range p.Extra {\n\t\tvalues[key] = value\n\t}\n")
+    }
+    for _, param := range endpoint.Params {
+        field := goName(param.Name)
+        fieldType := goType(param.Type)
+        switch {
+        case param.Required && param.Nullable:
+            // A nil pointer is sent as null
+            fmt.Fprintf(b, "\tvalues[%q] = p.%s\n", param.Name, field)
+        case fieldType == "interface{}":
+            fmt.Fprintf(b, "\tif p.%s != nil {\n\t\tvalues[%q] = p.%s\n\t}\n", field, param.Name, field)
+        case !param.Required:
+            fmt.Fprintf(b, "\tif p.%s != nil {\n\t\tvalues[%q] = *p.%s\n\t}\n", field, param.Name, field)
+        default:
+            fmt.Fprintf(b, "\tvalues[%q] = p.%s\n", param.Name, field)
+        }
+    }
+    b.WriteString("\treturn values\n}\n\n")
+
+    var pathParams []string
+    for _, param := range endpoint.Params {
+        if param.In == "path" {
+            pathParams = append(pathParams, fmt.Sprintf("%q", param.Name))
+        }
+    }
+    call := fmt.Sprintf("c.do(ctx, %q, %q, params.values(), []string{%s})", endpoint.Method, endpoint.Path, strings.Join(pathParams, ", "))
+
+    doc := fmt.Sprintf("%s calls %s %s", typeName, endpoint.Method, endpoint.Path)
+    if endpoint.Description != "" {
+        doc += "\n// " + strings.ReplaceAll(endpoint.Description, "\n", " ")
+    }
+
+    if !endpoint.ReturnsRows {
+        fmt.Fprintf(b, "// %s\n", doc)
+        fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context, params %sParams) (ExecResult, error) {\n", typeName, typeName)
+        fmt.Fprintf(b, "\tdata, err := %s\n\tif err != nil {\n\t\treturn ExecResult{}, err\n\t}\n\treturn decodeExec(data)\n}\n\n", call)
+        return
+    }
+
+    if len(endpoint.Columns) == 0 {
+        fmt.Fprintf(b, "// %s\n", doc)
+        fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context, params %sParams) ([][]json.RawMessage, error) {\n", typeName, typeName)
+        fmt.Fprintf(b, "\tdata, err := %s\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn decodeRows(data)\n}\n\n", call)
+        return
+    }
+
+    // Row struct and decoding
+    fmt.Fprintf(b, "// %sRow is a row returned by %s %s\n", typeName, endpoint.Method, endpoint.Path)
+    fmt.Fprintf(b, "type %sRow struct {\n", typeName)
+    for _, column := range endpoint.Columns {
+        fieldType := goType(columnJSONType(column))
+        if fieldType != "interface{}" {
+            fieldType = "*" + fieldType
+        }
+        fmt.Fprintf(b, "\t%s %s // Column %q\n", goName(column.Name), fieldType, column.Name)
+    }
+    b.WriteString("}\n\n")
+
+    fmt.Fprintf(b, "// %s\n", doc)
+    fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context, params %sParams) ([]%sRow, error) {\n", typeName, typeName, typeName)
+    fmt.Fprintf(b, "\tdata, err := %s\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", call)
+    b.WriteString("\trows, err := decodeRows(data)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+    fmt.Fprintf(b, "\tresult := make([]%sRow, len(rows))\n", typeName)
+    b.WriteString("\tfor i, row := range rows {\n")
+    fmt.Fprintf(b, "\t\tif len(row) != %d {\n\t\t\treturn nil, fmt.Errorf(\"gosql: expected %d columns, got %%d\", len(row))\n\t\t}\n", len(endpoint.Columns), len(endpoint.Columns))
+    for i, column := range endpoint.Columns {
+        fmt.Fprintf(b, "\t\tif err := json.Unmarshal(row[%d], &result[i].%s); err != nil {\n\t\t\treturn nil, fmt.Errorf(\"gosql: decode column %s: %%w\", err)\n\t\t}\n", i, goName(column.Name), column.Name)
+    }
+    b.WriteString("\t}\n\treturn result, nil\n}\n\n")
+}
+
+// goName converts a name to an exported Go identifier, keeping common initialisms upper case
+func goName(name string) string {
+    var b strings.Builder
+    for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+        return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+    }) {
+        word = strings.ToUpper(word[:1]) + word[1:]
+        if initialism, ok := goInitialisms[word]; ok {
+            word = initialism
+        }
+        b.WriteString(word)
+    }
+    result := b.String()
+    if result == "" || result[0] >= '0' && result[0] <= '9' {
+        result = "X" + result
+    }
+    return result
+}
+
+// goType maps a JSON type to a Go type
+func goType(jsonType string) string {
+    switch jsonType {
+    case "string":
+        return "string"
+    case "integer":
+        return "int64"
+    case "number":
+        return "float64"
+    case "boolean":
+        return "bool"
+    default:
+        return "interface{}"
+    }
+}
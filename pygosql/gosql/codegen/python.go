@@ -0,0 +1,190 @@
+// python.go
+package codegen
+
+import (
+    "fmt"
+    "strings"
+)
+
+// pythonKeywords cannot be used as parameter or attribute names in generated Python
+var pythonKeywords = map[string]bool{
+    "False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+    "async": true, "await": true, "break": true, "class": true, "continue": true,
+    "def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true,
+    "for": true, "from": true, "global": true, "if": true, "import": true, "in": true,
+    "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true,
+    "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// PythonOptions controls the Python module generator
+type PythonOptions struct {
+    Stub bool // Emit a .pyi stub (bodies replaced with ...) instead of a runnable module
+}
+
+// Python generates a typed Python module describing the PyGoSQL namespaces of a Spec
+// Row dataclasses mirror result columns and namespace protocols mirror endpoint parameters
+func Python(spec Spec, opts PythonOptions) string {
+    var b strings.Builder
+    body := func(code string) string {
+        if opts.Stub {
+            return "..."
+        }
+        return code
+    }
+
+    fmt.Fprintf(&b, "# Code generated by gosql gen python. DO NOT EDIT.\n")
+    fmt.Fprintf(&b, "# API version %s, base URL %s\n", spec.Version, spec.BaseURL)
+    b.WriteString(`from dataclasses import dataclass
+from typing import Any, Dict, List, Optional, Protocol, TypedDict, cast
+
+
+class Result(TypedDict, total=False):
+    """Response returned by every PyGoSQL endpoint."""
+    success: bool
+    data: Any
+    error: str
+    status_code: int
+    details: Dict[str, Any]
+
+
+`)
+
+    order, groups := spec.Namespaces()
+
+    // Row dataclasses
+    for _, namespace := range order {
+        for _, endpoint := range groups[namespace] {
+            if !endpoint.ReturnsRows || len(endpoint.Columns) == 0 || !IsIdentifier(endpoint.Name) {
+                continue
+            }
+            className := pythonRowClass(endpoint)
+            fmt.Fprintf(&b, "@dataclass\nclass %s:\n", className)
+            fmt.Fprintf(&b, "    \"\"\"Row returned by %s %s.\"\"\"\n", endpoint.Method, endpoint.Path)
+            for _, column := range endpoint.Columns {
+                fmt.Fprintf(&b, "    %s: Optional[%s]\n", pythonName(column.Name), pythonType(columnJSONType(column)))
+            }
+            b.WriteString("\n    @classmethod\n")
+            fmt.Fprintf(&b, "    def from_result(cls, result: Result) -> List[%q]:\n", className)
+            b.WriteString("        \"\"\"Convert the [header, *rows] data of a result into dataclass instances.\"\"\"\n")
+            fmt.Fprintf(&b, "        %s\n\n\n", body("return [cls(*row) for row in (result.get(\"data\") or [])[1:]]"))
+        }
+    }
+
+    // Namespace protocols
+    for _, namespace := range order {
+        fmt.Fprintf(&b, "class %sNamespace(Protocol):\n", Pascal(namespace))
+        fmt.Fprintf(&b, "    \"\"\"Endpoints available as client.%s.\"\"\"\n", namespace)
+        for _, endpoint := range groups[namespace] {
+            if !IsIdentifier(endpoint.Name) || pythonKeywords[endpoint.Name] {
+                fmt.Fprintf(&b, "\n    # %s %s is only reachable via getattr(client.%s, %q)\n",
+                    endpoint.Method, endpoint.Path, namespace, endpoint.Name)
+                continue
+            }
+            fmt.Fprintf(&b, "\n    async def %s(%s) -> Result:\n", endpoint.Name, pythonSignature(endpoint))
+            var doc []string
+            if endpoint.Description != "" {
+                doc = append(doc, escapeDocstring(endpoint.Description))
+            }
+            doc = append(doc, fmt.Sprintf("%s %s", endpoint.Method, endpoint.Path))
+            if endpoint.ReturnsRows && len(endpoint.Columns) > 0 {
+                doc = append(doc, fmt.Sprintf("Rows: %s.from_result(result)", pythonRowClass(endpoint)))
+            }
+            if len(doc) == 1 {
+                fmt.Fprintf(&b, "        \"\"\"%s\"\"\"\n        ...\n", doc[0])
+            } else {
+                fmt.Fprintf(&b, "        \"\"\"%s\n        \"\"\"\n        ...\n", strings.Join(doc, "\n\n        "))
+            }
+        }
+        b.WriteString("\n\n")
+    }
+
+    // Typed view of the client
+    b.WriteString("class TypedPyGoSQL(Protocol):\n")
+    b.WriteString("    \"\"\"PyGoSQL client with the namespaces discovered from the server.\"\"\"\n")
+    for _, namespace := range order {
+        if IsIdentifier(namespace) && !pythonKeywords[namespace] {
+            fmt.Fprintf(&b, "    %s: %sNamespace\n", namespace, Pascal(namespace))
+        }
+    }
+    b.WriteString(`
+    async def launch(self) -> None: ...
+
+    async def stop(self) -> None: ...
+
+    async def health(self) -> Dict[str, Any]: ...
+
+    async def docs(self) -> Dict[str, Any]: ...
+
+
+def typed(client: Any) -> TypedPyGoSQL:
+    """Return the client annotated with the generated namespaces."""
+`)
+    fmt.Fprintf(&b, "    %s\n", body("return cast(TypedPyGoSQL, client)"))
+
+    return b.String()
+}
+
+// pythonSignature builds the keyword-only parameter list of an endpoint method
+func pythonSignature(endpoint Endpoint) string {
+    parts := []string{"self"}
+    var required, optional []string
+    for _, param := range endpoint.Params {
+        if !IsIdentifier(param.Name) || pythonKeywords[param.Name] {
+            continue
+        }
+        if param.Required && param.Nullable {
+            required = append(required, fmt.Sprintf("%s: Optional[%s]", param.Name, pythonType(param.Type)))
+        } else if param.Required {
+            required = append(required, fmt.Sprintf("%s: %s", param.Name, pythonType(param.Type)))
+        } else {
+            optional = append(optional, fmt.Sprintf("%s: Optional[%s] = None", param.Name, pythonType(param.Type)))
+        }
+    }
+    if len(required)+len(optional) > 0 {
+        parts = append(parts, "*")
+        parts = append(parts, required...)
+        parts = append(parts, optional...)
+    }
+    // Positional ? parameters bind whatever values are sent, in name order
+    if endpoint.AcceptsExtra || endpoint.Positional > 0 || len(required)+len(optional) < len(endpoint.Params) {
+        parts = append(parts, "**extra: Any")
+    }
+    return strings.Join(parts, ", ")
+}
+
+// pythonRowClass returns the dataclass name of an endpoint's rows
+func pythonRowClass(endpoint Endpoint) string {
+    return Pascal(endpoint.Namespace+"_"+endpoint.Name) + "Row"
+}
+
+// pythonType maps a JSON type to a Python annotation
+func pythonType(jsonType string) string {
+    switch jsonType {
+    case "string":
+        return "str"
+    case "integer":
+        return "int"
+    case "number":
+        return "float"
+    case "boolean":
+        return "bool"
+    default:
+        return "Any"
+    }
+}
+
+// pythonName returns a valid Python attribute name for a column
+func pythonName(name string) string {
+    if !IsIdentifier(name) {
+        name = strings.ToLower(Pascal(name))
+    }
+    if pythonKeywords[name] {
+        name += "_"
+    }
+    return name
+}
+
+// escapeDocstring keeps user text from terminating a generated docstring
+func escapeDocstring(text string) string {
+    return strings.ReplaceAll(strings.ReplaceAll(text, `\`, `\\`), `"""`, `\"\"\"`)
+}
@@ -0,0 +1,110 @@
+// spec.go
+package codegen
+
+import (
+    "fmt"
+    "gosql/database"
+    "strings"
+    "unicode"
+)
+
+// Spec is the language independent description of the API consumed by the generators
+type Spec struct {
+    Version   string     // API version reported by the server
+    BaseURL   string     // Base URL prefix for API endpoints
+    Endpoints []Endpoint // Endpoints in registration order
+}
+
+// Endpoint describes one SQL endpoint as seen by a generated client
+type Endpoint struct {
+    Namespace    string            // Client namespace: the table name, or "system" for universal endpoints
+    Name         string            // Function name: the route below the base URL and table, joined by underscores
+    Method       string            // HTTP method
+    Path         string            // HTTP route path, may contain {param} segments
+    Description  string            // Description from the SQL file metadata
+    SQLPath      string            // Path to the SQL file
+    Params       []database.Param  // Declared and inferred request parameters
+    Columns      []database.Column // Result columns for row-returning queries
+    Positional   int               // Number of positional ? parameters in the SQL
+    ReturnsRows  bool              // Whether the endpoint returns rows
+    AcceptsExtra bool              // Whether the JSON body accepts columns beyond Params
+}
+
+// Namespaces returns the endpoints grouped by namespace, in order of first appearance
+func (s Spec) Namespaces() ([]string, map[string][]Endpoint) {
+    var order []string
+    groups := make(map[string][]Endpoint)
+    for _, endpoint := range s.Endpoints {
+        if _, seen := groups[endpoint.Namespace]; !seen {
+            order = append(order, endpoint.Namespace)
+        }
+        groups[endpoint.Namespace] = append(groups[endpoint.Namespace], endpoint)
+    }
+    return order, groups
+}
+
+// Validate reports endpoints that share a function name in a namespace, which a
+// generated client could only expose one of
+func (s Spec) Validate() error {
+    var clashes []string
+    seen := make(map[string]Endpoint)
+    for _, endpoint := range s.Endpoints {
+        key := endpoint.Namespace + "." + endpoint.Name
+        if other, ok := seen[key]; ok {
+            clashes = append(clashes, fmt.Sprintf("%s %s and %s %s are both %s", other.Method, other.Path, endpoint.Method, endpoint.Path, key))
+            continue
+        }
+        seen[key] = endpoint
+    }
+    if len(clashes) > 0 {
+        return fmt.Errorf("function name collision: %s; rename one of the SQL files", strings.Join(clashes, "; "))
+    }
+    return nil
+}
+
+// Pascal converts a name such as "users_by_email" or "reports/daily" to "UsersByEmail"
+func Pascal(name string) string {
+    var b strings.Builder
+    upper := true
+    for _, r := range name {
+        if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+            upper = true
+            continue
+        }
+        if upper {
+            b.WriteRune(unicode.ToUpper(r))
+            upper = false
+        } else {
+            b.WriteRune(r)
+        }
+    }
+    result := b.String()
+    if result == "" || unicode.IsDigit(rune(result[0])) {
+        result = "X" + result
+    }
+    return result
+}
+
+// Camel converts a name such as "users_by_email" to "usersByEmail"
+func Camel(name string) string {
+    pascal := Pascal(name)
+    return strings.ToLower(pascal[:1]) + pascal[1:]
+}
+
+// IsIdentifier reports whether name is a valid identifier in the generated languages
+func IsIdentifier(name string) bool {
+    if name == "" || unicode.IsDigit(rune(name[0])) {
+        return false
+    }
+    for _, r := range name {
+        if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+            return false
+        }
+    }
+    return true
+}
+
+// columnJSONType returns the JSON type of a result column, or "" when unknown
+func columnJSONType(column database.Column) string {
+    return database.JSONType(column.Type)
+}
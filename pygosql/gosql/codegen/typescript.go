@@ -0,0 +1,199 @@
+// typescript.go
+package codegen
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// TypeScript generates a typed fetch-based client for a Spec
+// Each namespace becomes a property of GoSQLClient with one method per endpoint
+func TypeScript(spec Spec) string {
+    var b strings.Builder
+
+    fmt.Fprintf(&b, "// Code generated by gosql gen ts. DO NOT EDIT.\n")
+    fmt.Fprintf(&b, "// API version %s, base URL %s\n\n", spec.Version, spec.BaseURL)
+    b.WriteString(`/** Successful response envelope returned by every endpoint. */
+export interface Result<T> {
+  success: true;
+  data: T;
+}
+
+/** Error response envelope returned with 4xx and 5xx statuses. */
+export interface ErrorBody {
+  success: false;
+  error: string;
+}
+
+/** Result of a statement that does not return rows. */
+export type ExecResult = [rowsAffected: number, lastInsertId: number];
+
+/** Thrown for every non-2xx response. */
+export class GoSQLError extends Error {
+  constructor(public readonly status: number, public readonly body: ErrorBody | unknown) {
+    super(typeof body === "object" && body !== null && "error" in body ? String((body as ErrorBody).error) : ` + "`HTTP ${status}`" + `);
+    this.name = "GoSQLError";
+  }
+}
+
+export interface ClientOptions {
+  fetch?: typeof fetch;
+  headers?: Record<string, string>;
+}
+
+/** Converts [header, ...rows] result data into one object per row. */
+export function toRecords<R extends Record<string, unknown>>(data: [string[], ...unknown[][]]): R[] {
+  const [header, ...rows] = data;
+  return rows.map((row) => Object.fromEntries(header.map((name, i) => [name, row[i]])) as R);
+}
+
+`)
+
+    order, groups := spec.Namespaces()
+
+    // Parameter and row types
+    for _, namespace := range order {
+        for _, endpoint := range groups[namespace] {
+            typeName := Pascal(endpoint.Namespace + "_" + endpoint.Name)
+
+            fmt.Fprintf(&b, "/** Parameters of %s %s. */\n", endpoint.Method, endpoint.Path)
+            fmt.Fprintf(&b, "export interface %sParams {\n", typeName)
+            for _, param := range endpoint.Params {
+                optional := "?"
+                if param.Required {
+                    optional = ""
+                }
+                if param.Description != "" {
+                    fmt.Fprintf(&b, "  /** %s */\n", strings.ReplaceAll(param.Description, "*/", "* /"))
+                }
+                paramType := tsType(param.Type)
+                if param.Nullable && paramType != "unknown" {
+                    paramType += " | null"
+                }
+                fmt.Fprintf(&b, "  %s%s: %s;\n", tsKey(param.Name), optional, paramType)
+            }
+            if endpoint.AcceptsExtra || endpoint.Positional > 0 {
+                b.WriteString("  [column: string]: unknown;\n")
+            }
+            b.WriteString("}\n\n")
+
+            if endpoint.ReturnsRows && len(endpoint.Columns) > 0 {
+                names := make([]string, len(endpoint.Columns))
+                cells := make([]string, len(endpoint.Columns))
+                fmt.Fprintf(&b, "/** Row returned by %s %s. */\n", endpoint.Method, endpoint.Path)
+                fmt.Fprintf(&b, "export interface %sRecord {\n", typeName)
+                for i, column := range endpoint.Columns {
+                    names[i] = strconv.Quote(column.Name)
+                    cells[i] = tsType(columnJSONType(column)) + " | null"
+                    fmt.Fprintf(&b, "  %s: %s;\n", tsKey(column.Name), cells[i])
+                }
+                b.WriteString("}\n\n")
+                fmt.Fprintf(&b, "export type %sRow = [%s];\n", typeName, strings.Join(cells, ", "))
+                fmt.Fprintf(&b, "export type %sData = [header: [%s], ...rows: %sRow[]];\n\n", typeName, strings.Join(names, ", "), typeName)
+            }
+        }
+    }
+
+    // Client
+    b.WriteString(`export class GoSQLClient {
+  private readonly fetchImpl: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(private readonly baseUrl: string, options: ClientOptions = {}) {
+    this.fetchImpl = options.fetch ?? fetch.bind(globalThis);
+    this.headers = options.headers ?? {};
+  }
+
+  private async request<T>(method: string, route: string, params: object, pathParams: string[]): Promise<Result<T>> {
+    const rest: Record<string, unknown> = { ...(params as Record<string, unknown>) };
+    let path = route;
+    for (const name of pathParams) {
+      path = path.replace(new RegExp("\\{" + name + "(\\.\\.\\.)?\\}"), encodeURIComponent(String(rest[name])));
+      delete rest[name];
+    }
+
+    const init: RequestInit = { method, headers: { Accept: "application/json", ...this.headers } };
+    if (method === "POST" || method === "PUT") {
+      (init.headers as Record<string, string>)["Content-Type"] = "application/json";
+      init.body = JSON.stringify(rest);
+    } else {
+      const query = new URLSearchParams();
+      for (const [key, value] of Object.entries(rest)) {
+        if (value !== undefined && value !== null) query.set(key, String(value));
+      }
+      if ([...query.keys()].length > 0) path += "?" + query.toString();
+    }
+
+    const response = await this.fetchImpl(this.baseUrl.replace(/\/$/, "") + path, init);
+    const body = await response.json().catch(() => undefined);
+    if (!response.ok) {
+      throw new GoSQLError(response.status, body);
+    }
+    return body as Result<T>;
+  }
+`)
+    for _, namespace := range order {
+        fmt.Fprintf(&b, "\n  /** Endpoints of the %s namespace. */\n", namespace)
+        fmt.Fprintf(&b, "  readonly %s = {\n", tsKey(namespace))
+        for _, endpoint := range groups[namespace] {
+            typeName := Pascal(endpoint.Namespace + "_" + endpoint.Name)
+            dataType := "ExecResult"
+            if endpoint.ReturnsRows {
+                dataType = "[string[], ...unknown[][]]"
+                if len(endpoint.Columns) > 0 {
+                    dataType = typeName + "Data"
+                }
+            }
+
+            paramsArg := fmt.Sprintf("params: %sParams = {}", typeName)
+            for _, param := range endpoint.Params {
+                if param.Required {
+                    paramsArg = fmt.Sprintf("params: %sParams", typeName)
+                    break
+                }
+            }
+
+            pathParams := make([]string, 0)
+            for _, param := range endpoint.Params {
+                if param.In == "path" {
+                    pathParams = append(pathParams, strconv.Quote(param.Name))
+                }
+            }
+
+            doc := fmt.Sprintf("%s %s", endpoint.Method, endpoint.Path)
+            if endpoint.Description != "" {
+                doc = strings.ReplaceAll(endpoint.Description, "*/", "* /") + " (" + doc + ")"
+            }
+            fmt.Fprintf(&b, "    /** %s */\n", doc)
+            fmt.Fprintf(&b, "    %s: (%s): Promise<Result<%s>> =>\n", tsKey(Camel(endpoint.Name)), paramsArg, dataType)
+            fmt.Fprintf(&b, "      this.request<%s>(%q, %q, params, [%s]),\n", dataType, endpoint.Method, endpoint.Path, strings.Join(pathParams, ", "))
+        }
+        b.WriteString("  };\n")
+    }
+    b.WriteString("}\n")
+
+    return b.String()
+}
+
+// tsType maps a JSON type to a TypeScript type
+func tsType(jsonType string) string {
+    switch jsonType {
+    case "string":
+        return "string"
+    case "integer", "number":
+        return "number"
+    case "boolean":
+        return "boolean"
+    default:
+        return "unknown"
+    }
+}
+
+// tsKey quotes property names that are not valid identifiers
+func tsKey(name string) string {
+    if IsIdentifier(name) {
+        return name
+    }
+    return strconv.Quote(name)
+}
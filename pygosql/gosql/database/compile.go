@@ -0,0 +1,151 @@
+// compile.go
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "regexp"
+    "strings"
+    "unicode"
+)
+
+// Statement is one statement of a SQL file
+type Statement struct {
+    SQL  string // Statement text without leading comments or the closing semicolon
+    Line int    // 1-based line of the file the statement starts on
+}
+
+// SplitStatements splits a SQL file on the semicolons that end its statements, skipping
+// string literals, quoted identifiers and comments, and keeping the body of a CREATE
+// TRIGGER together
+// Statements that are only whitespace and comments are dropped
+func SplitStatements(content string) []Statement {
+    var statements []Statement
+    start := 0
+    add := func(end int) {
+        text := strings.TrimRightFunc(content[start:end], unicode.IsSpace)
+        statement := StripLeadingComments(text)
+        if statement != "" {
+            offset := start + len(text) - len(statement)
+            statements = append(statements, Statement{
+                SQL:  statement,
+                Line: strings.Count(content[:offset], "\n") + 1,
+            })
+        }
+    }
+
+    for i := 0; i < len(content); i++ {
+        c := content[i]
+        switch {
+        case c == '\'' || c == '"' || c == '`':
+            for i++; i < len(content); i++ {
+                if content[i] == c {
+                    if i+1 < len(content) && content[i+1] == c {
+                        i++
+                        continue
+                    }
+                    break
+                }
+            }
+        case c == '-' && i+1 < len(content) && content[i+1] == '-':
+            for i < len(content) && content[i] != '\n' {
+                i++
+            }
+        case c == '/' && i+1 < len(content) && content[i+1] == '*':
+            end := strings.Index(content[i+2:], "*/")
+            if end == -1 {
+                i = len(content)
+                break
+            }
+            i += end + 3
+        case c == ';':
+            // Statements inside a trigger body end with semicolons too; the trigger
+            // ends at the semicolon after END
+            if isTrigger(content[start:i]) && !endsWithEnd(content[start:i]) {
+                continue
+            }
+            add(i)
+            start = i + 1
+        }
+    }
+    add(len(content))
+    return statements
+}
+
+// isTrigger reports whether a statement is CREATE [TEMP] TRIGGER
+func isTrigger(statement string) bool {
+    fields := strings.Fields(strings.ToUpper(StripLeadingComments(statement)))
+    if len(fields) < 2 || fields[0] != "CREATE" {
+        return false
+    }
+    for _, field := range fields[1:min(len(fields), 3)] {
+        if field == "TRIGGER" {
+            return true
+        }
+    }
+    return false
+}
+
+// endsWithEnd reports whether a statement's last word is END
+func endsWithEnd(statement string) bool {
+    statement = strings.ToUpper(strings.TrimRightFunc(statement, unicode.IsSpace))
+    rest, ok := strings.CutSuffix(statement, "END")
+    return ok && (rest == "" || !isIdentByte(rest[len(rest)-1]))
+}
+
+// CompileSQL has SQLite compile one statement against the schema without running it,
+// with every bind parameter set to NULL, and reports whether the statement would write
+// to the database
+// Syntax errors and unknown tables or columns are returned as errors
+func (d *Database) CompileSQL(statement string) (writes bool, err error) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    if d.closed {
+        return false, fmt.Errorf("database is closed")
+    }
+
+    placeholders := FindPlaceholders(statement)
+    args := make([]interface{}, 0, placeholders.Positional+len(placeholders.Named))
+    for i := 0; i < placeholders.Positional; i++ {
+        args = append(args, nil)
+    }
+    for _, name := range placeholders.Named {
+        args = append(args, sql.Named(name, nil))
+    }
+
+    // EXPLAIN compiles the statement into its bytecode program; a write transaction
+    // starts with a Transaction opcode whose P2 is set
+    rows, err := d.DB.Query("EXPLAIN "+statement, args...)
+    if err != nil {
+        return false, err
+    }
+    defer rows.Close()
+
+    columns, err := rows.Columns()
+    if err != nil {
+        return false, err
+    }
+    values := make([]interface{}, len(columns))
+    pointers := make([]interface{}, len(columns))
+    for i := range values {
+        pointers[i] = &values[i]
+    }
+    for rows.Next() {
+        if err := rows.Scan(pointers...); err != nil {
+            return false, err
+        }
+        if len(values) > 3 && fmt.Sprint(values[1]) == "Transaction" && fmt.Sprint(values[3]) != "0" {
+            writes = true
+        }
+    }
+    return writes, rows.Err()
+}
+
+var errorDecoration = regexp.MustCompile(`^SQL logic error: | \(\d+\)$`)
+
+// ErrorMessage returns a SQLite error without the driver's result code text, e.g.
+// "no such table: users" rather than "SQL logic error: no such table: users (1)"
+func ErrorMessage(err error) string {
+    return errorDecoration.ReplaceAllString(err.Error(), "")
+}
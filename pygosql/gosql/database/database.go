@@ -2,25 +2,48 @@
 package database
 
 import (
+    "context"
     "database/sql"
     "fmt"
-    "log"
+    "log/slog"
     "os"
     "path/filepath"
     "regexp"
     "strings"
     "sync"
+    "sync/atomic"
+    "time"
     _ "modernc.org/sqlite"
 )
 
 // Database wraps a sql.DB connection with thread-safety and additional methods
 type Database struct {
-    DB     *sql.DB        // Underlying database connection
-    Path   string         // Database file path
-    mu     sync.RWMutex   // Read-write mutex for thread safety
-    closed bool           // Whether the database is closed
+    DB       *sql.DB        // Underlying database connection
+    Path     string         // Database file path
+    mu       sync.RWMutex   // Read-write mutex for thread safety
+    closed   bool           // Whether the database is closed
+    lockWait lockWaitStats  // Time statements spent waiting for the write lock
 }
 
+// lockWaitStats accumulates how long statements waited for the database lock
+type lockWaitStats struct {
+    count atomic.Int64 // Statements that acquired the lock
+    nanos atomic.Int64 // Total nanoseconds spent waiting
+}
+
+// Stats is a snapshot of the connection pool, lock waits and file sizes
+type Stats struct {
+    Pool         sql.DBStats   // Connection pool statistics
+    LockAcquired int64         // Statements that acquired the database lock
+    LockWait     time.Duration // Total time statements waited for the lock
+    FileSize     int64         // Size of the database file in bytes
+    WALSize      int64         // Size of the write-ahead log in bytes, 0 when absent
+}
+
+// MemoryPath opens a private in-memory database that is discarded on Close, e.g. as a
+// scratch copy of the schema
+const MemoryPath = ":memory:"
+
 // Config holds configuration options for database initialization
 type Config struct {
     Path              string // Database file path
@@ -34,13 +57,20 @@ func NewDatabase(cfg Config) (*Database, error) {
         cfg.Path = "gosql_dir/gosql.db"
     }
 
+    memory := cfg.Path == MemoryPath
+
     // Create directory if it doesn't exist
-    if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
-        return nil, fmt.Errorf("failed to create database directory: %w", err)
+    if !memory {
+        if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+            return nil, fmt.Errorf("failed to create database directory: %w", err)
+        }
     }
 
     // Open database connection with SQLite pragmas for performance
     dsn := cfg.Path + "?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=cache_size(-64000)"
+    if memory {
+        dsn = cfg.Path
+    }
     conn, err := sql.Open("sqlite", dsn)
     if err != nil {
         return nil, fmt.Errorf("failed to open database: %w", err)
@@ -48,12 +78,18 @@ func NewDatabase(cfg Config) (*Database, error) {
 
     // Enable foreign key constraints
     if _, err := conn.Exec("PRAGMA foreign_keys = ON"); err != nil {
-        log.Printf("Warning: failed to enable foreign keys: %v", err)
+        slog.Warn("failed to enable foreign keys", "error", err)
     }
 
-    // Set connection pool settings
-    conn.SetMaxOpenConns(10)
-    conn.SetMaxIdleConns(5)
+    // Set connection pool settings; every connection to :memory: is a separate
+    // database, so an in-memory database keeps a single one
+    if memory {
+        conn.SetMaxOpenConns(1)
+        conn.SetMaxIdleConns(1)
+    } else {
+        conn.SetMaxOpenConns(10)
+        conn.SetMaxIdleConns(5)
+    }
 
     // Test the connection
     if err := conn.Ping(); err != nil {
@@ -67,17 +103,14 @@ func NewDatabase(cfg Config) (*Database, error) {
     }
 
     // Apply schema if provided
-    log.Printf("[NewDatabase] Checking if schema should be applied...")
     if cfg.Schema != "" {
-        log.Printf("[NewDatabase] Schema provided, calling ApplySchema...")
         if err := db.ApplySchema(cfg.Schema); err != nil {
             conn.Close()
-            log.Printf("[NewDatabase] ERROR: Failed to apply schema: %v", err)
             return nil, fmt.Errorf("failed to apply schema: %w", err)
         }
-        log.Printf("[NewDatabase] Schema applied successfully")
-    } else {
-        log.Printf("[NewDatabase] WARNING: No schema provided (Config.Schema is empty)")
+        slog.Debug("schema applied", "database", cfg.Path)
+    } else if !memory {
+        slog.Warn("no schema provided", "database", cfg.Path)
     }
 
     return db, nil
@@ -93,53 +126,32 @@ func (d *Database) ApplySchema(schema string) error {
     }
 
     if strings.TrimSpace(schema) == "" {
-        log.Printf("[ApplySchema] WARNING: Schema is empty or whitespace only, returning early")
+        slog.Warn("schema is empty, nothing to apply")
         return nil
     }
 
-    log.Printf("[ApplySchema] Processing schema...")
-
     // Clean the schema by removing comments and empty lines
     cleanedSchema := cleanSQLSchema(schema)
-    log.Printf("[ApplySchema] Schema after cleaning comments (length: %d)", len(cleanedSchema))
-    if len(cleanedSchema) > 300 {
-        log.Printf("[ApplySchema] Cleaned schema (first 300 chars): %q", cleanedSchema[:300]+"...")
-    } else {
-        log.Printf("[ApplySchema] Cleaned schema: %q", cleanedSchema)
-    }
-
 
     // Ensure CREATE TABLE statements are idempotent
     fixedSchema := regexp.MustCompile(`(?i)CREATE\s+TABLE\s+`).ReplaceAllString(cleanedSchema, "CREATE TABLE IF NOT EXISTS ")
-    log.Printf("[ApplySchema] Schema after fixing CREATE TABLE statements (length: %d)", len(fixedSchema))
 
     // Split schema into individual statements
     statements := strings.Split(fixedSchema, ";")
-    log.Printf("[ApplySchema] Split schema into %d statements", len(statements))
 
     for i, stmt := range statements {
         stmt = strings.TrimSpace(stmt)
-        log.Printf("[ApplySchema] Processing statement %d (length: %d)", i+1, len(stmt))
-
         if stmt == "" {
-            log.Printf("[ApplySchema] Statement %d is empty, skipping", i+1)
             continue
         }
 
-        if len(stmt) > 100 {
-            log.Printf("[ApplySchema] Executing schema statement %d: %s...", i+1, stmt[:100])
-        } else {
-            log.Printf("[ApplySchema] Executing schema statement %d: %s", i+1, stmt)
-        }
-
+        slog.Debug("executing schema statement", "statement", i+1, "length", len(stmt))
         if _, err := d.DB.Exec(stmt); err != nil {
-            log.Printf("[ApplySchema] ERROR executing statement %d: %v", i+1, err)
             return fmt.Errorf("failed to execute schema statement '%s': %w", stmt, err)
         }
-        log.Printf("[ApplySchema] Successfully executed statement %d", i+1)
     }
 
-    log.Printf("[ApplySchema] All schema statements executed successfully")
+    slog.Debug("schema statements executed", "statements", len(statements))
     return nil
 }
 
@@ -178,11 +190,13 @@ func cleanSQLSchema(schema string) string {
 
 // ExecSQL executes a SQL query and returns whatever the database outputs
 func (d *Database) ExecSQL(query string, args ...interface{}) (interface{}, error) {
-    log.Printf("Database.ExecSQL called:")
-    log.Printf("   - Query: %s", query)
-    log.Printf("   - Args: %+v", args)
+    return d.ExecSQLContext(context.Background(), query, args...)
+}
 
-    d.mu.Lock()
+// ExecSQLContext is ExecSQL bound to a request context
+// The statement and its arguments are not logged since they may hold client data
+func (d *Database) ExecSQLContext(ctx context.Context, query string, args ...interface{}) (interface{}, error) {
+    d.lockTimed()
     defer d.mu.Unlock()
 
     if d.closed {
@@ -198,10 +212,9 @@ func (d *Database) ExecSQL(query string, args ...interface{}) (interface{}, erro
     // For SELECT queries, use Query() to get rows
     // For everything else, use Exec() to get result metadata
 
-    queryUpper := strings.ToUpper(strings.TrimSpace(query))
-    if strings.HasPrefix(queryUpper, "SELECT") {
+    if ReturnsRows(query) {
         // Return rows as JSON-like structure
-        rows, err := d.DB.Query(query, args...)
+        rows, err := d.DB.QueryContext(ctx, query, args...)
         if err != nil {
             return nil, err
         }
@@ -242,7 +255,7 @@ func (d *Database) ExecSQL(query string, args ...interface{}) (interface{}, erro
         return results, nil
     } else {
         // Just return what Exec() gives us
-        result, err := d.DB.Exec(query, args...)
+        result, err := d.DB.ExecContext(ctx, query, args...)
         if err != nil {
             return nil, err
         }
@@ -254,6 +267,80 @@ func (d *Database) ExecSQL(query string, args ...interface{}) (interface{}, erro
     }
 }
 
+// QueryRows runs a statement that returns rows and gives each row as a map keyed by column name
+// Unlike ExecSQL it is used for any row-returning statement, including INSERT ... RETURNING
+func (d *Database) QueryRows(query string, args ...interface{}) ([]map[string]interface{}, error) {
+    d.lockTimed()
+    defer d.mu.Unlock()
+
+    if d.closed {
+        return nil, fmt.Errorf("database is closed")
+    }
+
+    rows, err := d.DB.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    columns, err := rows.Columns()
+    if err != nil {
+        return nil, err
+    }
+
+    var results []map[string]interface{}
+    for rows.Next() {
+        values := make([]interface{}, len(columns))
+        valuePtrs := make([]interface{}, len(columns))
+        for i := range values {
+            valuePtrs[i] = &values[i]
+        }
+        if err := rows.Scan(valuePtrs...); err != nil {
+            return nil, err
+        }
+
+        row := make(map[string]interface{}, len(columns))
+        for i, val := range values {
+            if b, ok := val.([]byte); ok {
+                row[columns[i]] = string(b)
+            } else {
+                row[columns[i]] = val
+            }
+        }
+        results = append(results, row)
+    }
+    return results, rows.Err()
+}
+
+// ReturnsRows reports whether ExecSQL treats the query as a row-returning SELECT
+// Leading comments, such as a metadata header, are ignored
+func ReturnsRows(query string) bool {
+    return strings.HasPrefix(strings.ToUpper(StripLeadingComments(query)), "SELECT")
+}
+
+// StripLeadingComments removes whitespace and comments that precede the first SQL token
+func StripLeadingComments(query string) string {
+    for {
+        query = strings.TrimSpace(query)
+        switch {
+        case strings.HasPrefix(query, "--"):
+            end := strings.Index(query, "\n")
+            if end == -1 {
+                return ""
+            }
+            query = query[end+1:]
+        case strings.HasPrefix(query, "/*"):
+            end := strings.Index(query, "*/")
+            if end == -1 {
+                return ""
+            }
+            query = query[end+2:]
+        default:
+            return query
+        }
+    }
+}
+
 // Close closes the database connection and marks it as closed
 func (d *Database) Close() error {
     d.mu.Lock()
@@ -270,16 +357,69 @@ func (d *Database) Close() error {
     return nil
 }
 
+// lockTimed acquires the write lock, recording how long it waited
+func (d *Database) lockTimed() {
+    start := time.Now()
+    d.mu.Lock()
+    d.lockWait.nanos.Add(int64(time.Since(start)))
+    d.lockWait.count.Add(1)
+}
+
+// Stats returns the pool statistics, lock waits and on-disk sizes of the database
+func (d *Database) Stats() Stats {
+    stats := Stats{
+        Pool:         d.DB.Stats(),
+        LockAcquired: d.lockWait.count.Load(),
+        LockWait:     time.Duration(d.lockWait.nanos.Load()),
+    }
+    if info, err := os.Stat(d.Path); err == nil {
+        stats.FileSize = info.Size()
+    }
+    if info, err := os.Stat(d.Path + "-wal"); err == nil {
+        stats.WALSize = info.Size()
+    }
+    return stats
+}
+
 // IsHealthy checks if the database connection is still functional
 func (d *Database) IsHealthy() bool {
+    return d.Ping(context.Background()) == nil
+}
+
+// Ping verifies the database is open and a connection can be used
+func (d *Database) Ping(ctx context.Context) error {
     d.mu.RLock()
     defer d.mu.RUnlock()
 
     if d.closed || d.DB == nil {
-        return false
+        return fmt.Errorf("database is closed")
+    }
+
+    return d.DB.PingContext(ctx)
+}
+
+// HealthTable is the scratch table written by WriteProbe
+const HealthTable = "gosql_health"
+
+// WriteProbe proves the database accepts writes by updating the single row of the
+// scratch health table, creating the table on first use
+func (d *Database) WriteProbe(ctx context.Context) error {
+    d.lockTimed()
+    defer d.mu.Unlock()
+
+    if d.closed {
+        return fmt.Errorf("database is closed")
     }
 
-    return d.DB.Ping() == nil
+    if _, err := d.DB.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+HealthTable+" (id INTEGER PRIMARY KEY CHECK (id = 1), checked_at TEXT NOT NULL)"); err != nil {
+        return fmt.Errorf("failed to create %s: %w", HealthTable, err)
+    }
+    _, err := d.DB.ExecContext(ctx, "INSERT INTO "+HealthTable+" (id, checked_at) VALUES (1, ?) ON CONFLICT(id) DO UPDATE SET checked_at = excluded.checked_at",
+        time.Now().UTC().Format(time.RFC3339Nano))
+    if err != nil {
+        return fmt.Errorf("failed to write %s: %w", HealthTable, err)
+    }
+    return nil
 }
 
 // GetConnection returns the underlying sql.DB connection for advanced usage
@@ -0,0 +1,210 @@
+// introspect.go
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "strings"
+)
+
+// Column describes a table column or a column returned by a query
+type Column struct {
+    Name       string // Column name
+    Type       string // Declared SQLite type (may be empty for expressions)
+    NotNull    bool   // Whether the column has a NOT NULL constraint
+    PrimaryKey bool   // Whether the column is part of the primary key
+    HasDefault bool   // Whether the column has a DEFAULT value
+}
+
+// Tables returns the names of all user tables in the database
+func (d *Database) Tables() ([]string, error) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    if d.closed {
+        return nil, fmt.Errorf("database is closed")
+    }
+
+    rows, err := d.DB.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+    if err != nil {
+        return nil, fmt.Errorf("failed to list tables: %w", err)
+    }
+    defer rows.Close()
+
+    var tables []string
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            return nil, fmt.Errorf("failed to scan table name: %w", err)
+        }
+        tables = append(tables, name)
+    }
+    return tables, rows.Err()
+}
+
+// TableColumns returns the columns of a table using PRAGMA table_info
+func (d *Database) TableColumns(table string) ([]Column, error) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    if d.closed {
+        return nil, fmt.Errorf("database is closed")
+    }
+
+    rows, err := d.DB.Query(fmt.Sprintf("PRAGMA table_info(%s)", quoteIdentifier(table)))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+    }
+    defer rows.Close()
+
+    var columns []Column
+    for rows.Next() {
+        var (
+            cid          int
+            name         string
+            colType      string
+            notNull      int
+            defaultValue sql.NullString
+            pk           int
+        )
+        if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+            return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+        }
+        columns = append(columns, Column{
+            Name:       name,
+            Type:       colType,
+            NotNull:    notNull != 0,
+            PrimaryKey: pk != 0,
+            HasDefault: defaultValue.Valid,
+        })
+    }
+    return columns, rows.Err()
+}
+
+// ForeignKey is a single-column reference from one table to another
+type ForeignKey struct {
+    Column    string // Referencing column in the table
+    RefTable  string // Referenced table
+    RefColumn string // Referenced column, the primary key when not named in the schema
+}
+
+// ForeignKeys returns the single-column foreign keys of a table using PRAGMA foreign_key_list
+// Composite keys are skipped
+func (d *Database) ForeignKeys(table string) ([]ForeignKey, error) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    if d.closed {
+        return nil, fmt.Errorf("database is closed")
+    }
+
+    rows, err := d.DB.Query(fmt.Sprintf("PRAGMA foreign_key_list(%s)", quoteIdentifier(table)))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read foreign keys of %s: %w", table, err)
+    }
+    defer rows.Close()
+
+    var keys []ForeignKey
+    counts := make(map[int]int)
+    ids := make([]int, 0)
+    for rows.Next() {
+        var (
+            id, seq                  int
+            refTable, from           string
+            to                       sql.NullString
+            onUpdate, onDelete, match string
+        )
+        if err := rows.Scan(&id, &seq, &refTable, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+            return nil, fmt.Errorf("failed to scan foreign key of %s: %w", table, err)
+        }
+        counts[id]++
+        ids = append(ids, id)
+        keys = append(keys, ForeignKey{Column: from, RefTable: refTable, RefColumn: to.String})
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    var single []ForeignKey
+    for i, key := range keys {
+        if counts[ids[i]] == 1 {
+            single = append(single, key)
+        }
+    }
+    return single, nil
+}
+
+// QueryColumns returns the result columns of a query without keeping its effects
+// The statement runs inside a transaction that is always rolled back, with every
+// bind parameter set to NULL
+func (d *Database) QueryColumns(query string, placeholders Placeholders) ([]Column, error) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    if d.closed {
+        return nil, fmt.Errorf("database is closed")
+    }
+
+    tx, err := d.DB.Begin()
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    args := make([]interface{}, 0, placeholders.Positional+len(placeholders.Named))
+    for i := 0; i < placeholders.Positional; i++ {
+        args = append(args, nil)
+    }
+    for _, name := range placeholders.Named {
+        args = append(args, sql.Named(name, nil))
+    }
+
+    rows, err := tx.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    types, err := rows.ColumnTypes()
+    if err != nil {
+        return nil, fmt.Errorf("failed to read column types: %w", err)
+    }
+
+    columns := make([]Column, len(types))
+    for i, t := range types {
+        nullable, ok := t.Nullable()
+        columns[i] = Column{
+            Name:    t.Name(),
+            Type:    t.DatabaseTypeName(),
+            NotNull: ok && !nullable,
+        }
+    }
+    return columns, nil
+}
+
+// JSONType maps a declared SQLite column type to a JSON schema type using SQLite affinity rules
+// Returns an empty string when the type carries no affinity information
+func JSONType(sqliteType string) string {
+    t := strings.ToUpper(sqliteType)
+    switch {
+    case t == "":
+        return ""
+    case strings.Contains(t, "BOOL"):
+        return "boolean"
+    case strings.Contains(t, "INT"):
+        return "integer"
+    case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"),
+        strings.Contains(t, "BLOB"), strings.Contains(t, "DATE"), strings.Contains(t, "TIME"):
+        return "string"
+    case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"),
+        strings.Contains(t, "NUMERIC"), strings.Contains(t, "DECIMAL"):
+        return "number"
+    default:
+        return ""
+    }
+}
+
+// quoteIdentifier quotes a table or column name for use in SQL
+func quoteIdentifier(name string) string {
+    return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
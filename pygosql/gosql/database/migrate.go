@@ -0,0 +1,203 @@
+// migrate.go
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strconv"
+    "time"
+)
+
+// MigrationsDir is the directory below the SQL root holding migration files; it is
+// not scanned for endpoints
+const MigrationsDir = "migrations"
+
+// MigrationsTable records the migrations applied to the database
+const MigrationsTable = "gosql_migrations"
+
+// Migration is a numbered schema change read from the migrations directory
+// Files are named <version>_<name>.up.sql with an optional matching .down.sql;
+// a plain <version>_<name>.sql is an up migration that cannot be reverted
+type Migration struct {
+    Version  int64  // Number from the file name prefix, applied in ascending order
+    Name     string // Rest of the file name, e.g. "create_users"
+    UpPath   string // SQL file applying the change
+    DownPath string // SQL file reverting the change, empty when irreversible
+}
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+?)(\.up|\.down)?\.sql$`)
+
+// LoadMigrations lists the migrations in dir sorted by version
+// A missing directory has no migrations
+func LoadMigrations(dir string) ([]Migration, error) {
+    entries, err := os.ReadDir(dir)
+    if os.IsNotExist(err) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to read migrations: %w", err)
+    }
+
+    byVersion := make(map[int64]*Migration)
+    for _, entry := range entries {
+        if entry.IsDir() {
+            continue
+        }
+        match := migrationName.FindStringSubmatch(entry.Name())
+        if match == nil {
+            return nil, fmt.Errorf("migration %s: name must look like 0001_create_users.up.sql", entry.Name())
+        }
+        version, err := strconv.ParseInt(match[1], 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+        }
+
+        migration := byVersion[version]
+        if migration == nil {
+            migration = &Migration{Version: version, Name: match[2]}
+            byVersion[version] = migration
+        } else if migration.Name != match[2] {
+            return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, migration.Name, match[2])
+        }
+
+        path := filepath.Join(dir, entry.Name())
+        target := &migration.UpPath
+        if match[3] == ".down" {
+            target = &migration.DownPath
+        }
+        if *target != "" {
+            return nil, fmt.Errorf("migration version %d has both %s and %s", version, filepath.Base(*target), entry.Name())
+        }
+        *target = path
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, migration := range byVersion {
+        if migration.UpPath == "" {
+            return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+        }
+        migrations = append(migrations, *migration)
+    }
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+    return migrations, nil
+}
+
+// AppliedMigrations returns the versions recorded in the migrations table
+// A database without the table has none applied
+func (d *Database) AppliedMigrations() (map[int64]bool, error) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    if d.closed {
+        return nil, fmt.Errorf("database is closed")
+    }
+
+    applied := make(map[int64]bool)
+    var exists int
+    if err := d.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", MigrationsTable).Scan(&exists); err != nil {
+        return nil, fmt.Errorf("failed to look up %s: %w", MigrationsTable, err)
+    }
+    if exists == 0 {
+        return applied, nil
+    }
+
+    rows, err := d.DB.Query("SELECT version FROM " + MigrationsTable)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read %s: %w", MigrationsTable, err)
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var version int64
+        if err := rows.Scan(&version); err != nil {
+            return nil, fmt.Errorf("failed to scan migration version: %w", err)
+        }
+        applied[version] = true
+    }
+    return applied, rows.Err()
+}
+
+// PendingMigrations returns the migrations not yet applied, in order
+func (d *Database) PendingMigrations(migrations []Migration) ([]Migration, error) {
+    applied, err := d.AppliedMigrations()
+    if err != nil {
+        return nil, err
+    }
+    var pending []Migration
+    for _, migration := range migrations {
+        if !applied[migration.Version] {
+            pending = append(pending, migration)
+        }
+    }
+    return pending, nil
+}
+
+// String returns the migration's file name stem, e.g. "3_add_posts"
+func (m Migration) String() string {
+    return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// ApplyMigration runs the migration's up file and records it, in one transaction
+func (d *Database) ApplyMigration(migration Migration) error {
+    content, err := os.ReadFile(migration.UpPath)
+    if err != nil {
+        return fmt.Errorf("migration %s: %w", migration, err)
+    }
+
+    return d.migrate(migration, func(tx *sql.Tx) error {
+        if _, err := tx.Exec("CREATE TABLE IF NOT EXISTS " + MigrationsTable + " (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at TEXT NOT NULL)"); err != nil {
+            return fmt.Errorf("failed to create %s: %w", MigrationsTable, err)
+        }
+        if _, err := tx.Exec(string(content)); err != nil {
+            return err
+        }
+        _, err := tx.Exec("INSERT INTO "+MigrationsTable+" (version, name, applied_at) VALUES (?, ?, ?)",
+            migration.Version, migration.Name, time.Now().UTC().Format(time.RFC3339))
+        return err
+    })
+}
+
+// RevertMigration runs the migration's down file and forgets it, in one transaction
+func (d *Database) RevertMigration(migration Migration) error {
+    if migration.DownPath == "" {
+        return fmt.Errorf("migration %s has no down file and cannot be reverted", migration)
+    }
+    content, err := os.ReadFile(migration.DownPath)
+    if err != nil {
+        return fmt.Errorf("migration %s: %w", migration, err)
+    }
+
+    return d.migrate(migration, func(tx *sql.Tx) error {
+        if _, err := tx.Exec(string(content)); err != nil {
+            return err
+        }
+        _, err := tx.Exec("DELETE FROM "+MigrationsTable+" WHERE version = ?", migration.Version)
+        return err
+    })
+}
+
+// migrate runs step in a transaction holding the database write lock
+func (d *Database) migrate(migration Migration, step func(tx *sql.Tx) error) error {
+    d.lockTimed()
+    defer d.mu.Unlock()
+
+    if d.closed {
+        return fmt.Errorf("database is closed")
+    }
+
+    tx, err := d.DB.Begin()
+    if err != nil {
+        return fmt.Errorf("migration %s: %w", migration, err)
+    }
+    if err := step(tx); err != nil {
+        tx.Rollback()
+        return fmt.Errorf("migration %s: %w", migration, err)
+    }
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("migration %s: %w", migration, err)
+    }
+    return nil
+}
@@ -0,0 +1,216 @@
+// sql_meta.go
+package database
+
+import (
+    "strings"
+)
+
+// Metadata holds the annotations declared in the leading comment block of a SQL file
+//
+//  -- @description Fetch users with the given role
+//  -- @param role string required Role to filter by
+//  -- @param limit integer Maximum number of rows
+type Metadata struct {
+    Description string              // Human readable description of the endpoint
+    Params      []Param             // Parameters declared with @param
+    Tags        map[string][]string // Any other @key values in declaration order
+}
+
+// Param describes a request parameter accepted by a SQL file
+type Param struct {
+    Name        string // Parameter name as sent by the client
+    Type        string // JSON type: string, integer, number, boolean (empty if unknown)
+    Required    bool   // Whether the client must provide the parameter
+    Nullable    bool   // Whether null is accepted as a value
+    Description string // Optional human readable description
+    In          string // Where the parameter is read from: path, query or body
+    Declared    bool   // Whether the parameter was declared with @param rather than inferred
+}
+
+// Placeholders summarizes the parameters referenced inside a SQL statement
+type Placeholders struct {
+    Named      []string // :name, @name and $name bind parameters in order of first appearance
+    Positional int      // Number of ? bind parameters
+    Templates  []string // {{variable}} template names in order of first appearance
+}
+
+// ParamTypes lists the parameter types accepted in @param declarations
+var ParamTypes = []string{"string", "integer", "number", "boolean"}
+
+// ParseMetadata extracts @key annotations from the comment lines at the top of a SQL file
+func ParseMetadata(content string) Metadata {
+    meta := Metadata{Tags: make(map[string][]string)}
+
+    for _, line := range strings.Split(content, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" {
+            continue
+        }
+
+        // The header ends at the first statement line
+        if !strings.HasPrefix(trimmed, "--") {
+            break
+        }
+
+        body := strings.TrimSpace(strings.TrimPrefix(trimmed, "--"))
+        if !strings.HasPrefix(body, "@") {
+            continue
+        }
+
+        key, value, _ := strings.Cut(body[1:], " ")
+        key = strings.ToLower(key)
+        value = strings.TrimSpace(value)
+
+        switch key {
+        case "description":
+            if meta.Description != "" {
+                meta.Description += " "
+            }
+            meta.Description += value
+        case "param":
+            if param, ok := parseParamDecl(value); ok {
+                meta.Params = append(meta.Params, param)
+            }
+        default:
+            meta.Tags[key] = append(meta.Tags[key], value)
+        }
+    }
+
+    return meta
+}
+
+// Tag returns the first value declared for an @key annotation, or an empty string
+func (m Metadata) Tag(key string) string {
+    if values := m.Tags[strings.ToLower(key)]; len(values) > 0 {
+        return values[0]
+    }
+    return ""
+}
+
+// parseParamDecl parses "<name> [type] [required|optional] [nullable] [description...]"
+func parseParamDecl(decl string) (Param, bool) {
+    fields := strings.Fields(decl)
+    if len(fields) == 0 {
+        return Param{}, false
+    }
+
+    param := Param{Name: strings.TrimLeft(fields[0], ":@$"), Declared: true}
+    rest := fields[1:]
+
+    if len(rest) > 0 && isParamType(strings.ToLower(rest[0])) {
+        param.Type = strings.ToLower(rest[0])
+        rest = rest[1:]
+    }
+
+    if len(rest) > 0 {
+        switch strings.ToLower(rest[0]) {
+        case "required":
+            param.Required = true
+            rest = rest[1:]
+        case "optional":
+            rest = rest[1:]
+        }
+    }
+    if len(rest) > 0 && strings.ToLower(rest[0]) == "nullable" {
+        param.Nullable = true
+        rest = rest[1:]
+    }
+
+    param.Description = strings.Join(rest, " ")
+    return param, param.Name != ""
+}
+
+// isParamType reports whether t is one of the supported @param types
+func isParamType(t string) bool {
+    for _, known := range ParamTypes {
+        if t == known {
+            return true
+        }
+    }
+    return false
+}
+
+// FindPlaceholders scans a SQL statement for bind parameters and template variables,
+// skipping string literals, quoted identifiers and comments
+func FindPlaceholders(content string) Placeholders {
+    var found Placeholders
+    seenNamed := make(map[string]bool)
+    seenTemplates := make(map[string]bool)
+
+    for i := 0; i < len(content); i++ {
+        c := content[i]
+        switch {
+        case c == '\'' || c == '"' || c == '`':
+            // Skip quoted text, doubled quotes are escapes
+            for i++; i < len(content); i++ {
+                if content[i] == c {
+                    if i+1 < len(content) && content[i+1] == c {
+                        i++
+                        continue
+                    }
+                    break
+                }
+            }
+        case c == '-' && i+1 < len(content) && content[i+1] == '-':
+            for i < len(content) && content[i] != '\n' {
+                i++
+            }
+        case c == '/' && i+1 < len(content) && content[i+1] == '*':
+            end := strings.Index(content[i+2:], "*/")
+            if end == -1 {
+                return found
+            }
+            i += end + 3
+        case c == '{' && strings.HasPrefix(content[i:], "{{"):
+            end := strings.Index(content[i+2:], "}}")
+            if end == -1 {
+                continue
+            }
+            name := content[i+2 : i+2+end]
+            if isIdentifier(name) && !seenTemplates[name] {
+                seenTemplates[name] = true
+                found.Templates = append(found.Templates, name)
+            }
+            i += end + 3
+        case c == '?':
+            found.Positional++
+            for i+1 < len(content) && isDigit(content[i+1]) {
+                i++
+            }
+        case c == ':' || c == '@' || c == '$':
+            j := i + 1
+            for j < len(content) && isIdentByte(content[j]) {
+                j++
+            }
+            name := content[i+1 : j]
+            if isIdentifier(name) && !seenNamed[name] {
+                seenNamed[name] = true
+                found.Named = append(found.Named, name)
+            }
+            i = j - 1
+        }
+    }
+
+    return found
+}
+
+// isIdentifier reports whether s is a valid parameter name (letter or underscore first)
+func isIdentifier(s string) bool {
+    if s == "" || isDigit(s[0]) {
+        return false
+    }
+    for i := 0; i < len(s); i++ {
+        if !isIdentByte(s[i]) {
+            return false
+        }
+    }
+    return true
+}
+
+func isIdentByte(c byte) bool {
+    return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+    return c >= '0' && c <= '9'
+}
@@ -0,0 +1,120 @@
+// ast.go
+package graphql
+
+// Document is a parsed GraphQL request document
+type Document struct {
+    Operations []*Operation          // Operation definitions in document order
+    Fragments  map[string]*Fragment  // Fragment definitions by name
+}
+
+// Operation is a query or mutation definition
+type Operation struct {
+    Type         string         // "query" or "mutation"
+    Name         string         // Optional operation name
+    Variables    []*VariableDef // Declared variables
+    SelectionSet []Selection    // Top level selections
+}
+
+// VariableDef declares an operation variable
+type VariableDef struct {
+    Name         string    // Variable name without $
+    Type         *TypeRef  // Declared type
+    DefaultValue Value     // Default value, nil when absent
+}
+
+// Fragment is a named fragment definition
+type Fragment struct {
+    Name          string      // Fragment name
+    TypeCondition string      // Type the fragment applies to
+    SelectionSet  []Selection // Fragment selections
+}
+
+// Selection is a Field, FragmentSpread or InlineFragment
+type Selection interface {
+    selection()
+}
+
+// Field selects a field, optionally with an alias, arguments and sub-selections
+type Field struct {
+    Alias        string           // Response key override, empty when absent
+    Name         string           // Field name
+    Arguments    map[string]Value // Arguments by name
+    Directives   []*Directive     // Directives such as @skip and @include
+    SelectionSet []Selection      // Sub-selections for object fields
+}
+
+// FragmentSpread references a named fragment with ...Name
+type FragmentSpread struct {
+    Name       string       // Fragment name
+    Directives []*Directive // Directives on the spread
+}
+
+// InlineFragment is an anonymous fragment with an optional type condition
+type InlineFragment struct {
+    TypeCondition string       // Type condition, empty when absent
+    Directives    []*Directive // Directives on the fragment
+    SelectionSet  []Selection  // Fragment selections
+}
+
+// Directive is a @name(args) annotation
+type Directive struct {
+    Name      string           // Directive name without @
+    Arguments map[string]Value // Arguments by name
+}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// ResponseKey returns the alias of a field, or its name when no alias is set
+func (f *Field) ResponseKey() string {
+    if f.Alias != "" {
+        return f.Alias
+    }
+    return f.Name
+}
+
+// Value is a literal or variable in a GraphQL document
+type Value interface {
+    value()
+}
+
+// Variable references an operation variable
+type Variable struct{ Name string }
+
+// IntValue is an integer literal
+type IntValue struct{ Value int64 }
+
+// FloatValue is a floating point literal
+type FloatValue struct{ Value float64 }
+
+// StringValue is a string literal
+type StringValue struct{ Value string }
+
+// BooleanValue is a boolean literal
+type BooleanValue struct{ Value bool }
+
+// NullValue is the null literal
+type NullValue struct{}
+
+// EnumValue is an enum literal
+type EnumValue struct{ Value string }
+
+// ListValue is a list literal
+type ListValue struct{ Values []Value }
+
+// ObjectValue is an input object literal, fields kept in source order
+type ObjectValue struct {
+    Names  []string
+    Values map[string]Value
+}
+
+func (*Variable) value()     {}
+func (*IntValue) value()     {}
+func (*FloatValue) value()   {}
+func (*StringValue) value()  {}
+func (*BooleanValue) value() {}
+func (*NullValue) value()    {}
+func (*EnumValue) value()    {}
+func (*ListValue) value()    {}
+func (*ObjectValue) value()  {}
@@ -0,0 +1,312 @@
+// coerce.go
+package graphql
+
+import (
+    "fmt"
+    "math"
+)
+
+// coerceVariables validates request variables against the operation's declarations
+func (e *executor) coerceVariables(defs []*VariableDef, input map[string]interface{}) (map[string]interface{}, error) {
+    values := make(map[string]interface{})
+    for _, def := range defs {
+        if _, ok := e.schema.Types[def.Type.NamedType()]; !ok {
+            return nil, fmt.Errorf("Unknown type %q for variable $%s", def.Type.NamedType(), def.Name)
+        }
+        raw, provided := input[def.Name]
+        switch {
+        case provided:
+            value, err := e.coerceInput(raw, def.Type)
+            if err != nil {
+                return nil, fmt.Errorf("Variable $%s: %v", def.Name, err)
+            }
+            values[def.Name] = value
+        case def.DefaultValue != nil:
+            value, err := e.valueFromAST(def.DefaultValue, def.Type)
+            if err != nil {
+                return nil, fmt.Errorf("Variable $%s: %v", def.Name, err)
+            }
+            values[def.Name] = value
+        case def.Type.Kind == KindNonNull:
+            return nil, fmt.Errorf("Variable $%s of required type %s was not provided", def.Name, def.Type)
+        }
+    }
+    return values, nil
+}
+
+// coerceArguments validates field arguments and applies defaults
+func (e *executor) coerceArguments(defs []*InputValue, args map[string]Value) (map[string]interface{}, error) {
+    for name := range args {
+        if findInputValue(defs, name) == nil {
+            return nil, fmt.Errorf("Unknown argument %q", name)
+        }
+    }
+
+    values := make(map[string]interface{})
+    for _, def := range defs {
+        literal, ok := args[def.Name]
+        if variable, isVariable := literal.(*Variable); ok && isVariable {
+            if _, set := e.variables[variable.Name]; !set {
+                ok = false
+            }
+        }
+        switch {
+        case ok:
+            value, err := e.valueFromAST(literal, def.Type)
+            if err != nil {
+                return nil, fmt.Errorf("Argument %q: %v", def.Name, err)
+            }
+            values[def.Name] = value
+        case def.DefaultValue != nil:
+            values[def.Name] = defaultValue(def.DefaultValue)
+        case def.Type.Kind == KindNonNull:
+            return nil, fmt.Errorf("Argument %q of required type %s was not provided", def.Name, def.Type)
+        }
+    }
+    return values, nil
+}
+
+// findInputValue returns the argument or input field with the given name, or nil
+func findInputValue(defs []*InputValue, name string) *InputValue {
+    for _, def := range defs {
+        if def.Name == name {
+            return def
+        }
+    }
+    return nil
+}
+
+// defaultValue converts a schema default into its coerced form
+func defaultValue(value interface{}) interface{} {
+    switch v := value.(type) {
+    case enumLiteral:
+        return string(v)
+    case []interface{}:
+        result := make([]interface{}, len(v))
+        for i, item := range v {
+            result[i] = defaultValue(item)
+        }
+        return result
+    }
+    return value
+}
+
+// valueFromAST coerces a literal from the document to the given input type
+func (e *executor) valueFromAST(literal Value, ref *TypeRef) (interface{}, error) {
+    if variable, ok := literal.(*Variable); ok {
+        value := e.variables[variable.Name]
+        if value == nil && ref.Kind == KindNonNull {
+            return nil, fmt.Errorf("Expected non-null value of type %s", ref)
+        }
+        return value, nil
+    }
+
+    if ref.Kind == KindNonNull {
+        if _, isNull := literal.(*NullValue); isNull {
+            return nil, fmt.Errorf("Expected non-null value of type %s", ref)
+        }
+        return e.valueFromAST(literal, ref.OfType)
+    }
+    if _, isNull := literal.(*NullValue); isNull {
+        return nil, nil
+    }
+
+    if ref.Kind == KindList {
+        list, ok := literal.(*ListValue)
+        if !ok {
+            item, err := e.valueFromAST(literal, ref.OfType)
+            if err != nil {
+                return nil, err
+            }
+            return []interface{}{item}, nil
+        }
+        result := make([]interface{}, len(list.Values))
+        for i, item := range list.Values {
+            value, err := e.valueFromAST(item, ref.OfType)
+            if err != nil {
+                return nil, err
+            }
+            result[i] = value
+        }
+        return result, nil
+    }
+
+    t := e.schema.Types[ref.Name]
+    switch t.Kind {
+    case InputObject:
+        object, ok := literal.(*ObjectValue)
+        if !ok {
+            return nil, fmt.Errorf("Expected an object of type %s", t.Name)
+        }
+        result := make(map[string]interface{})
+        for _, name := range object.Names {
+            if findInputValue(t.InputFields, name) == nil {
+                return nil, fmt.Errorf("Field %q is not defined by type %s", name, t.Name)
+            }
+        }
+        for _, field := range t.InputFields {
+            fieldLiteral, ok := object.Values[field.Name]
+            if variable, isVariable := fieldLiteral.(*Variable); ok && isVariable {
+                if _, set := e.variables[variable.Name]; !set {
+                    ok = false
+                }
+            }
+            switch {
+            case ok:
+                value, err := e.valueFromAST(fieldLiteral, field.Type)
+                if err != nil {
+                    return nil, fmt.Errorf("%s.%s: %v", t.Name, field.Name, err)
+                }
+                result[field.Name] = value
+            case field.DefaultValue != nil:
+                result[field.Name] = defaultValue(field.DefaultValue)
+            case field.Type.Kind == KindNonNull:
+                return nil, fmt.Errorf("Field %s.%s of required type %s was not provided", t.Name, field.Name, field.Type)
+            }
+        }
+        return result, nil
+    case Enum:
+        enum, ok := literal.(*EnumValue)
+        if !ok || !hasEnumValue(t, enum.Value) {
+            return nil, fmt.Errorf("Expected a value of enum %s", t.Name)
+        }
+        return enum.Value, nil
+    }
+
+    switch v := literal.(type) {
+    case *IntValue:
+        return coerceScalar(t.Name, v.Value)
+    case *FloatValue:
+        return coerceScalar(t.Name, v.Value)
+    case *StringValue:
+        return coerceScalar(t.Name, v.Value)
+    case *BooleanValue:
+        return coerceScalar(t.Name, v.Value)
+    }
+    return nil, fmt.Errorf("Expected a value of type %s", t.Name)
+}
+
+// coerceInput coerces a JSON variable value to the given input type
+func (e *executor) coerceInput(value interface{}, ref *TypeRef) (interface{}, error) {
+    if ref.Kind == KindNonNull {
+        if value == nil {
+            return nil, fmt.Errorf("Expected non-null value of type %s", ref)
+        }
+        return e.coerceInput(value, ref.OfType)
+    }
+    if value == nil {
+        return nil, nil
+    }
+
+    if ref.Kind == KindList {
+        list, ok := value.([]interface{})
+        if !ok {
+            item, err := e.coerceInput(value, ref.OfType)
+            if err != nil {
+                return nil, err
+            }
+            return []interface{}{item}, nil
+        }
+        result := make([]interface{}, len(list))
+        for i, item := range list {
+            coerced, err := e.coerceInput(item, ref.OfType)
+            if err != nil {
+                return nil, err
+            }
+            result[i] = coerced
+        }
+        return result, nil
+    }
+
+    t := e.schema.Types[ref.Name]
+    switch t.Kind {
+    case InputObject:
+        object, ok := value.(map[string]interface{})
+        if !ok {
+            return nil, fmt.Errorf("Expected an object of type %s", t.Name)
+        }
+        for name := range object {
+            if findInputValue(t.InputFields, name) == nil {
+                return nil, fmt.Errorf("Field %q is not defined by type %s", name, t.Name)
+            }
+        }
+        result := make(map[string]interface{})
+        for _, field := range t.InputFields {
+            fieldValue, ok := object[field.Name]
+            switch {
+            case ok:
+                coerced, err := e.coerceInput(fieldValue, field.Type)
+                if err != nil {
+                    return nil, fmt.Errorf("%s.%s: %v", t.Name, field.Name, err)
+                }
+                result[field.Name] = coerced
+            case field.DefaultValue != nil:
+                result[field.Name] = defaultValue(field.DefaultValue)
+            case field.Type.Kind == KindNonNull:
+                return nil, fmt.Errorf("Field %s.%s of required type %s was not provided", t.Name, field.Name, field.Type)
+            }
+        }
+        return result, nil
+    case Enum:
+        name, ok := value.(string)
+        if !ok || !hasEnumValue(t, name) {
+            return nil, fmt.Errorf("Expected a value of enum %s", t.Name)
+        }
+        return name, nil
+    }
+    return coerceScalar(t.Name, value)
+}
+
+// hasEnumValue reports whether the enum defines the value
+func hasEnumValue(t *Type, name string) bool {
+    for _, value := range t.EnumValues {
+        if value == name {
+            return true
+        }
+    }
+    return false
+}
+
+// coerceScalar converts an input value to a built-in scalar's Go representation
+func coerceScalar(name string, value interface{}) (interface{}, error) {
+    switch name {
+    case "Int":
+        switch v := value.(type) {
+        case int64:
+            return v, nil
+        case float64:
+            if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+                return int64(v), nil
+            }
+        }
+    case "Float":
+        switch v := value.(type) {
+        case int64:
+            return float64(v), nil
+        case float64:
+            return v, nil
+        }
+    case "String":
+        if v, ok := value.(string); ok {
+            return v, nil
+        }
+    case "Boolean":
+        if v, ok := value.(bool); ok {
+            return v, nil
+        }
+    case "ID":
+        switch v := value.(type) {
+        case string:
+            return v, nil
+        case int64:
+            return fmt.Sprint(v), nil
+        case float64:
+            if v == math.Trunc(v) {
+                return fmt.Sprint(int64(v)), nil
+            }
+        }
+    default:
+        return value, nil
+    }
+    return nil, fmt.Errorf("%s cannot represent value %v", name, value)
+}
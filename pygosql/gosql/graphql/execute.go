@@ -0,0 +1,533 @@
+// execute.go
+package graphql
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "math"
+    "strconv"
+    "time"
+)
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+    Query         string                 `json:"query"`
+    OperationName string                 `json:"operationName,omitempty"`
+    Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of executing a request
+type Response struct {
+    Data   interface{} `json:"data,omitempty"` // Absent when the request failed before execution
+    Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Location is a line and column in the request document
+type Location struct {
+    Line   int `json:"line"`
+    Column int `json:"column"`
+}
+
+// Error is a GraphQL error with optional source locations and response path
+type Error struct {
+    Message   string        `json:"message"`
+    Locations []Location    `json:"locations,omitempty"`
+    Path      []interface{} `json:"path,omitempty"`
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+    return e.Message
+}
+
+// orderedObject is a response object that keeps fields in selection order
+type orderedObject struct {
+    keys   []string
+    values map[string]interface{}
+}
+
+// set stores a field value, keeping the position of the first occurrence
+func (o *orderedObject) set(key string, value interface{}) {
+    if _, exists := o.values[key]; !exists {
+        o.keys = append(o.keys, key)
+    }
+    o.values[key] = value
+}
+
+// MarshalJSON encodes the object with fields in selection order
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+    var buf bytes.Buffer
+    buf.WriteByte('{')
+    for i, key := range o.keys {
+        if i > 0 {
+            buf.WriteByte(',')
+        }
+        name, _ := json.Marshal(key)
+        buf.Write(name)
+        buf.WriteByte(':')
+        value, err := json.Marshal(o.values[key])
+        if err != nil {
+            return nil, err
+        }
+        buf.Write(value)
+    }
+    buf.WriteByte('}')
+    return buf.Bytes(), nil
+}
+
+// executor holds the state of a single request execution
+type executor struct {
+    ctx       context.Context
+    schema    *Schema
+    doc       *Document
+    variables map[string]interface{}
+    errors    []*Error
+}
+
+// fieldError is a resolver or completion error that nulls the nearest nullable field
+type fieldError struct {
+    message string
+    path    []interface{}
+}
+
+// Execute parses and executes a request against the schema
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+    doc, err := Parse(req.Query)
+    if err != nil {
+        return &Response{Errors: []*Error{asError(err)}}
+    }
+
+    op, err := selectOperation(doc, req.OperationName)
+    if err != nil {
+        return &Response{Errors: []*Error{asError(err)}}
+    }
+
+    var root *Type
+    switch op.Type {
+    case "query":
+        root = s.Types[s.Query]
+    case "mutation":
+        if s.Mutation == "" {
+            return &Response{Errors: []*Error{{Message: "Schema does not support mutations"}}}
+        }
+        root = s.Types[s.Mutation]
+    default:
+        return &Response{Errors: []*Error{{Message: "Subscriptions are not supported"}}}
+    }
+
+    e := &executor{ctx: ctx, schema: s, doc: doc}
+    if e.variables, err = e.coerceVariables(op.Variables, req.Variables); err != nil {
+        return &Response{Errors: []*Error{asError(err)}}
+    }
+
+    objects, ferr := e.executeSelectionSet(root, []interface{}{nil}, op.SelectionSet, nil)
+    if ferr != nil {
+        e.addError(ferr)
+        return &Response{Data: json.RawMessage("null"), Errors: e.errors}
+    }
+    return &Response{Data: objects[0], Errors: e.errors}
+}
+
+// selectOperation picks the operation to run by name
+func selectOperation(doc *Document, name string) (*Operation, error) {
+    if name == "" {
+        if len(doc.Operations) > 1 {
+            return nil, &Error{Message: "Must provide operation name if query contains multiple operations"}
+        }
+        return doc.Operations[0], nil
+    }
+    for _, op := range doc.Operations {
+        if op.Name == name {
+            return op, nil
+        }
+    }
+    return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q", name)}
+}
+
+// asError converts any error into a GraphQL error
+func asError(err error) *Error {
+    if gqlErr, ok := err.(*Error); ok {
+        return gqlErr
+    }
+    return &Error{Message: err.Error()}
+}
+
+// addError records a field error in the response
+func (e *executor) addError(ferr *fieldError) {
+    e.errors = append(e.errors, &Error{Message: ferr.message, Path: ferr.path})
+}
+
+// fieldGroup is a response key with all fields merged into it
+type fieldGroup struct {
+    key    string
+    fields []*Field
+}
+
+// collectFields flattens fragments and applies @skip/@include for an object type
+func (e *executor) collectFields(t *Type, selections []Selection, groups []*fieldGroup, visited map[string]bool) ([]*fieldGroup, error) {
+    for _, selection := range selections {
+        switch sel := selection.(type) {
+        case *Field:
+            include, err := e.shouldInclude(sel.Directives)
+            if err != nil {
+                return nil, err
+            }
+            if !include {
+                continue
+            }
+            key := sel.ResponseKey()
+            merged := false
+            for _, group := range groups {
+                if group.key == key {
+                    group.fields = append(group.fields, sel)
+                    merged = true
+                    break
+                }
+            }
+            if !merged {
+                groups = append(groups, &fieldGroup{key: key, fields: []*Field{sel}})
+            }
+        case *FragmentSpread:
+            include, err := e.shouldInclude(sel.Directives)
+            if err != nil {
+                return nil, err
+            }
+            if !include || visited[sel.Name] {
+                continue
+            }
+            visited[sel.Name] = true
+            fragment, ok := e.doc.Fragments[sel.Name]
+            if !ok {
+                return nil, fmt.Errorf("Unknown fragment %q", sel.Name)
+            }
+            if fragment.TypeCondition != t.Name {
+                continue
+            }
+            if groups, err = e.collectFields(t, fragment.SelectionSet, groups, visited); err != nil {
+                return nil, err
+            }
+        case *InlineFragment:
+            include, err := e.shouldInclude(sel.Directives)
+            if err != nil {
+                return nil, err
+            }
+            if !include || (sel.TypeCondition != "" && sel.TypeCondition != t.Name) {
+                continue
+            }
+            if groups, err = e.collectFields(t, sel.SelectionSet, groups, visited); err != nil {
+                return nil, err
+            }
+        }
+    }
+    return groups, nil
+}
+
+// shouldInclude evaluates @skip and @include directives
+func (e *executor) shouldInclude(directives []*Directive) (bool, error) {
+    for _, directive := range directives {
+        if directive.Name != "skip" && directive.Name != "include" {
+            continue
+        }
+        value, ok := directive.Arguments["if"]
+        if !ok {
+            return false, fmt.Errorf("Directive @%s requires an \"if\" argument", directive.Name)
+        }
+        condition, err := e.valueFromAST(value, NonNullOf(Named("Boolean")))
+        if err != nil {
+            return false, err
+        }
+        if condition.(bool) == (directive.Name == "skip") {
+            return false, nil
+        }
+    }
+    return true, nil
+}
+
+// executeSelectionSet resolves the selections of an object type for a batch of parent values
+func (e *executor) executeSelectionSet(t *Type, parents []interface{}, selections []Selection, path []interface{}) ([]*orderedObject, *fieldError) {
+    groups, err := e.collectFields(t, selections, nil, make(map[string]bool))
+    if err != nil {
+        return nil, &fieldError{message: err.Error(), path: path}
+    }
+
+    objects := make([]*orderedObject, len(parents))
+    for i := range objects {
+        objects[i] = &orderedObject{values: make(map[string]interface{})}
+    }
+
+    // Fields run one after another, which also gives mutations their required serial order
+    for _, group := range groups {
+        field := group.fields[0]
+        fieldPath := appendPath(path, group.key)
+
+        values, ferr := e.resolveField(t, field, group, parents, fieldPath)
+        if ferr != nil {
+            return nil, ferr
+        }
+        for i, object := range objects {
+            object.set(group.key, values[i])
+        }
+    }
+    return objects, nil
+}
+
+// resolveField resolves and completes one response key for every parent
+func (e *executor) resolveField(t *Type, field *Field, group *fieldGroup, parents []interface{}, path []interface{}) ([]interface{}, *fieldError) {
+    values := make([]interface{}, len(parents))
+    if field.Name == "__typename" {
+        for i := range values {
+            values[i] = t.Name
+        }
+        return values, nil
+    }
+
+    def := t.Field(field.Name)
+    if def == nil && t.Name == e.schema.Query {
+        def = e.schema.introspectionField(field.Name)
+    }
+    if def == nil {
+        return nil, &fieldError{message: fmt.Sprintf("Cannot query field %q on type %q", field.Name, t.Name), path: path}
+    }
+
+    args, err := e.coerceArguments(def.Args, field.Arguments)
+    if err != nil {
+        return nil, &fieldError{message: err.Error(), path: path}
+    }
+
+    var resolved []interface{}
+    if def.Resolve != nil {
+        resolved, err = def.Resolve(e.ctx, parents, args)
+        if err == nil && len(resolved) != len(parents) {
+            err = fmt.Errorf("resolver for %s.%s returned %d values for %d parents", t.Name, def.Name, len(resolved), len(parents))
+        }
+    } else {
+        resolved = make([]interface{}, len(parents))
+        for i, parent := range parents {
+            if m, ok := parent.(map[string]interface{}); ok {
+                resolved[i] = m[def.Name]
+            }
+        }
+    }
+
+    var ferr *fieldError
+    if err != nil {
+        ferr = &fieldError{message: err.Error(), path: path}
+    } else {
+        var subSelections []Selection
+        for _, f := range group.fields {
+            subSelections = append(subSelections, f.SelectionSet...)
+        }
+        values, ferr = e.completeValues(def.Type, subSelections, resolved, path)
+    }
+
+    if ferr != nil {
+        if def.Type.Kind == KindNonNull {
+            return nil, ferr
+        }
+        e.addError(ferr)
+        return make([]interface{}, len(parents)), nil
+    }
+    return values, nil
+}
+
+// introspectionField returns the __schema and __type meta fields of the query root
+func (s *Schema) introspectionField(name string) *FieldDef {
+    switch name {
+    case "__schema":
+        return &FieldDef{
+            Name: "__schema",
+            Type: NonNullOf(Named("__Schema")),
+            Resolve: func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+                return []interface{}{s.introspect()}, nil
+            },
+        }
+    case "__type":
+        return &FieldDef{
+            Name: "__type",
+            Args: []*InputValue{{Name: "name", Type: NonNullOf(Named("String"))}},
+            Type: Named("__Type"),
+            Resolve: func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+                return []interface{}{s.typeMap(args["name"].(string))}, nil
+            },
+        }
+    }
+    return nil
+}
+
+// completeValues converts resolved values to response values for a batch of parents
+func (e *executor) completeValues(ref *TypeRef, selections []Selection, values []interface{}, path []interface{}) ([]interface{}, *fieldError) {
+    switch ref.Kind {
+    case KindNonNull:
+        completed, ferr := e.completeValues(ref.OfType, selections, values, path)
+        if ferr != nil {
+            return nil, ferr
+        }
+        for _, value := range completed {
+            if value == nil {
+                return nil, &fieldError{message: "Cannot return null for non-nullable field", path: path}
+            }
+        }
+        return completed, nil
+
+    case KindList:
+        // Flatten every list into one batch so nested fields resolve together
+        var flat []interface{}
+        lengths := make([]int, len(values))
+        for i, value := range values {
+            if value == nil {
+                lengths[i] = -1
+                continue
+            }
+            items, ok := value.([]interface{})
+            if !ok {
+                return nil, &fieldError{message: fmt.Sprintf("Expected a list, got %T", value), path: path}
+            }
+            lengths[i] = len(items)
+            flat = append(flat, items...)
+        }
+        completed, ferr := e.completeValues(ref.OfType, selections, flat, path)
+        if ferr != nil {
+            return nil, ferr
+        }
+        result := make([]interface{}, len(values))
+        offset := 0
+        for i, n := range lengths {
+            if n < 0 {
+                continue
+            }
+            result[i] = completed[offset : offset+n]
+            offset += n
+        }
+        return result, nil
+    }
+
+    t, ok := e.schema.Types[ref.Name]
+    if !ok {
+        return nil, &fieldError{message: fmt.Sprintf("Unknown type %q", ref.Name), path: path}
+    }
+
+    result := make([]interface{}, len(values))
+    switch t.Kind {
+    case Object:
+        if len(selections) == 0 {
+            return nil, &fieldError{message: fmt.Sprintf("Field of type %q must have a selection of subfields", t.Name), path: path}
+        }
+        var present []interface{}
+        var indexes []int
+        for i, value := range values {
+            if value != nil {
+                present = append(present, value)
+                indexes = append(indexes, i)
+            }
+        }
+        if len(present) == 0 {
+            return result, nil
+        }
+        objects, ferr := e.executeSelectionSet(t, present, selections, path)
+        if ferr != nil {
+            return nil, ferr
+        }
+        for j, i := range indexes {
+            result[i] = objects[j]
+        }
+    default:
+        if len(selections) > 0 {
+            return nil, &fieldError{message: fmt.Sprintf("Field of type %q must not have a selection of subfields", t.Name), path: path}
+        }
+        for i, value := range values {
+            serialized, err := serialize(t, value)
+            if err != nil {
+                return nil, &fieldError{message: err.Error(), path: path}
+            }
+            result[i] = serialized
+        }
+    }
+    return result, nil
+}
+
+// serialize converts a resolved leaf value to its scalar or enum response value
+func serialize(t *Type, value interface{}) (interface{}, error) {
+    if value == nil {
+        return nil, nil
+    }
+    if b, ok := value.([]byte); ok {
+        value = string(b)
+    }
+    if tm, ok := value.(time.Time); ok {
+        value = tm.Format(time.RFC3339Nano)
+    }
+
+    switch t.Name {
+    case "Int":
+        switch v := value.(type) {
+        case int64:
+            return v, nil
+        case int:
+            return int64(v), nil
+        case float64:
+            if v == math.Trunc(v) {
+                return int64(v), nil
+            }
+        case bool:
+            if v {
+                return int64(1), nil
+            }
+            return int64(0), nil
+        case string:
+            if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+                return n, nil
+            }
+        }
+        return nil, fmt.Errorf("Int cannot represent value %v", value)
+    case "Float":
+        switch v := value.(type) {
+        case float64:
+            return v, nil
+        case int64:
+            return float64(v), nil
+        case int:
+            return float64(v), nil
+        case string:
+            if f, err := strconv.ParseFloat(v, 64); err == nil {
+                return f, nil
+            }
+        }
+        return nil, fmt.Errorf("Float cannot represent value %v", value)
+    case "Boolean":
+        switch v := value.(type) {
+        case bool:
+            return v, nil
+        case int64:
+            return v != 0, nil
+        case float64:
+            return v != 0, nil
+        }
+        return nil, fmt.Errorf("Boolean cannot represent value %v", value)
+    case "String", "ID":
+        if s, ok := value.(string); ok {
+            return s, nil
+        }
+        return fmt.Sprint(value), nil
+    }
+
+    if t.Kind == Enum {
+        name := fmt.Sprint(value)
+        for _, v := range t.EnumValues {
+            if v == name {
+                return name, nil
+            }
+        }
+        return nil, fmt.Errorf("Enum %q cannot represent value %v", t.Name, value)
+    }
+    return value, nil
+}
+
+// appendPath returns a copy of path with one more element
+func appendPath(path []interface{}, key interface{}) []interface{} {
+    result := make([]interface{}, len(path)+1)
+    copy(result, path)
+    result[len(path)] = key
+    return result
+}
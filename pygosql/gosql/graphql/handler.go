@@ -0,0 +1,107 @@
+// handler.go
+package graphql
+
+import (
+    "encoding/json"
+    "io"
+    "mime"
+    "net/http"
+    "strings"
+)
+
+// maxRequestBytes limits the size of a GraphQL request body
+const maxRequestBytes = 1 << 20
+
+// Handler serves GraphQL requests over HTTP
+// GET requests take query, operationName and JSON-encoded variables from the URL and may only
+// run queries; POST requests take a JSON body or a raw application/graphql document
+type Handler struct {
+    Schema *Schema
+
+    // Authorize, when set, is called with the operation type ("query" or "mutation")
+    // before execution; an error is returned to the client with the given status
+    Authorize func(r *http.Request, operation string) (int, error)
+}
+
+// NewHandler creates an HTTP handler for the schema
+func NewHandler(schema *Schema) *Handler {
+    return &Handler{Schema: schema}
+}
+
+// ServeHTTP decodes the request, executes it and writes the JSON response
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    var req Request
+    switch r.Method {
+    case http.MethodGet:
+        query := r.URL.Query()
+        req.Query = query.Get("query")
+        req.OperationName = query.Get("operationName")
+        if variables := query.Get("variables"); variables != "" {
+            if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+                h.writeError(w, http.StatusBadRequest, "variables must be a JSON object")
+                return
+            }
+        }
+    case http.MethodPost:
+        body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+        if err != nil {
+            h.writeError(w, http.StatusBadRequest, "failed to read request body")
+            return
+        }
+        mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+        if mediaType == "application/graphql" {
+            req.Query = string(body)
+        } else if err := json.Unmarshal(body, &req); err != nil {
+            h.writeError(w, http.StatusBadRequest, "request body must be a JSON object with a query")
+            return
+        }
+    default:
+        w.Header().Set("Allow", "GET, POST")
+        h.writeError(w, http.StatusMethodNotAllowed, "GraphQL requests must use GET or POST")
+        return
+    }
+
+    if strings.TrimSpace(req.Query) == "" {
+        h.writeError(w, http.StatusBadRequest, "missing query")
+        return
+    }
+
+    // Documents that do not parse fail in Execute with the parse error
+    operation := "query"
+    if doc, err := Parse(req.Query); err == nil {
+        if op, err := selectOperation(doc, req.OperationName); err == nil {
+            operation = op.Type
+        }
+    }
+    if r.Method == http.MethodGet && operation != "query" {
+        w.Header().Set("Allow", "POST")
+        h.writeError(w, http.StatusMethodNotAllowed, "mutations must use POST")
+        return
+    }
+    if h.Authorize != nil {
+        if status, err := h.Authorize(r, operation); err != nil {
+            h.writeError(w, status, err.Error())
+            return
+        }
+    }
+
+    response := h.Schema.Execute(r.Context(), req)
+    status := http.StatusOK
+    if response.Data == nil {
+        // The request failed before execution started, e.g. a syntax or variable error
+        status = http.StatusBadRequest
+    }
+    h.write(w, status, response)
+}
+
+// writeError writes a response holding a single error
+func (h *Handler) writeError(w http.ResponseWriter, status int, message string) {
+    h.write(w, status, &Response{Errors: []*Error{{Message: message}}})
+}
+
+// write encodes a response as JSON
+func (h *Handler) write(w http.ResponseWriter, status int, response *Response) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(response)
+}
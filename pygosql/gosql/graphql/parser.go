@@ -0,0 +1,459 @@
+// parser.go
+package graphql
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// token kinds produced by the lexer
+const (
+    tokEOF = iota
+    tokPunct
+    tokName
+    tokInt
+    tokFloat
+    tokString
+)
+
+// token is a lexical token with its position for error messages
+type token struct {
+    kind  int
+    value string
+    line  int
+    col   int
+}
+
+// parser is a recursive descent parser for executable GraphQL documents
+type parser struct {
+    src  string
+    pos  int
+    line int
+    col  int
+    tok  token
+}
+
+// Parse parses a GraphQL query document containing operations and fragments
+func Parse(source string) (doc *Document, err error) {
+    p := &parser{src: source, line: 1, col: 1}
+    defer func() {
+        if r := recover(); r != nil {
+            if syntaxErr, ok := r.(*Error); ok {
+                err = syntaxErr
+                return
+            }
+            panic(r)
+        }
+    }()
+
+    p.next()
+    doc = &Document{Fragments: make(map[string]*Fragment)}
+    for p.tok.kind != tokEOF {
+        switch {
+        case p.peek("{"):
+            doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: p.parseSelectionSet()})
+        case p.tok.kind == tokName && (p.tok.value == "query" || p.tok.value == "mutation" || p.tok.value == "subscription"):
+            doc.Operations = append(doc.Operations, p.parseOperation())
+        case p.tok.kind == tokName && p.tok.value == "fragment":
+            fragment := p.parseFragment()
+            doc.Fragments[fragment.Name] = fragment
+        default:
+            p.fail("unexpected %s", p.describe())
+        }
+    }
+
+    if len(doc.Operations) == 0 {
+        return nil, &Error{Message: "document contains no operations"}
+    }
+    return doc, nil
+}
+
+// fail aborts parsing with a syntax error at the current token
+func (p *parser) fail(format string, args ...interface{}) {
+    panic(&Error{
+        Message:   "Syntax Error: " + fmt.Sprintf(format, args...),
+        Locations: []Location{{Line: p.tok.line, Column: p.tok.col}},
+    })
+}
+
+// describe returns a readable description of the current token
+func (p *parser) describe() string {
+    if p.tok.kind == tokEOF {
+        return "<EOF>"
+    }
+    return strconv.Quote(p.tok.value)
+}
+
+// peek reports whether the current token is the given punctuator
+func (p *parser) peek(punct string) bool {
+    return p.tok.kind == tokPunct && p.tok.value == punct
+}
+
+// skip consumes the given punctuator if present
+func (p *parser) skip(punct string) bool {
+    if p.peek(punct) {
+        p.next()
+        return true
+    }
+    return false
+}
+
+// expect consumes the given punctuator or fails
+func (p *parser) expect(punct string) {
+    if !p.skip(punct) {
+        p.fail("expected %q, found %s", punct, p.describe())
+    }
+}
+
+// name consumes a name token or fails
+func (p *parser) name() string {
+    if p.tok.kind != tokName {
+        p.fail("expected name, found %s", p.describe())
+    }
+    value := p.tok.value
+    p.next()
+    return value
+}
+
+func (p *parser) parseOperation() *Operation {
+    op := &Operation{Type: p.name()}
+    if p.tok.kind == tokName {
+        op.Name = p.name()
+    }
+    if p.skip("(") {
+        for !p.skip(")") {
+            p.expect("$")
+            def := &VariableDef{Name: p.name()}
+            p.expect(":")
+            def.Type = p.parseType()
+            if p.skip("=") {
+                def.DefaultValue = p.parseValue(true)
+            }
+            p.parseDirectives()
+            op.Variables = append(op.Variables, def)
+        }
+    }
+    p.parseDirectives()
+    op.SelectionSet = p.parseSelectionSet()
+    return op
+}
+
+func (p *parser) parseFragment() *Fragment {
+    p.name() // fragment
+    fragment := &Fragment{Name: p.name()}
+    if p.tok.kind != tokName || p.tok.value != "on" {
+        p.fail("expected \"on\", found %s", p.describe())
+    }
+    p.next()
+    fragment.TypeCondition = p.name()
+    p.parseDirectives()
+    fragment.SelectionSet = p.parseSelectionSet()
+    return fragment
+}
+
+func (p *parser) parseSelectionSet() []Selection {
+    p.expect("{")
+    var selections []Selection
+    for !p.skip("}") {
+        if p.tok.kind == tokEOF {
+            p.fail("unterminated selection set")
+        }
+        selections = append(selections, p.parseSelection())
+    }
+    return selections
+}
+
+func (p *parser) parseSelection() Selection {
+    if p.skip("...") {
+        if p.tok.kind == tokName && p.tok.value != "on" {
+            return &FragmentSpread{Name: p.name(), Directives: p.parseDirectives()}
+        }
+        inline := &InlineFragment{}
+        if p.tok.kind == tokName && p.tok.value == "on" {
+            p.next()
+            inline.TypeCondition = p.name()
+        }
+        inline.Directives = p.parseDirectives()
+        inline.SelectionSet = p.parseSelectionSet()
+        return inline
+    }
+
+    field := &Field{Name: p.name()}
+    if p.skip(":") {
+        field.Alias = field.Name
+        field.Name = p.name()
+    }
+    field.Arguments = p.parseArguments()
+    field.Directives = p.parseDirectives()
+    if p.peek("{") {
+        field.SelectionSet = p.parseSelectionSet()
+    }
+    return field
+}
+
+func (p *parser) parseArguments() map[string]Value {
+    args := make(map[string]Value)
+    if !p.skip("(") {
+        return args
+    }
+    for !p.skip(")") {
+        name := p.name()
+        p.expect(":")
+        args[name] = p.parseValue(false)
+    }
+    return args
+}
+
+func (p *parser) parseDirectives() []*Directive {
+    var directives []*Directive
+    for p.skip("@") {
+        directives = append(directives, &Directive{Name: p.name(), Arguments: p.parseArguments()})
+    }
+    return directives
+}
+
+func (p *parser) parseType() *TypeRef {
+    var ref *TypeRef
+    if p.skip("[") {
+        ref = &TypeRef{Kind: KindList, OfType: p.parseType()}
+        p.expect("]")
+    } else {
+        ref = &TypeRef{Kind: KindNamed, Name: p.name()}
+    }
+    if p.skip("!") {
+        ref = &TypeRef{Kind: KindNonNull, OfType: ref}
+    }
+    return ref
+}
+
+func (p *parser) parseValue(constant bool) Value {
+    tok := p.tok
+    switch tok.kind {
+    case tokPunct:
+        switch tok.value {
+        case "$":
+            if constant {
+                p.fail("unexpected variable in constant value")
+            }
+            p.next()
+            return &Variable{Name: p.name()}
+        case "[":
+            p.next()
+            list := &ListValue{}
+            for !p.skip("]") {
+                if p.tok.kind == tokEOF {
+                    p.fail("unterminated list")
+                }
+                list.Values = append(list.Values, p.parseValue(constant))
+            }
+            return list
+        case "{":
+            p.next()
+            object := &ObjectValue{Values: make(map[string]Value)}
+            for !p.skip("}") {
+                name := p.name()
+                p.expect(":")
+                object.Names = append(object.Names, name)
+                object.Values[name] = p.parseValue(constant)
+            }
+            return object
+        }
+    case tokInt:
+        p.next()
+        n, err := strconv.ParseInt(tok.value, 10, 64)
+        if err != nil {
+            p.fail("invalid integer %s", tok.value)
+        }
+        return &IntValue{Value: n}
+    case tokFloat:
+        p.next()
+        f, err := strconv.ParseFloat(tok.value, 64)
+        if err != nil {
+            p.fail("invalid float %s", tok.value)
+        }
+        return &FloatValue{Value: f}
+    case tokString:
+        p.next()
+        return &StringValue{Value: tok.value}
+    case tokName:
+        p.next()
+        switch tok.value {
+        case "true":
+            return &BooleanValue{Value: true}
+        case "false":
+            return &BooleanValue{Value: false}
+        case "null":
+            return &NullValue{}
+        default:
+            return &EnumValue{Value: tok.value}
+        }
+    }
+    p.fail("unexpected %s", p.describe())
+    return nil
+}
+
+// next advances to the next token, skipping whitespace, commas and comments
+func (p *parser) next() {
+    for p.pos < len(p.src) {
+        c := p.src[p.pos]
+        switch {
+        case c == '\n':
+            p.pos++
+            p.line++
+            p.col = 1
+        case c == ' ' || c == '\t' || c == '\r' || c == ',':
+            p.advance(1)
+        case c == '#':
+            for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+                p.advance(1)
+            }
+        default:
+            p.lexToken()
+            return
+        }
+    }
+    p.tok = token{kind: tokEOF, line: p.line, col: p.col}
+}
+
+// advance moves the read position forward n bytes on the current line
+func (p *parser) advance(n int) {
+    p.pos += n
+    p.col += n
+}
+
+// lexToken reads the token starting at the current position
+func (p *parser) lexToken() {
+    start, line, col := p.pos, p.line, p.col
+    c := p.src[p.pos]
+    emit := func(kind int, value string) {
+        p.tok = token{kind: kind, value: value, line: line, col: col}
+    }
+
+    switch {
+    case strings.HasPrefix(p.src[p.pos:], "..."):
+        p.advance(3)
+        emit(tokPunct, "...")
+    case strings.ContainsRune("!$&()=:@[]{}|", rune(c)):
+        p.advance(1)
+        emit(tokPunct, string(c))
+    case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+        for p.pos < len(p.src) && isNameByte(p.src[p.pos]) {
+            p.advance(1)
+        }
+        emit(tokName, p.src[start:p.pos])
+    case c == '-' || c >= '0' && c <= '9':
+        p.advance(1)
+        kind := tokInt
+        for p.pos < len(p.src) {
+            d := p.src[p.pos]
+            if d >= '0' && d <= '9' {
+                p.advance(1)
+            } else if d == '.' || d == 'e' || d == 'E' || ((d == '+' || d == '-') && kind == tokFloat) {
+                kind = tokFloat
+                p.advance(1)
+            } else {
+                break
+            }
+        }
+        emit(kind, p.src[start:p.pos])
+    case strings.HasPrefix(p.src[p.pos:], `"""`):
+        p.advance(3)
+        end := strings.Index(p.src[p.pos:], `"""`)
+        if end == -1 {
+            p.tok = token{line: line, col: col}
+            p.fail("unterminated block string")
+        }
+        raw := p.src[p.pos : p.pos+end]
+        for i := 0; i < end+3; i++ {
+            if p.src[p.pos] == '\n' {
+                p.line++
+                p.col = 0
+            }
+            p.advance(1)
+        }
+        emit(tokString, blockStringValue(raw))
+    case c == '"':
+        p.advance(1)
+        var b strings.Builder
+        for {
+            if p.pos >= len(p.src) || p.src[p.pos] == '\n' {
+                p.tok = token{line: line, col: col}
+                p.fail("unterminated string")
+            }
+            ch := p.src[p.pos]
+            if ch == '"' {
+                p.advance(1)
+                break
+            }
+            if ch == '\\' && p.pos+1 < len(p.src) {
+                esc := p.src[p.pos+1]
+                p.advance(2)
+                switch esc {
+                case 'n':
+                    b.WriteByte('\n')
+                case 't':
+                    b.WriteByte('\t')
+                case 'r':
+                    b.WriteByte('\r')
+                case 'b':
+                    b.WriteByte('\b')
+                case 'f':
+                    b.WriteByte('\f')
+                case 'u':
+                    if p.pos+4 > len(p.src) {
+                        p.fail("invalid unicode escape")
+                    }
+                    code, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+                    if err != nil {
+                        p.fail("invalid unicode escape")
+                    }
+                    b.WriteRune(rune(code))
+                    p.advance(4)
+                default:
+                    b.WriteByte(esc)
+                }
+                continue
+            }
+            _, size := utf8.DecodeRuneInString(p.src[p.pos:])
+            b.WriteString(p.src[p.pos : p.pos+size])
+            p.advance(size)
+        }
+        emit(tokString, b.String())
+    default:
+        p.tok = token{line: line, col: col}
+        p.fail("unexpected character %q", c)
+    }
+}
+
+// blockStringValue removes the common indentation and blank edge lines of a block string
+func blockStringValue(raw string) string {
+    lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+    indent := -1
+    for _, line := range lines[1:] {
+        trimmed := strings.TrimLeft(line, " \t")
+        if trimmed == "" {
+            continue
+        }
+        if n := len(line) - len(trimmed); indent == -1 || n < indent {
+            indent = n
+        }
+    }
+    for i := 1; i < len(lines) && indent > 0; i++ {
+        if len(lines[i]) >= indent {
+            lines[i] = lines[i][indent:]
+        }
+    }
+    for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+        lines = lines[1:]
+    }
+    for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+        lines = lines[:len(lines)-1]
+    }
+    return strings.Join(lines, "\n")
+}
+
+func isNameByte(c byte) bool {
+    return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
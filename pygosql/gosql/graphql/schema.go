@@ -0,0 +1,362 @@
+// schema.go
+package graphql
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// Type reference kinds
+const (
+    KindNamed   = "NAMED"
+    KindList    = "LIST"
+    KindNonNull = "NON_NULL"
+)
+
+// Named type kinds, as reported by introspection
+const (
+    Scalar      = "SCALAR"
+    Object      = "OBJECT"
+    InputObject = "INPUT_OBJECT"
+    Enum        = "ENUM"
+)
+
+// TypeRef refers to a named type, optionally wrapped in list and non-null markers
+type TypeRef struct {
+    Kind   string   // KindNamed, KindList or KindNonNull
+    Name   string   // Type name for KindNamed references
+    OfType *TypeRef // Wrapped type for KindList and KindNonNull
+}
+
+// Named returns a reference to the named type
+func Named(name string) *TypeRef {
+    return &TypeRef{Kind: KindNamed, Name: name}
+}
+
+// ListOf wraps a type reference in a list
+func ListOf(ref *TypeRef) *TypeRef {
+    return &TypeRef{Kind: KindList, OfType: ref}
+}
+
+// NonNullOf marks a type reference as non-null
+func NonNullOf(ref *TypeRef) *TypeRef {
+    return &TypeRef{Kind: KindNonNull, OfType: ref}
+}
+
+// NamedType returns the name of the innermost named type
+func (t *TypeRef) NamedType() string {
+    for t.Kind != KindNamed {
+        t = t.OfType
+    }
+    return t.Name
+}
+
+// String renders the reference in GraphQL syntax, e.g. "[Users!]!"
+func (t *TypeRef) String() string {
+    switch t.Kind {
+    case KindList:
+        return "[" + t.OfType.String() + "]"
+    case KindNonNull:
+        return t.OfType.String() + "!"
+    default:
+        return t.Name
+    }
+}
+
+// ResolveFunc resolves a field for every parent object at once, returning one value per parent,
+// which lets relation fields load all related rows with a single query
+type ResolveFunc func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error)
+
+// Type is a named type in the schema
+type Type struct {
+    Kind        string        // Scalar, Object, InputObject or Enum
+    Name        string        // Type name
+    Description string        // Optional description
+    Fields      []*FieldDef   // Fields of an Object type
+    InputFields []*InputValue // Fields of an InputObject type
+    EnumValues  []string      // Values of an Enum type
+}
+
+// Field returns the field definition with the given name, or nil
+func (t *Type) Field(name string) *FieldDef {
+    for _, field := range t.Fields {
+        if field.Name == name {
+            return field
+        }
+    }
+    return nil
+}
+
+// FieldDef defines a field of an object type
+type FieldDef struct {
+    Name        string        // Field name
+    Description string        // Optional description
+    Args        []*InputValue // Accepted arguments
+    Type        *TypeRef      // Result type
+    Resolve     ResolveFunc   // Batch resolver, nil to read the field from map parents
+}
+
+// InputValue defines an argument or an input object field
+type InputValue struct {
+    Name         string      // Argument or field name
+    Description  string      // Optional description
+    Type         *TypeRef    // Input type
+    DefaultValue interface{} // Default value used when absent, nil for none
+}
+
+// Schema is a set of types with query and mutation root types
+type Schema struct {
+    Query    string           // Name of the query root type
+    Mutation string           // Name of the mutation root type, empty if none
+    Types    map[string]*Type // All named types
+    order    []string         // Type names in registration order
+
+    introspectOnce sync.Once
+    introspection  map[string]interface{}
+    typeMaps       map[string]map[string]interface{}
+}
+
+// NewSchema creates a schema with the built-in scalars and introspection types
+func NewSchema() *Schema {
+    s := &Schema{Types: make(map[string]*Type)}
+    for _, name := range []string{"Int", "Float", "String", "Boolean", "ID"} {
+        s.Add(&Type{Kind: Scalar, Name: name})
+    }
+    addIntrospectionTypes(s)
+    return s
+}
+
+// Add registers a named type, replacing any type with the same name
+func (s *Schema) Add(t *Type) {
+    if _, exists := s.Types[t.Name]; !exists {
+        s.order = append(s.order, t.Name)
+    }
+    s.Types[t.Name] = t
+}
+
+// addIntrospectionTypes registers the __Schema family of types used by introspection queries
+func addIntrospectionTypes(s *Schema) {
+    str, boolean := Named("String"), Named("Boolean")
+    nonNull := func(name string) *TypeRef { return NonNullOf(Named(name)) }
+    listOf := func(name string) *TypeRef { return ListOf(nonNull(name)) }
+    includeDeprecated := []*InputValue{{Name: "includeDeprecated", Type: boolean, DefaultValue: false}}
+
+    s.Add(&Type{Kind: Enum, Name: "__TypeKind", EnumValues: []string{
+        "SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL",
+    }})
+    s.Add(&Type{Kind: Enum, Name: "__DirectiveLocation", EnumValues: []string{
+        "QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD",
+        "INLINE_FRAGMENT", "VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION",
+        "ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT",
+        "INPUT_FIELD_DEFINITION",
+    }})
+    s.Add(&Type{Kind: Object, Name: "__Schema", Fields: []*FieldDef{
+        {Name: "description", Type: str},
+        {Name: "types", Type: NonNullOf(listOf("__Type"))},
+        {Name: "queryType", Type: nonNull("__Type")},
+        {Name: "mutationType", Type: Named("__Type")},
+        {Name: "subscriptionType", Type: Named("__Type")},
+        {Name: "directives", Type: NonNullOf(listOf("__Directive"))},
+    }})
+    s.Add(&Type{Kind: Object, Name: "__Type", Fields: []*FieldDef{
+        {Name: "kind", Type: nonNull("__TypeKind")},
+        {Name: "name", Type: str},
+        {Name: "description", Type: str},
+        {Name: "specifiedByURL", Type: str},
+        {Name: "fields", Type: listOf("__Field"), Args: includeDeprecated},
+        {Name: "interfaces", Type: listOf("__Type")},
+        {Name: "possibleTypes", Type: listOf("__Type")},
+        {Name: "enumValues", Type: listOf("__EnumValue"), Args: includeDeprecated},
+        {Name: "inputFields", Type: listOf("__InputValue"), Args: includeDeprecated},
+        {Name: "ofType", Type: Named("__Type")},
+        {Name: "isOneOf", Type: boolean},
+    }})
+    s.Add(&Type{Kind: Object, Name: "__Field", Fields: []*FieldDef{
+        {Name: "name", Type: nonNull("String")},
+        {Name: "description", Type: str},
+        {Name: "args", Type: NonNullOf(listOf("__InputValue")), Args: includeDeprecated},
+        {Name: "type", Type: nonNull("__Type")},
+        {Name: "isDeprecated", Type: nonNull("Boolean")},
+        {Name: "deprecationReason", Type: str},
+    }})
+    s.Add(&Type{Kind: Object, Name: "__InputValue", Fields: []*FieldDef{
+        {Name: "name", Type: nonNull("String")},
+        {Name: "description", Type: str},
+        {Name: "type", Type: nonNull("__Type")},
+        {Name: "defaultValue", Type: str},
+        {Name: "isDeprecated", Type: nonNull("Boolean")},
+        {Name: "deprecationReason", Type: str},
+    }})
+    s.Add(&Type{Kind: Object, Name: "__EnumValue", Fields: []*FieldDef{
+        {Name: "name", Type: nonNull("String")},
+        {Name: "description", Type: str},
+        {Name: "isDeprecated", Type: nonNull("Boolean")},
+        {Name: "deprecationReason", Type: str},
+    }})
+    s.Add(&Type{Kind: Object, Name: "__Directive", Fields: []*FieldDef{
+        {Name: "name", Type: nonNull("String")},
+        {Name: "description", Type: str},
+        {Name: "locations", Type: NonNullOf(listOf("__DirectiveLocation"))},
+        {Name: "args", Type: NonNullOf(listOf("__InputValue")), Args: includeDeprecated},
+        {Name: "isRepeatable", Type: nonNull("Boolean")},
+    }})
+}
+
+// introspect returns the __schema value, building the introspection data on first use
+func (s *Schema) introspect() map[string]interface{} {
+    s.introspectOnce.Do(func() {
+        s.typeMaps = make(map[string]map[string]interface{})
+
+        // Create every named type map first so references can share them
+        names := append([]string{}, s.order...)
+        sort.Strings(names)
+        for _, name := range names {
+            t := s.Types[name]
+            s.typeMaps[name] = map[string]interface{}{
+                "kind":        t.Kind,
+                "name":        t.Name,
+                "description": optionalString(t.Description),
+            }
+        }
+
+        for _, name := range names {
+            t, m := s.Types[name], s.typeMaps[name]
+            switch t.Kind {
+            case Object:
+                fields := make([]interface{}, 0, len(t.Fields))
+                for _, field := range t.Fields {
+                    fields = append(fields, map[string]interface{}{
+                        "name":              field.Name,
+                        "description":       optionalString(field.Description),
+                        "args":              s.inputValueMaps(field.Args),
+                        "type":              s.typeRefMap(field.Type),
+                        "isDeprecated":      false,
+                        "deprecationReason": nil,
+                    })
+                }
+                m["fields"] = fields
+                m["interfaces"] = []interface{}{}
+            case InputObject:
+                m["inputFields"] = s.inputValueMaps(t.InputFields)
+                m["isOneOf"] = false
+            case Enum:
+                values := make([]interface{}, 0, len(t.EnumValues))
+                for _, value := range t.EnumValues {
+                    values = append(values, map[string]interface{}{
+                        "name":              value,
+                        "description":       nil,
+                        "isDeprecated":      false,
+                        "deprecationReason": nil,
+                    })
+                }
+                m["enumValues"] = values
+            }
+        }
+
+        types := make([]interface{}, 0, len(names))
+        for _, name := range names {
+            types = append(types, s.typeMaps[name])
+        }
+
+        ifArg := []*InputValue{{Name: "if", Type: NonNullOf(Named("Boolean"))}}
+        directive := func(name, description string) map[string]interface{} {
+            return map[string]interface{}{
+                "name":         name,
+                "description":  description,
+                "locations":    []interface{}{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+                "args":         s.inputValueMaps(ifArg),
+                "isRepeatable": false,
+            }
+        }
+
+        s.introspection = map[string]interface{}{
+            "description":      nil,
+            "types":            types,
+            "queryType":        s.typeMaps[s.Query],
+            "mutationType":     nil,
+            "subscriptionType": nil,
+            "directives": []interface{}{
+                directive("include", "Directs the executor to include this field or fragment only when the `if` argument is true."),
+                directive("skip", "Directs the executor to skip this field or fragment when the `if` argument is true."),
+            },
+        }
+        if s.Mutation != "" {
+            s.introspection["mutationType"] = s.typeMaps[s.Mutation]
+        }
+    })
+    return s.introspection
+}
+
+// typeMap returns the introspection value of a named type, or nil when unknown
+func (s *Schema) typeMap(name string) interface{} {
+    s.introspect()
+    if m, ok := s.typeMaps[name]; ok {
+        return m
+    }
+    return nil
+}
+
+// typeRefMap returns the introspection value of a possibly wrapped type reference
+func (s *Schema) typeRefMap(ref *TypeRef) map[string]interface{} {
+    if ref.Kind == KindNamed {
+        return s.typeMaps[ref.Name]
+    }
+    return map[string]interface{}{
+        "kind":   ref.Kind,
+        "name":   nil,
+        "ofType": s.typeRefMap(ref.OfType),
+    }
+}
+
+// inputValueMaps returns the introspection values of arguments or input fields
+func (s *Schema) inputValueMaps(values []*InputValue) []interface{} {
+    result := make([]interface{}, 0, len(values))
+    for _, value := range values {
+        var defaultValue interface{}
+        if value.DefaultValue != nil {
+            defaultValue = formatLiteral(value.DefaultValue)
+        }
+        result = append(result, map[string]interface{}{
+            "name":              value.Name,
+            "description":       optionalString(value.Description),
+            "type":              s.typeRefMap(value.Type),
+            "defaultValue":      defaultValue,
+            "isDeprecated":      false,
+            "deprecationReason": nil,
+        })
+    }
+    return result
+}
+
+// formatLiteral renders a Go input value as a GraphQL literal for defaultValue
+func formatLiteral(value interface{}) string {
+    switch v := value.(type) {
+    case string:
+        return strconv.Quote(v)
+    case enumLiteral:
+        return string(v)
+    case []interface{}:
+        parts := make([]string, len(v))
+        for i, item := range v {
+            parts[i] = formatLiteral(item)
+        }
+        return "[" + strings.Join(parts, ", ") + "]"
+    default:
+        return fmt.Sprint(v)
+    }
+}
+
+// enumLiteral marks a default value that must be rendered without quotes
+type enumLiteral string
+
+// optionalString returns nil for an empty string so it serializes as null
+func optionalString(s string) interface{} {
+    if s == "" {
+        return nil
+    }
+    return s
+}
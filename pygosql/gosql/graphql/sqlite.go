@@ -0,0 +1,787 @@
+// sqlite.go
+package graphql
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "gosql/database"
+    "sort"
+    "strings"
+)
+
+// relationBatchSize limits the number of keys bound in a single IN (...) lookup
+const relationBatchSize = 500
+
+// table holds what the schema builder knows about one SQLite table
+type table struct {
+    name     string
+    typeName string
+    columns  []database.Column
+    pk       []string
+    fields   map[string]bool // Field names already used on the object type
+}
+
+// builder turns database tables into schema types and resolvers
+type builder struct {
+    db     *database.Database
+    schema *Schema
+    tables map[string]*table
+    query  *Type
+    mutate *Type
+}
+
+// NewSQLiteSchema builds a GraphQL schema from the tables of a SQLite database
+// Every table becomes an object type with a field per column, relation fields for
+// foreign keys in both directions, a filtered and paginated list query, a lookup by
+// primary key, and insert, update and delete mutations
+func NewSQLiteSchema(db *database.Database) (*Schema, error) {
+    b := &builder{
+        db:     db,
+        schema: NewSchema(),
+        tables: make(map[string]*table),
+        query:  &Type{Kind: Object, Name: "Query"},
+        mutate: &Type{Kind: Object, Name: "Mutation"},
+    }
+    b.schema.Query = b.query.Name
+    b.schema.Add(b.query)
+    b.addSharedTypes()
+
+    names, err := db.Tables()
+    if err != nil {
+        return nil, err
+    }
+
+    var tables []*table
+    for _, name := range names {
+        // gosql_ tables hold server state such as API keys and are never exposed
+        if !isName(name) || strings.HasPrefix(name, "gosql_") {
+            continue
+        }
+        columns, err := db.TableColumns(name)
+        if err != nil {
+            return nil, err
+        }
+        t := &table{name: name, fields: make(map[string]bool)}
+        for _, column := range columns {
+            if !isName(column.Name) {
+                continue
+            }
+            t.columns = append(t.columns, column)
+            t.fields[column.Name] = true
+            if column.PrimaryKey {
+                t.pk = append(t.pk, column.Name)
+            }
+        }
+        if len(t.columns) == 0 {
+            continue
+        }
+        t.typeName = b.typeName(name)
+        b.tables[name] = t
+        tables = append(tables, t)
+        b.addTableTypes(t)
+    }
+
+    for _, t := range tables {
+        if err := b.addRelations(t); err != nil {
+            return nil, err
+        }
+    }
+    for _, t := range tables {
+        b.addRootFields(t)
+    }
+
+    if len(b.mutate.Fields) > 0 {
+        b.schema.Mutation = b.mutate.Name
+        b.schema.Add(b.mutate)
+    }
+    return b.schema, nil
+}
+
+// addSharedTypes registers the comparison inputs and ordering enum used by every table
+func (b *builder) addSharedTypes() {
+    b.schema.Add(&Type{Kind: Enum, Name: "OrderDirection", EnumValues: []string{"ASC", "DESC"}})
+    for _, scalar := range []string{"Int", "Float", "String", "Boolean"} {
+        ref := Named(scalar)
+        fields := []*InputValue{
+            {Name: "eq", Type: ref},
+            {Name: "neq", Type: ref},
+        }
+        if scalar != "Boolean" {
+            fields = append(fields,
+                &InputValue{Name: "gt", Type: ref},
+                &InputValue{Name: "gte", Type: ref},
+                &InputValue{Name: "lt", Type: ref},
+                &InputValue{Name: "lte", Type: ref},
+                &InputValue{Name: "in", Type: ListOf(NonNullOf(ref))},
+                &InputValue{Name: "nin", Type: ListOf(NonNullOf(ref))},
+            )
+        }
+        if scalar == "String" {
+            fields = append(fields,
+                &InputValue{Name: "like", Type: ref},
+                &InputValue{Name: "nlike", Type: ref},
+            )
+        }
+        fields = append(fields, &InputValue{Name: "is_null", Type: Named("Boolean")})
+        b.schema.Add(&Type{
+            Kind:        InputObject,
+            Name:        scalar + "Comparison",
+            Description: "Conditions on a " + scalar + " column, combined with AND",
+            InputFields: fields,
+        })
+    }
+}
+
+// typeName picks an unused type name for a table, leaving room for its derived types
+func (b *builder) typeName(table string) string {
+    name := pascal(table)
+    for {
+        free := true
+        for _, suffix := range []string{"", "Filter", "OrderBy", "Column", "InsertInput", "SetInput", "MutationResult"} {
+            if _, exists := b.schema.Types[name+suffix]; exists || name+suffix == "Query" || name+suffix == "Mutation" {
+                free = false
+                break
+            }
+        }
+        if free {
+            return name
+        }
+        name += "Table"
+    }
+}
+
+// addTableTypes registers the object, filter, ordering and input types of a table
+func (b *builder) addTableTypes(t *table) {
+    object := &Type{Kind: Object, Name: t.typeName, Description: fmt.Sprintf("A row of the %s table", t.name)}
+    filter := &Type{Kind: InputObject, Name: t.typeName + "Filter", Description: fmt.Sprintf("Row conditions for %s, combined with AND", t.name)}
+    columnEnum := &Type{Kind: Enum, Name: t.typeName + "Column"}
+    insert := &Type{Kind: InputObject, Name: t.typeName + "InsertInput"}
+    set := &Type{Kind: InputObject, Name: t.typeName + "SetInput"}
+
+    for _, column := range t.columns {
+        scalar := scalarType(column.Type)
+
+        ref := Named(scalar)
+        if column.NotNull || (column.PrimaryKey && len(t.pk) == 1 && scalar == "Int") {
+            ref = NonNullOf(ref)
+        }
+        object.Fields = append(object.Fields, &FieldDef{Name: column.Name, Type: ref, Description: column.Type})
+
+        filter.InputFields = append(filter.InputFields, &InputValue{Name: column.Name, Type: Named(scalar + "Comparison")})
+        if column.Name != "true" && column.Name != "false" && column.Name != "null" {
+            columnEnum.EnumValues = append(columnEnum.EnumValues, column.Name)
+        }
+
+        insertRef := Named(scalar)
+        if column.NotNull && !column.HasDefault && !(column.PrimaryKey && len(t.pk) == 1 && scalar == "Int") {
+            insertRef = NonNullOf(insertRef)
+        }
+        insert.InputFields = append(insert.InputFields, &InputValue{Name: column.Name, Type: insertRef})
+        set.InputFields = append(set.InputFields, &InputValue{Name: column.Name, Type: Named(scalar)})
+    }
+
+    filterList := ListOf(NonNullOf(Named(filter.Name)))
+    filter.InputFields = append(filter.InputFields,
+        &InputValue{Name: "_and", Type: filterList, Description: "All conditions must hold"},
+        &InputValue{Name: "_or", Type: filterList, Description: "At least one condition must hold"},
+        &InputValue{Name: "_not", Type: Named(filter.Name), Description: "The condition must not hold"},
+    )
+
+    b.schema.Add(object)
+    b.schema.Add(filter)
+    b.schema.Add(columnEnum)
+    b.schema.Add(&Type{Kind: InputObject, Name: t.typeName + "OrderBy", InputFields: []*InputValue{
+        {Name: "column", Type: NonNullOf(Named(columnEnum.Name))},
+        {Name: "direction", Type: Named("OrderDirection"), DefaultValue: enumLiteral("ASC")},
+    }})
+    b.schema.Add(insert)
+    b.schema.Add(set)
+    b.schema.Add(&Type{Kind: Object, Name: t.typeName + "MutationResult", Fields: []*FieldDef{
+        {Name: "affected_rows", Type: NonNullOf(Named("Int"))},
+        {Name: "returning", Type: NonNullOf(ListOf(NonNullOf(Named(t.typeName))))},
+    }})
+}
+
+// listArgs returns the filtering, ordering and optional pagination arguments of a table list
+func listArgs(t *table, paginate bool) []*InputValue {
+    args := []*InputValue{
+        {Name: "where", Type: Named(t.typeName + "Filter")},
+        {Name: "order_by", Type: ListOf(NonNullOf(Named(t.typeName + "OrderBy")))},
+    }
+    if paginate {
+        args = append(args,
+            &InputValue{Name: "limit", Type: Named("Int")},
+            &InputValue{Name: "offset", Type: Named("Int")},
+        )
+    }
+    return args
+}
+
+// addRelations adds fields for the foreign keys of a table in both directions
+func (b *builder) addRelations(child *table) error {
+    keys, err := b.db.ForeignKeys(child.name)
+    if err != nil {
+        return err
+    }
+
+    for _, key := range keys {
+        parent, ok := b.tables[key.RefTable]
+        if !ok || !child.fields[key.Column] {
+            continue
+        }
+        refColumn := key.RefColumn
+        if refColumn == "" {
+            if len(parent.pk) != 1 {
+                continue
+            }
+            refColumn = parent.pk[0]
+        }
+
+        // Many-to-one: orders.user_id becomes orders.user
+        name := strings.TrimSuffix(key.Column, "_id")
+        if name == key.Column || child.fields[name] {
+            name = parent.name
+        }
+        if child.fields[name] {
+            name = key.Column + "_" + parent.name
+        }
+        if !child.fields[name] {
+            child.fields[name] = true
+            object := b.schema.Types[child.typeName]
+            object.Fields = append(object.Fields, &FieldDef{
+                Name:        name,
+                Description: fmt.Sprintf("The %s row referenced by %s", parent.name, key.Column),
+                Type:        Named(parent.typeName),
+                Resolve:     b.resolveReference(parent, key.Column, refColumn),
+            })
+        }
+
+        // One-to-many: users.orders lists the rows that reference the parent
+        name = child.name
+        if parent.fields[name] {
+            name = child.name + "_by_" + key.Column
+        }
+        if !parent.fields[name] {
+            parent.fields[name] = true
+            object := b.schema.Types[parent.typeName]
+            object.Fields = append(object.Fields, &FieldDef{
+                Name:        name,
+                Description: fmt.Sprintf("The %s rows whose %s references this row", child.name, key.Column),
+                Args:        listArgs(child, true),
+                Type:        NonNullOf(ListOf(NonNullOf(Named(child.typeName)))),
+                Resolve:     b.resolveReferencing(child, key.Column, refColumn),
+            })
+        }
+    }
+    return nil
+}
+
+// addRootFields adds the queries and mutations of a table
+func (b *builder) addRootFields(t *table) {
+    rowRef := NonNullOf(Named(t.typeName))
+    b.query.Fields = append(b.query.Fields, &FieldDef{
+        Name:        t.name,
+        Description: fmt.Sprintf("Rows of the %s table", t.name),
+        Args:        listArgs(t, true),
+        Type:        NonNullOf(ListOf(rowRef)),
+        Resolve:     b.resolveList(t),
+    })
+
+    if len(t.pk) > 0 {
+        var args []*InputValue
+        for _, column := range t.columns {
+            if column.PrimaryKey {
+                args = append(args, &InputValue{Name: column.Name, Type: NonNullOf(Named(scalarType(column.Type)))})
+            }
+        }
+        b.query.Fields = append(b.query.Fields, &FieldDef{
+            Name:        t.name + "_by_pk",
+            Description: fmt.Sprintf("A single %s row by primary key", t.name),
+            Args:        args,
+            Type:        Named(t.typeName),
+            Resolve:     b.resolveByPK(t),
+        })
+    }
+
+    result := NonNullOf(Named(t.typeName + "MutationResult"))
+    where := &InputValue{Name: "where", Type: NonNullOf(Named(t.typeName + "Filter")), Description: "Rows to change, at least one condition"}
+    b.mutate.Fields = append(b.mutate.Fields,
+        &FieldDef{
+            Name:        "insert_" + t.name,
+            Description: fmt.Sprintf("Insert a row into %s and return it", t.name),
+            Args:        []*InputValue{{Name: "object", Type: NonNullOf(Named(t.typeName + "InsertInput"))}},
+            Type:        Named(t.typeName),
+            Resolve:     b.resolveInsert(t),
+        },
+        &FieldDef{
+            Name:        "update_" + t.name,
+            Description: fmt.Sprintf("Update matching %s rows", t.name),
+            Args:        []*InputValue{where, {Name: "set", Type: NonNullOf(Named(t.typeName + "SetInput"))}},
+            Type:        result,
+            Resolve:     b.resolveUpdate(t),
+        },
+        &FieldDef{
+            Name:        "delete_" + t.name,
+            Description: fmt.Sprintf("Delete matching %s rows", t.name),
+            Args:        []*InputValue{where},
+            Type:        result,
+            Resolve:     b.resolveDelete(t),
+        },
+    )
+}
+
+// resolveList resolves the root list query of a table
+func (b *builder) resolveList(t *table) ResolveFunc {
+    return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+        var params []interface{}
+        query := "SELECT * FROM " + quote(t.name)
+        if where := buildFilter(args["where"], &params); where != "" {
+            query += " WHERE " + where
+        }
+        query += buildOrderBy(args["order_by"])
+        query += buildPagination(args, &params)
+
+        rows, err := b.db.QueryRows(query, params...)
+        if err != nil {
+            return nil, err
+        }
+        return repeat(rowList(rows), len(parents)), nil
+    }
+}
+
+// resolveByPK resolves the primary key lookup of a table
+func (b *builder) resolveByPK(t *table) ResolveFunc {
+    return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+        var conditions []string
+        var params []interface{}
+        for _, column := range t.pk {
+            conditions = append(conditions, quote(column)+" = ?")
+            params = append(params, args[column])
+        }
+        rows, err := b.db.QueryRows("SELECT * FROM "+quote(t.name)+" WHERE "+strings.Join(conditions, " AND "), params...)
+        if err != nil {
+            return nil, err
+        }
+        var row interface{}
+        if len(rows) > 0 {
+            row = rows[0]
+        }
+        return repeat(row, len(parents)), nil
+    }
+}
+
+// resolveReference loads the parent rows referenced by a batch of child rows
+func (b *builder) resolveReference(parent *table, column, refColumn string) ResolveFunc {
+    return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+        rows, err := b.loadByKeys(parent, refColumn, columnValues(parents, column), nil, nil)
+        if err != nil {
+            return nil, err
+        }
+        byKey := make(map[string]interface{}, len(rows))
+        for _, row := range rows {
+            byKey[keyOf(row[refColumn])] = row
+        }
+
+        results := make([]interface{}, len(parents))
+        for i, p := range parents {
+            if value := p.(map[string]interface{})[column]; value != nil {
+                results[i] = byKey[keyOf(value)]
+            }
+        }
+        return results, nil
+    }
+}
+
+// resolveReferencing loads the child rows referencing a batch of parent rows
+func (b *builder) resolveReferencing(child *table, column, refColumn string) ResolveFunc {
+    return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+        rows, err := b.loadByKeys(child, column, columnValues(parents, refColumn), args, args["order_by"])
+        if err != nil {
+            return nil, err
+        }
+        byKey := make(map[string][]interface{})
+        for _, row := range rows {
+            key := keyOf(row[column])
+            byKey[key] = append(byKey[key], row)
+        }
+
+        results := make([]interface{}, len(parents))
+        for i, p := range parents {
+            results[i] = []interface{}{}
+            if value := p.(map[string]interface{})[refColumn]; value != nil {
+                if children, ok := byKey[keyOf(value)]; ok {
+                    results[i] = children
+                }
+            }
+        }
+        return results, nil
+    }
+}
+
+// loadByKeys selects the rows of a table whose column matches any of the keys,
+// in chunks of relationBatchSize, applying optional filter, ordering and per-key pagination
+func (b *builder) loadByKeys(t *table, column string, keys []interface{}, args map[string]interface{}, orderBy interface{}) ([]map[string]interface{}, error) {
+    var results []map[string]interface{}
+    for start := 0; start < len(keys); start += relationBatchSize {
+        end := start + relationBatchSize
+        if end > len(keys) {
+            end = len(keys)
+        }
+        chunk := keys[start:end]
+
+        params := append([]interface{}{}, chunk...)
+        where := quote(column) + " IN (" + placeholders(len(chunk)) + ")"
+        if filter := buildFilter(args["where"], &params); filter != "" {
+            where += " AND (" + filter + ")"
+        }
+        order := buildOrderBy(orderBy)
+
+        query := "SELECT * FROM " + quote(t.name) + " WHERE " + where + order
+        if args["limit"] != nil || args["offset"] != nil {
+            // Number the rows of each key so limit and offset apply per parent
+            over := "PARTITION BY " + quote(column) + order
+            query = "SELECT * FROM (SELECT *, ROW_NUMBER() OVER (" + over + ") AS __row FROM " + quote(t.name) + " WHERE " + where + ") WHERE __row > ?"
+            offset, _ := args["offset"].(int64)
+            params = append(params, offset)
+            if limit, ok := args["limit"].(int64); ok {
+                query += " AND __row <= ?"
+                params = append(params, offset+limit)
+            }
+            query += " ORDER BY __row"
+        }
+
+        rows, err := b.db.QueryRows(query, params...)
+        if err != nil {
+            return nil, err
+        }
+        results = append(results, rows...)
+    }
+    return results, nil
+}
+
+// resolveInsert inserts one row and returns it
+func (b *builder) resolveInsert(t *table) ResolveFunc {
+    return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+        object := args["object"].(map[string]interface{})
+
+        var columns []string
+        var params []interface{}
+        for _, column := range t.columns {
+            if value, ok := object[column.Name]; ok {
+                columns = append(columns, quote(column.Name))
+                params = append(params, value)
+            }
+        }
+
+        query := "INSERT INTO " + quote(t.name) + " DEFAULT VALUES RETURNING *"
+        if len(columns) > 0 {
+            query = "INSERT INTO " + quote(t.name) + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders(len(columns)) + ") RETURNING *"
+        }
+        rows, err := b.db.QueryRows(query, params...)
+        if err != nil {
+            return nil, err
+        }
+        var row interface{}
+        if len(rows) > 0 {
+            row = rows[0]
+        }
+        return repeat(row, len(parents)), nil
+    }
+}
+
+// resolveUpdate updates the rows matching a filter and returns them
+func (b *builder) resolveUpdate(t *table) ResolveFunc {
+    return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+        set := args["set"].(map[string]interface{})
+
+        var assignments []string
+        var params []interface{}
+        for _, column := range t.columns {
+            if value, ok := set[column.Name]; ok {
+                assignments = append(assignments, quote(column.Name)+" = ?")
+                params = append(params, value)
+            }
+        }
+        if len(assignments) == 0 {
+            return nil, fmt.Errorf("set must contain at least one column")
+        }
+
+        where := buildFilter(args["where"], &params)
+        if where == "" {
+            return nil, errEmptyWhere
+        }
+        query := "UPDATE " + quote(t.name) + " SET " + strings.Join(assignments, ", ") + " WHERE " + where
+        return b.mutationResult(query+" RETURNING *", params, len(parents))
+    }
+}
+
+// errEmptyWhere keeps a mutation with where: {} from changing every row of a table
+var errEmptyWhere = errors.New("where must contain at least one condition")
+
+// resolveDelete deletes the rows matching a filter and returns them
+func (b *builder) resolveDelete(t *table) ResolveFunc {
+    return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+        var params []interface{}
+        where := buildFilter(args["where"], &params)
+        if where == "" {
+            return nil, errEmptyWhere
+        }
+        query := "DELETE FROM " + quote(t.name) + " WHERE " + where
+        return b.mutationResult(query+" RETURNING *", params, len(parents))
+    }
+}
+
+// mutationResult runs an UPDATE or DELETE ... RETURNING and wraps the rows it returned
+func (b *builder) mutationResult(query string, params []interface{}, count int) ([]interface{}, error) {
+    rows, err := b.db.QueryRows(query, params...)
+    if err != nil {
+        return nil, err
+    }
+    result := map[string]interface{}{
+        "affected_rows": int64(len(rows)),
+        "returning":     rowList(rows),
+    }
+    return repeat(result, count), nil
+}
+
+// buildFilter translates a Filter input into a SQL condition, appending bound values to params
+// Returns an empty string when the filter places no condition
+func buildFilter(input interface{}, params *[]interface{}) string {
+    filter, ok := input.(map[string]interface{})
+    if !ok {
+        return ""
+    }
+
+    var conditions []string
+    for _, key := range sortedKeys(filter) {
+        value := filter[key]
+        switch key {
+        case "_and", "_or":
+            list, _ := value.([]interface{})
+            var parts []string
+            for _, item := range list {
+                if part := buildFilter(item, params); part != "" {
+                    parts = append(parts, "("+part+")")
+                }
+            }
+            separator := " AND "
+            if key == "_or" {
+                separator = " OR "
+            }
+            if len(parts) > 0 {
+                conditions = append(conditions, "("+strings.Join(parts, separator)+")")
+            }
+        case "_not":
+            if part := buildFilter(value, params); part != "" {
+                conditions = append(conditions, "NOT ("+part+")")
+            }
+        default:
+            comparison, ok := value.(map[string]interface{})
+            if !ok {
+                continue
+            }
+            conditions = append(conditions, buildComparison(quote(key), comparison, params)...)
+        }
+    }
+    return strings.Join(conditions, " AND ")
+}
+
+// comparisonOperators maps comparison input fields to SQL operators
+var comparisonOperators = map[string]string{
+    "eq":    "=",
+    "neq":   "<>",
+    "gt":    ">",
+    "gte":   ">=",
+    "lt":    "<",
+    "lte":   "<=",
+    "like":  "LIKE",
+    "nlike": "NOT LIKE",
+}
+
+// buildComparison translates the operators of one column comparison into SQL conditions
+func buildComparison(column string, comparison map[string]interface{}, params *[]interface{}) []string {
+    var conditions []string
+    for _, op := range sortedKeys(comparison) {
+        value := comparison[op]
+        switch op {
+        case "in", "nin":
+            list, _ := value.([]interface{})
+            switch {
+            case value == nil:
+                continue
+            case len(list) == 0 && op == "in":
+                conditions = append(conditions, "0")
+            case len(list) == 0:
+                continue
+            default:
+                keyword := " IN ("
+                if op == "nin" {
+                    keyword = " NOT IN ("
+                }
+                conditions = append(conditions, column+keyword+placeholders(len(list))+")")
+                *params = append(*params, list...)
+            }
+        case "is_null":
+            if isNull, ok := value.(bool); ok {
+                if isNull {
+                    conditions = append(conditions, column+" IS NULL")
+                } else {
+                    conditions = append(conditions, column+" IS NOT NULL")
+                }
+            }
+        default:
+            sqlOp := comparisonOperators[op]
+            switch {
+            case value != nil:
+                conditions = append(conditions, column+" "+sqlOp+" ?")
+                *params = append(*params, value)
+            case op == "eq":
+                conditions = append(conditions, column+" IS NULL")
+            case op == "neq":
+                conditions = append(conditions, column+" IS NOT NULL")
+            }
+        }
+    }
+    return conditions
+}
+
+// buildOrderBy translates an order_by argument into an ORDER BY clause
+func buildOrderBy(input interface{}) string {
+    list, _ := input.([]interface{})
+    var terms []string
+    for _, item := range list {
+        order, ok := item.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        column, _ := order["column"].(string)
+        direction, _ := order["direction"].(string)
+        if direction != "DESC" {
+            direction = "ASC"
+        }
+        terms = append(terms, quote(column)+" "+direction)
+    }
+    if len(terms) == 0 {
+        return ""
+    }
+    return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// buildPagination translates limit and offset arguments into a LIMIT clause
+func buildPagination(args map[string]interface{}, params *[]interface{}) string {
+    limit, hasLimit := args["limit"].(int64)
+    offset, hasOffset := args["offset"].(int64)
+    switch {
+    case hasLimit && hasOffset:
+        *params = append(*params, limit, offset)
+        return " LIMIT ? OFFSET ?"
+    case hasLimit:
+        *params = append(*params, limit)
+        return " LIMIT ?"
+    case hasOffset:
+        *params = append(*params, offset)
+        return " LIMIT -1 OFFSET ?"
+    }
+    return ""
+}
+
+// columnValues collects the distinct non-null values of a column across parent rows
+func columnValues(parents []interface{}, column string) []interface{} {
+    seen := make(map[string]bool)
+    var values []interface{}
+    for _, p := range parents {
+        value := p.(map[string]interface{})[column]
+        if value == nil || seen[keyOf(value)] {
+            continue
+        }
+        seen[keyOf(value)] = true
+        values = append(values, value)
+    }
+    return values
+}
+
+// keyOf normalizes a column value for matching rows across tables
+func keyOf(value interface{}) string {
+    return fmt.Sprint(value)
+}
+
+// scalarType maps a declared SQLite column type to a GraphQL scalar
+func scalarType(sqliteType string) string {
+    switch database.JSONType(sqliteType) {
+    case "integer":
+        return "Int"
+    case "number":
+        return "Float"
+    case "boolean":
+        return "Boolean"
+    default:
+        return "String"
+    }
+}
+
+// rowList converts query rows to a list value
+func rowList(rows []map[string]interface{}) []interface{} {
+    list := make([]interface{}, len(rows))
+    for i, row := range rows {
+        list[i] = row
+    }
+    return list
+}
+
+// repeat returns a slice holding value count times, one per parent
+func repeat(value interface{}, count int) []interface{} {
+    values := make([]interface{}, count)
+    for i := range values {
+        values[i] = value
+    }
+    return values
+}
+
+// placeholders returns n comma separated ? markers
+func placeholders(n int) string {
+    return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// quote quotes an identifier for use in SQL
+func quote(name string) string {
+    return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sortedKeys returns the keys of a map in sorted order so generated SQL is deterministic
+func sortedKeys(m map[string]interface{}) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// isName reports whether s is a valid GraphQL name that is not reserved for introspection
+func isName(s string) bool {
+    if s == "" || strings.HasPrefix(s, "__") {
+        return false
+    }
+    for i, c := range s {
+        if !(c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || i > 0 && c >= '0' && c <= '9') {
+            return false
+        }
+    }
+    return true
+}
+
+// pascal converts a snake_case table name to a PascalCase type name
+func pascal(s string) string {
+    var b strings.Builder
+    for _, part := range strings.Split(s, "_") {
+        if part == "" {
+            continue
+        }
+        b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+    }
+    if b.Len() == 0 {
+        return "Table"
+    }
+    return b.String()
+}
@@ -0,0 +1,106 @@
+// logging.go
+package logging
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "log/slog"
+    "sort"
+    "strings"
+)
+
+// Redacted replaces the value of attributes whose key is on the redaction list
+const Redacted = "[REDACTED]"
+
+// Options configures the logger built by New
+type Options struct {
+    Level  string    // debug, info, warn or error
+    Format string    // text or json
+    Redact []string  // Attribute keys whose values are hidden; matched case-insensitively as substrings
+    Output io.Writer // Destination of log records
+}
+
+// New builds a leveled logger that hides the values of redacted attributes
+func New(opts Options) (*slog.Logger, error) {
+    var level slog.Level
+    if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+        return nil, fmt.Errorf("invalid log level %q: use debug, info, warn or error", opts.Level)
+    }
+
+    redact := make([]string, 0, len(opts.Redact))
+    for _, name := range opts.Redact {
+        if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+            redact = append(redact, name)
+        }
+    }
+    handlerOpts := &slog.HandlerOptions{
+        Level: level,
+        ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+            if a.Value.Kind() != slog.KindGroup && IsRedacted(a.Key, redact) {
+                return slog.String(a.Key, Redacted)
+            }
+            return a
+        },
+    }
+
+    switch strings.ToLower(opts.Format) {
+    case "", "text":
+        return slog.New(slog.NewTextHandler(opts.Output, handlerOpts)), nil
+    case "json":
+        return slog.New(slog.NewJSONHandler(opts.Output, handlerOpts)), nil
+    default:
+        return nil, fmt.Errorf("invalid log format %q: use text or json", opts.Format)
+    }
+}
+
+// IsRedacted reports whether key contains any of the lower case names on the redaction list
+func IsRedacted(key string, redact []string) bool {
+    key = strings.ToLower(key)
+    for _, name := range redact {
+        if strings.Contains(key, name) {
+            return true
+        }
+    }
+    return false
+}
+
+// Params groups request parameters under key, one attribute per parameter in name order,
+// so each value is subject to redaction by its own name
+func Params(key string, params map[string]interface{}) slog.Attr {
+    names := make([]string, 0, len(params))
+    for name := range params {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    attrs := make([]any, 0, len(names))
+    for _, name := range names {
+        attrs = append(attrs, slog.Any(name, params[name]))
+    }
+    return slog.Group(key, attrs...)
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying a request-scoped logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+    return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger, or the default logger outside a request
+func FromContext(ctx context.Context) *slog.Logger {
+    if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+        return logger
+    }
+    return slog.Default()
+}
+
+// NewRequestID returns a random identifier for correlating a request's log records
+func NewRequestID() string {
+    b := make([]byte, 8)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
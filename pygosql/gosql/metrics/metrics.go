@@ -0,0 +1,207 @@
+// metrics.go
+package metrics
+
+import (
+    "fmt"
+    "io"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// DefaultBuckets are latency histogram upper bounds in seconds
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// CounterVec is a family of counters partitioned by label values
+type CounterVec struct {
+    name   string              // Metric name
+    help   string              // HELP text
+    labels []string            // Label names
+    mu     sync.Mutex          // Guards values
+    values map[string]*counter // Counters by joined label values
+}
+
+type counter struct {
+    labels []string
+    value  float64
+}
+
+// NewCounterVec creates a counter family with the given label names
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+    return &CounterVec{name: name, help: help, labels: labels, values: make(map[string]*counter)}
+}
+
+// Add increases the counter for the label values by delta
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+    key := strings.Join(labelValues, "\xff")
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    entry, ok := c.values[key]
+    if !ok {
+        entry = &counter{labels: append([]string(nil), labelValues...)}
+        c.values[key] = entry
+    }
+    entry.value += delta
+}
+
+// Inc increases the counter for the label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+    c.Add(1, labelValues...)
+}
+
+// Value returns the counter for the label values, 0 when it was never incremented
+func (c *CounterVec) Value(labelValues ...string) float64 {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if entry, ok := c.values[strings.Join(labelValues, "\xff")]; ok {
+        return entry.value
+    }
+    return 0
+}
+
+// Write writes the family in the Prometheus text exposition format
+func (c *CounterVec) Write(w io.Writer) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    WriteHeader(w, c.name, c.help, "counter")
+    for _, key := range sortedKeys(c.values) {
+        entry := c.values[key]
+        fmt.Fprintf(w, "%s%s %s\n", c.name, Labels(c.labels, entry.labels), FormatValue(entry.value))
+    }
+}
+
+// HistogramVec is a family of histograms partitioned by label values
+type HistogramVec struct {
+    name    string                // Metric name
+    help    string                // HELP text
+    labels  []string              // Label names
+    buckets []float64             // Sorted bucket upper bounds
+    mu      sync.Mutex            // Guards series
+    series  map[string]*histogram // Histograms by joined label values
+}
+
+type histogram struct {
+    labels []string
+    counts []uint64 // Observations per bucket, not cumulative
+    count  uint64
+    sum    float64
+}
+
+// NewHistogramVec creates a histogram family with the given buckets and label names
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+    buckets = append([]float64(nil), buckets...)
+    sort.Float64s(buckets)
+    return &HistogramVec{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogram)}
+}
+
+// Observe records a value for the label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+    key := strings.Join(labelValues, "\xff")
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    entry, ok := h.series[key]
+    if !ok {
+        entry = &histogram{labels: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+        h.series[key] = entry
+    }
+    if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+        entry.counts[i]++
+    }
+    entry.count++
+    entry.sum += value
+}
+
+// Count returns the number of observations for the label values
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if entry, ok := h.series[strings.Join(labelValues, "\xff")]; ok {
+        return entry.count
+    }
+    return 0
+}
+
+// Write writes the family in the Prometheus text exposition format
+func (h *HistogramVec) Write(w io.Writer) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    WriteHeader(w, h.name, h.help, "histogram")
+    bucketLabels := append(append([]string(nil), h.labels...), "le")
+    for _, key := range sortedKeys(h.series) {
+        entry := h.series[key]
+        var cumulative uint64
+        for i, bound := range h.buckets {
+            cumulative += entry.counts[i]
+            fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, Labels(bucketLabels, append(entry.labels, FormatValue(bound))), cumulative)
+        }
+        fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, Labels(bucketLabels, append(entry.labels, "+Inf")), entry.count)
+        fmt.Fprintf(w, "%s_sum%s %s\n", h.name, Labels(h.labels, entry.labels), FormatValue(entry.sum))
+        fmt.Fprintf(w, "%s_count%s %d\n", h.name, Labels(h.labels, entry.labels), entry.count)
+    }
+}
+
+// WriteHeader writes the HELP and TYPE lines of a metric family
+func WriteHeader(w io.Writer, name, help, kind string) {
+    fmt.Fprintf(w, "# HELP %s %s\n", name, strings.ReplaceAll(help, "\n", " "))
+    fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// WriteSample writes a single unlabelled family, for values read when the metrics are scraped
+func WriteSample(w io.Writer, name, help, kind string, value float64) {
+    WriteHeader(w, name, help, kind)
+    fmt.Fprintf(w, "%s %s\n", name, FormatValue(value))
+}
+
+// Labels formats label names and values as {name="value",...}, or "" when there are none
+func Labels(names, values []string) string {
+    if len(names) == 0 {
+        return ""
+    }
+    var b strings.Builder
+    b.WriteByte('{')
+    for i, name := range names {
+        if i > 0 {
+            b.WriteByte(',')
+        }
+        value := ""
+        if i < len(values) {
+            value = values[i]
+        }
+        b.WriteString(name)
+        b.WriteString(`="`)
+        b.WriteString(escapeLabel(value))
+        b.WriteByte('"')
+    }
+    b.WriteByte('}')
+    return b.String()
+}
+
+// FormatValue formats a sample value the way Prometheus expects
+func FormatValue(value float64) string {
+    switch {
+    case math.IsInf(value, 1):
+        return "+Inf"
+    case math.IsInf(value, -1):
+        return "-Inf"
+    case math.IsNaN(value):
+        return "NaN"
+    }
+    return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+    return labelEscaper.Replace(value)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
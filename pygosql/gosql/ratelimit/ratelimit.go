@@ -0,0 +1,196 @@
+// ratelimit.go
+package ratelimit
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Limit is a token bucket refilled at Rate tokens per second holding at most Burst tokens
+type Limit struct {
+    Rate   float64       // Tokens added per second
+    Burst  int           // Bucket capacity
+    Window time.Duration // Unit the limit was declared in, reported in RateLimit-Policy
+}
+
+// Unlimited reports whether the limit disables rate limiting
+func (l Limit) Unlimited() bool {
+    return l.Rate <= 0
+}
+
+// String renders the limit in the syntax accepted by ParseLimit
+func (l Limit) String() string {
+    if l.Unlimited() {
+        return "none"
+    }
+    count := l.Rate * l.Window.Seconds()
+    unit := map[time.Duration]string{time.Second: "s", time.Minute: "m", time.Hour: "h"}[l.Window]
+    return fmt.Sprintf("%s/%s burst %d", strconv.FormatFloat(count, 'f', -1, 64), unit, l.Burst)
+}
+
+// ParseLimit parses limits such as "10/s", "600/m burst 50" or "none"
+// The burst defaults to the number of requests per unit
+func ParseLimit(spec string) (Limit, error) {
+    fields := strings.Fields(strings.ToLower(spec))
+    if len(fields) == 0 || fields[0] == "none" || fields[0] == "off" {
+        return Limit{}, nil
+    }
+
+    count, unit, ok := strings.Cut(fields[0], "/")
+    n, err := strconv.ParseFloat(count, 64)
+    if !ok || err != nil || n <= 0 {
+        return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<s|m|h>", spec)
+    }
+
+    var window time.Duration
+    switch unit {
+    case "s", "sec", "second":
+        window = time.Second
+    case "m", "min", "minute":
+        window = time.Minute
+    case "h", "hour":
+        window = time.Hour
+    default:
+        return Limit{}, fmt.Errorf("invalid rate limit %q: unit must be s, m or h", spec)
+    }
+
+    limit := Limit{Rate: n / window.Seconds(), Burst: int(math.Ceil(n)), Window: window}
+    switch {
+    case len(fields) == 1:
+    case len(fields) == 3 && fields[1] == "burst":
+        burst, err := strconv.Atoi(fields[2])
+        if err != nil || burst < 1 {
+            return Limit{}, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", spec)
+        }
+        limit.Burst = burst
+    default:
+        return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<unit> [burst <n>]", spec)
+    }
+    return limit, nil
+}
+
+// Decision is the outcome of taking a token, with the values for RateLimit-* headers
+type Decision struct {
+    Allowed    bool          // Whether the request may proceed
+    Limit      int           // Bucket capacity
+    Remaining  int           // Whole tokens left after this request
+    Reset      time.Duration // Time until the bucket is full again
+    RetryAfter time.Duration // Time until a token is available, zero when allowed
+}
+
+// bucket is the token state of one client
+type bucket struct {
+    tokens float64
+    last   time.Time
+    full   time.Time // When the bucket will be full again and can be forgotten
+}
+
+// Counts holds the number of allowed and limited requests for one scope
+type Counts struct {
+    Allowed uint64
+    Limited uint64
+}
+
+// Limiter keeps a token bucket per key
+type Limiter struct {
+    mu        sync.Mutex
+    buckets   map[string]*bucket
+    counts    map[string]*Counts
+    lastSweep time.Time
+    now       func() time.Time
+}
+
+// NewLimiter creates an empty limiter
+func NewLimiter() *Limiter {
+    return &Limiter{
+        buckets: make(map[string]*bucket),
+        counts:  make(map[string]*Counts),
+        now:     time.Now,
+    }
+}
+
+// Take removes a token from the bucket of key under the given limit
+// scope groups the allowed and limited counts reported by Stats, e.g. by endpoint
+func (l *Limiter) Take(scope, key string, limit Limit) Decision {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    now := l.now()
+    l.sweep(now)
+
+    b, ok := l.buckets[key]
+    if !ok {
+        b = &bucket{tokens: float64(limit.Burst), last: now}
+        l.buckets[key] = b
+    }
+
+    // Refill for the time elapsed since the last request
+    b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+    b.last = now
+
+    counts, ok := l.counts[scope]
+    if !ok {
+        counts = &Counts{}
+        l.counts[scope] = counts
+    }
+
+    decision := Decision{Limit: limit.Burst}
+    if b.tokens >= 1 {
+        b.tokens--
+        decision.Allowed = true
+        counts.Allowed++
+    } else {
+        decision.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+        counts.Limited++
+    }
+    decision.Remaining = int(b.tokens)
+    decision.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+    b.full = now.Add(decision.Reset)
+    return decision
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute
+// A forgotten bucket starts full again, so dropping it does not change any decision
+func (l *Limiter) sweep(now time.Time) {
+    if now.Sub(l.lastSweep) < time.Minute {
+        return
+    }
+    l.lastSweep = now
+    for key, b := range l.buckets {
+        if now.After(b.full) {
+            delete(l.buckets, key)
+        }
+    }
+}
+
+// Stats returns the allowed and limited counts per scope, and the number of tracked clients
+func (l *Limiter) Stats() (map[string]Counts, int) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    counts := make(map[string]Counts, len(l.counts))
+    for scope, c := range l.counts {
+        counts[scope] = *c
+    }
+    return counts, len(l.buckets)
+}
+
+// Scopes returns the scopes in sorted order
+func Scopes(counts map[string]Counts) []string {
+    scopes := make([]string, 0, len(counts))
+    for scope := range counts {
+        scopes = append(scopes, scope)
+    }
+    sort.Strings(scopes)
+    return scopes
+}
+
+// seconds converts fractional seconds to a duration
+func seconds(s float64) time.Duration {
+    return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,172 @@
+// access.go
+package server
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "os"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// Access log formats
+const (
+    AccessLogCommon   = "common"
+    AccessLogCombined = "combined"
+    AccessLogJSON     = "json"
+)
+
+// AccessLogger writes one line per request to a sink in Common, Combined or JSON format
+type AccessLogger struct {
+    mu     sync.Mutex // Serializes lines from concurrent requests
+    out    io.Writer  // Sink the lines are written to
+    closer io.Closer  // Closes the sink when it is a file, nil otherwise
+    format string     // One of the AccessLog* formats
+}
+
+// AccessEntry is a finished request as recorded in the access log
+type AccessEntry struct {
+    Time       time.Time `json:"time"`
+    RequestID  string    `json:"request_id"`
+    Client     string    `json:"client"`
+    User       string    `json:"user,omitempty"`
+    Method     string    `json:"method"`
+    Path       string    `json:"path"`
+    Route      string    `json:"route,omitempty"`
+    Protocol   string    `json:"protocol"`
+    Status     int       `json:"status"`
+    Bytes      int64     `json:"bytes"`
+    DurationMS float64   `json:"duration_ms"`
+    Rows       int       `json:"rows"`
+    Referer    string    `json:"referer,omitempty"`
+    UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// NewAccessLogger opens the access log sink: stderr, stdout or a file path that is
+// appended to; "-" means stderr, as stdout carries the ready line. It returns nil when
+// sink is empty, which disables the access log
+func NewAccessLogger(sink, format string) (*AccessLogger, error) {
+    switch format {
+    case "":
+        format = AccessLogCommon
+    case AccessLogCommon, AccessLogCombined, AccessLogJSON:
+    default:
+        return nil, fmt.Errorf("unknown access log format %q: use common, combined or json", format)
+    }
+
+    logger := &AccessLogger{format: format}
+    switch sink {
+    case "", "none":
+        return nil, nil
+    case "stderr", "-":
+        logger.out = os.Stderr
+    case "stdout":
+        logger.out = os.Stdout
+    default:
+        file, err := os.OpenFile(sink, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+        if err != nil {
+            return nil, fmt.Errorf("failed to open access log: %w", err)
+        }
+        logger.out, logger.closer = file, file
+    }
+    return logger, nil
+}
+
+// Middleware records every request passing through next; on a nil logger it returns
+// next unchanged
+// It must run inside RequestLogger so the response already carries the request id
+func (a *AccessLogger) Middleware(next http.Handler) http.Handler {
+    if a == nil {
+        return next
+    }
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        start := time.Now()
+        stats := statsFrom(r.Context())
+        if stats == nil {
+            stats = newRequestStats()
+            r = r.WithContext(withStats(r.Context(), stats))
+        }
+        recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+        next.ServeHTTP(recorder, r)
+
+        a.Log(AccessEntry{
+            Time:       start,
+            RequestID:  w.Header().Get(RequestIDHeader),
+            Client:     clientHost(r),
+            User:       stats.user,
+            Method:     r.Method,
+            Path:       r.URL.Path, // Query strings may carry api_key or tokens
+            Route:      stats.route,
+            Protocol:   r.Proto,
+            Status:     recorder.status,
+            Bytes:      recorder.bytes,
+            DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+            Rows:       stats.rows,
+            Referer:    r.Referer(),
+            UserAgent:  r.UserAgent(),
+        })
+    })
+}
+
+// Log writes one entry in the logger's format
+func (a *AccessLogger) Log(entry AccessEntry) {
+    var line []byte
+    if a.format == AccessLogJSON {
+        line, _ = json.Marshal(entry)
+    } else {
+        line = []byte(formatCommon(entry, a.format == AccessLogCombined))
+    }
+    line = append(line, '\n')
+
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    a.out.Write(line)
+}
+
+// Close closes a file sink; it does nothing on a nil logger or a standard stream
+func (a *AccessLogger) Close() error {
+    if a == nil || a.closer == nil {
+        return nil
+    }
+    return a.closer.Close()
+}
+
+// formatCommon renders the NCSA Common or Combined log format, followed by the
+// request id, route, duration and row count as key=value fields that log parsers
+// matching the standard prefix ignore
+func formatCommon(entry AccessEntry, combined bool) string {
+    line := fmt.Sprintf("%s - %s [%s] %s %d %d",
+        entry.Client,
+        orDash(entry.User),
+        entry.Time.Format("02/Jan/2006:15:04:05 -0700"),
+        strconv.Quote(entry.Method+" "+entry.Path+" "+entry.Protocol),
+        entry.Status,
+        entry.Bytes,
+    )
+    if combined {
+        line += fmt.Sprintf(" %s %s", strconv.Quote(orDash(entry.Referer)), strconv.Quote(orDash(entry.UserAgent)))
+    }
+    return line + fmt.Sprintf(" request_id=%s route=%s duration_ms=%.3f rows=%d",
+        orDash(entry.RequestID), orDash(entry.Route), entry.DurationMS, entry.Rows)
+}
+
+// clientHost returns the remote IP, or "-" for Unix socket peers
+func clientHost(r *http.Request) string {
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        host = r.RemoteAddr
+    }
+    return orDash(host)
+}
+
+// orDash substitutes "-" for an empty log field
+func orDash(value string) string {
+    if value == "" || value == "@" {
+        return "-"
+    }
+    return value
+}
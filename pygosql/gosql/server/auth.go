@@ -0,0 +1,128 @@
+// auth.go
+package server
+
+import (
+    "errors"
+    "fmt"
+    "gosql/auth"
+    "gosql/database"
+    "gosql/logging"
+    "gosql/setup"
+    "net/http"
+    "strings"
+)
+
+// NewAuthenticator builds the authenticator for the configured key sources:
+// the key file when one is set, then the gosql_keys table, plus JWT verification
+// when a JWT key file or secret is configured
+func NewAuthenticator(cfg setup.Config, db *database.Database) (*auth.Authenticator, error) {
+    var stores auth.MultiStore
+    if cfg.AuthKeysFile != "" {
+        fileStore, err := auth.LoadKeyFile(cfg.AuthKeysFile)
+        if err != nil {
+            return nil, err
+        }
+        stores = append(stores, fileStore)
+    }
+    if db != nil {
+        stores = append(stores, &auth.TableStore{DB: db})
+    }
+    authenticator := auth.NewAuthenticator(stores)
+
+    var jwtKeys []auth.JWTKey
+    if cfg.JWTKeyFile != "" {
+        keys, err := auth.LoadJWTKeys(cfg.JWTKeyFile)
+        if err != nil {
+            return nil, err
+        }
+        jwtKeys = append(jwtKeys, keys...)
+    }
+    if cfg.JWTSecret != "" {
+        jwtKeys = append(jwtKeys, auth.JWTKey{Key: []byte(cfg.JWTSecret)})
+    }
+    if len(jwtKeys) > 0 {
+        authenticator.JWT = auth.NewJWTVerifier(jwtKeys, cfg.JWTIssuer, cfg.JWTAudience)
+        authenticator.JWT.AllowNoExp = cfg.JWTAllowNoExp
+    }
+    return authenticator, nil
+}
+
+// IsAuthExempt reports whether a system route is served without credentials
+func IsAuthExempt(cfg setup.Config, path string) bool {
+    for _, exempt := range cfg.AuthExempt {
+        if strings.TrimSpace(exempt) == path {
+            return true
+        }
+    }
+    return false
+}
+
+// authorize authenticates the request and checks the required scopes
+// On success it returns the request with the principal in its context; otherwise it
+// writes a 401 or 403 response and returns false
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, scopes []string) (*http.Request, bool) {
+    if s.auth == nil {
+        return r, true
+    }
+
+    principal, err := s.auth.Authenticate(r)
+    if err != nil {
+        status := http.StatusUnauthorized
+        message := err.Error()
+        if !errors.Is(err, auth.ErrMissingCredentials) && !errors.Is(err, auth.ErrInvalidCredentials) {
+            logging.FromContext(r.Context()).Error("authentication failed", "error", err)
+            status = http.StatusInternalServerError
+            message = "authentication unavailable"
+        } else {
+            w.Header().Set("WWW-Authenticate", `Bearer realm="gosql"`)
+        }
+        s.WriteJSONResponse(w, status, map[string]interface{}{
+            "success": false,
+            "error":   message,
+        })
+        return nil, false
+    }
+
+    if missing := principal.MissingScopes(scopes); len(missing) > 0 {
+        s.WriteJSONResponse(w, http.StatusForbidden, map[string]interface{}{
+            "success": false,
+            "error":   fmt.Sprintf("key %q lacks required scope: %s", principal.Name, strings.Join(missing, ", ")),
+        })
+        return nil, false
+    }
+
+    if stats := statsFrom(r.Context()); stats != nil {
+        stats.user = principal.Name
+    }
+    return r.WithContext(auth.WithPrincipal(r.Context(), principal)), true
+}
+
+// requireAuth protects a system endpoint unless it is listed as exempt
+// Preflight requests pass through so browsers can discover the CORS policy
+func (s *Server) requireAuth(path string, handler http.HandlerFunc) http.HandlerFunc {
+    if s.auth == nil || IsAuthExempt(s.config, path) {
+        return handler
+    }
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != "OPTIONS" {
+            var ok bool
+            if r, ok = s.authorize(w, r, nil); !ok {
+                return
+            }
+        }
+        handler(w, r)
+    }
+}
+
+// authorizeGraphQL requires the write scope for GraphQL mutations; the read scope was
+// checked when the request was authenticated
+func (s *Server) authorizeGraphQL(r *http.Request, operation string) (int, error) {
+    principal := auth.FromContext(r.Context())
+    if operation == "query" || principal == nil {
+        return http.StatusOK, nil
+    }
+    if missing := principal.MissingScopes([]string{s.config.GraphQLWrite}); len(missing) > 0 {
+        return http.StatusForbidden, fmt.Errorf("key %q lacks required scope: %s", principal.Name, strings.Join(missing, ", "))
+    }
+    return http.StatusOK, nil
+}
@@ -0,0 +1,153 @@
+// auth_test.go
+package server
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "gosql/database"
+    "gosql/setup"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// testJWTSecret signs the tokens of these tests
+const testJWTSecret = "test-secret"
+
+// hs256Token returns a token with the given claims signed with testJWTSecret
+func hs256Token(t *testing.T, claims map[string]interface{}) string {
+    t.Helper()
+    header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+    payload, err := json.Marshal(claims)
+    if err != nil {
+        t.Fatal(err)
+    }
+    signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+    mac := hmac.New(sha256.New, []byte(testJWTSecret))
+    mac.Write([]byte(signed))
+    return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newAuthServer serves GET /api/v1/whoami, which needs the read scope and returns the
+// caller's auth_sub and auth_role, behind JWT authentication
+func newAuthServer(t *testing.T) *Server {
+    t.Helper()
+    dir := t.TempDir()
+    db, err := database.NewDatabase(database.Config{Path: filepath.Join(dir, "app.db"), CreateIfNotExists: true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    sqlPath := filepath.Join(dir, "sql", "GET", "whoami.sql")
+    if err := os.MkdirAll(filepath.Dir(sqlPath), 0755); err != nil {
+        t.Fatal(err)
+    }
+    sql := "-- @scope read\nSELECT :auth_sub AS sub, :auth_role AS role;\n"
+    if err := os.WriteFile(sqlPath, []byte(sql), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    cfg := setup.DefaultConfig()
+    cfg.DatabasePath = filepath.Join(dir, "app.db")
+    cfg.SQLRoot = filepath.Join(dir, "sql")
+    cfg.EnableAuth = true
+    cfg.JWTSecret = testJWTSecret
+    s, err := NewServer(cfg, []Endpoint{AssembleEndpoint(sqlPath, db, cfg.BaseURL)}, db)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return s
+}
+
+func TestAuthorizeRequests(t *testing.T) {
+    s := newAuthServer(t)
+    exp := float64(time.Now().Add(time.Hour).Unix())
+
+    tests := []struct {
+        name   string
+        claims map[string]interface{}
+        status int
+    }{
+        {"no credentials", nil, http.StatusUnauthorized},
+        {"valid token", map[string]interface{}{"sub": "alice", "scope": "read", "exp": exp}, http.StatusOK},
+        {"missing scope", map[string]interface{}{"sub": "alice", "scope": "write", "exp": exp}, http.StatusForbidden},
+        {"missing sub", map[string]interface{}{"scope": "read", "exp": exp}, http.StatusUnauthorized},
+        {"missing exp", map[string]interface{}{"sub": "alice", "scope": "read"}, http.StatusUnauthorized},
+        {"expired", map[string]interface{}{"sub": "alice", "scope": "read", "exp": float64(time.Now().Add(-time.Hour).Unix())}, http.StatusUnauthorized},
+    }
+    for _, test := range tests {
+        r := httptest.NewRequest(http.MethodGet, "/api/v1/whoami", nil)
+        if test.claims != nil {
+            r.Header.Set("Authorization", "Bearer "+hs256Token(t, test.claims))
+        }
+        w := httptest.NewRecorder()
+        s.Handler().ServeHTTP(w, r)
+        if w.Code != test.status {
+            t.Errorf("%s: status %d, want %d: %s", test.name, w.Code, test.status, w.Body.String())
+        }
+        if test.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+            t.Errorf("%s: 401 without a WWW-Authenticate header", test.name)
+        }
+    }
+}
+
+func TestClientCannotSetAuthParams(t *testing.T) {
+    s := newAuthServer(t)
+    token := hs256Token(t, map[string]interface{}{
+        "sub":   "alice",
+        "role":  "user",
+        "scope": "read",
+        "exp":   float64(time.Now().Add(time.Hour).Unix()),
+    })
+
+    for _, query := range []string{"?auth_sub=mallory&auth_role=admin", "?AUTH_SUB=mallory&Auth_Role=admin"} {
+        r := httptest.NewRequest(http.MethodGet, "/api/v1/whoami"+query, nil)
+        r.Header.Set("Authorization", "Bearer "+token)
+        w := httptest.NewRecorder()
+        s.Handler().ServeHTTP(w, r)
+        body := w.Body.String()
+        if w.Code != http.StatusOK || !strings.Contains(body, "alice") || !strings.Contains(body, `"user"`) {
+            t.Errorf("%s: status %d: %s", query, w.Code, body)
+        }
+        if strings.Contains(body, "mallory") || strings.Contains(body, "admin") {
+            t.Errorf("%s: client auth_ parameters reached the SQL: %s", query, body)
+        }
+    }
+}
+
+func TestReadyzDetailsNeedCredentials(t *testing.T) {
+    s := newAuthServer(t)
+    token := hs256Token(t, map[string]interface{}{"sub": "alice", "exp": float64(time.Now().Add(time.Hour).Unix())})
+
+    tests := []struct {
+        name    string
+        bearer  string
+        details bool
+    }{
+        {"no credentials", "", false},
+        {"invalid token", token + "x", false},
+        {"valid token", token, true},
+    }
+    for _, test := range tests {
+        r := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+        if test.bearer != "" {
+            r.Header.Set("Authorization", "Bearer "+test.bearer)
+        }
+        w := httptest.NewRecorder()
+        s.Handler().ServeHTTP(w, r)
+        var body map[string]interface{}
+        if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["status"] == nil {
+            t.Fatalf("%s: status %d: %s", test.name, w.Code, w.Body.String())
+        }
+        if _, ok := body["checks"]; ok != test.details {
+            t.Errorf("%s: checks reported = %v, want %v: %s", test.name, ok, test.details, w.Body.String())
+        }
+    }
+}
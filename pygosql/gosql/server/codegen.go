@@ -0,0 +1,88 @@
+// codegen.go
+package server
+
+import (
+    "gosql/codegen"
+    "gosql/setup"
+    "net/http"
+    "strings"
+)
+
+// NewCodegenSpec converts the registered endpoints into the model used by client generators
+// Fails when two endpoints would get the same function name in a namespace
+func NewCodegenSpec(cfg setup.Config, endpoints []Endpoint) (codegen.Spec, error) {
+    spec := codegen.Spec{
+        Version: APIVersion,
+        BaseURL: cfg.BaseURL,
+    }
+
+    for _, endpoint := range endpoints {
+        namespace := endpoint.TableName
+        if namespace == "" {
+            namespace = "system"
+        }
+
+        name := FunctionName(endpoint, cfg.BaseURL)
+        spec.Endpoints = append(spec.Endpoints, codegen.Endpoint{
+            Namespace:    namespace,
+            Name:         name,
+            Method:       endpoint.Method,
+            Path:         endpoint.Path,
+            Description:  endpoint.Description,
+            SQLPath:      endpoint.SQLPath,
+            Params:       endpoint.Params,
+            Columns:      endpoint.Columns,
+            Positional:   endpoint.Positional,
+            ReturnsRows:  endpoint.ReturnsRows,
+            AcceptsExtra: endpoint.BodyColumns,
+        })
+    }
+
+    if err := spec.Validate(); err != nil {
+        return codegen.Spec{}, err
+    }
+    return spec, nil
+}
+
+// FunctionName returns the client function name of an endpoint: its route below the base
+// URL and table, with the segments joined by underscores and path parameters unbraced
+// Example: "/api/v1/reports/daily" -> "reports_daily", "/api/v1/users/{id}/posts" -> "id_posts"
+func FunctionName(endpoint Endpoint, baseURL string) string {
+    segments := strings.Split(strings.Trim(strings.TrimPrefix(endpoint.Path, baseURL), "/"), "/")
+    if endpoint.TableName != "" && len(segments) > 1 && segments[0] == endpoint.TableName {
+        segments = segments[1:]
+    }
+    for i, segment := range segments {
+        segments[i] = strings.TrimSuffix(strings.Trim(segment, "{}"), "...")
+    }
+    return strings.ToLower(strings.Join(segments, "_"))
+}
+
+// CodegenPythonHandler serves the typed Python module generated from the current endpoints
+// Pass ?stub=1 to receive a .pyi stub instead of a runnable module
+func (s *Server) CodegenPythonHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "OPTIONS" {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    if r.Method != "GET" {
+        s.WriteJSONResponse(w, http.StatusMethodNotAllowed, map[string]interface{}{
+            "success": false,
+            "error":   "Only GET method allowed for code generation",
+        })
+        return
+    }
+
+    spec, err := NewCodegenSpec(s.config, s.Endpoints())
+    if err != nil {
+        s.WriteJSONResponse(w, http.StatusInternalServerError, map[string]interface{}{
+            "success": false,
+            "error":   err.Error(),
+        })
+        return
+    }
+    opts := codegen.PythonOptions{Stub: r.URL.Query().Get("stub") != ""}
+    w.Header().Set("Content-Type", "text/x-python; charset=utf-8")
+    w.Write([]byte(codegen.Python(spec, opts)))
+}
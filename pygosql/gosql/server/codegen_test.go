@@ -0,0 +1,50 @@
+// codegen_test.go
+package server
+
+import (
+    "gosql/setup"
+    "strings"
+    "testing"
+)
+
+func TestFunctionName(t *testing.T) {
+    tests := []struct {
+        path  string
+        table string
+        want  string
+    }{
+        {"/api/v1/users/select", "users", "select"},
+        {"/api/v1/users/reports/old", "users", "reports_old"},
+        {"/api/v1/users/{id}/posts", "users", "id_posts"},
+        {"/api/v1/reports/daily", "", "reports_daily"},
+        {"/api/v1/daily", "", "daily"},
+        {"/api/v1/files/{path...}", "", "files_path"},
+        {"/api/v1/Reports/Daily", "", "reports_daily"},
+    }
+    for _, test := range tests {
+        got := FunctionName(Endpoint{Path: test.path, TableName: test.table}, "/api/v1")
+        if got != test.want {
+            t.Errorf("FunctionName(%q, table %q) = %q, want %q", test.path, test.table, got, test.want)
+        }
+    }
+}
+
+func TestNewCodegenSpecKeepsNestedRoutes(t *testing.T) {
+    cfg := setup.Config{BaseURL: "/api/v1"}
+    endpoints := []Endpoint{
+        {Method: "GET", Path: "/api/v1/reports/daily", SQLPath: "sql/GET/reports/daily.sql"},
+        {Method: "POST", Path: "/api/v1/daily", SQLPath: "sql/POST/daily.sql"},
+    }
+    spec, err := NewCodegenSpec(cfg, endpoints)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(spec.Endpoints) != 2 || spec.Endpoints[0].Name != "reports_daily" || spec.Endpoints[1].Name != "daily" {
+        t.Errorf("unexpected endpoints: %+v", spec.Endpoints)
+    }
+
+    endpoints = append(endpoints, Endpoint{Method: "GET", Path: "/api/v1/reports_daily", SQLPath: "sql/GET/reports_daily.sql"})
+    if _, err := NewCodegenSpec(cfg, endpoints); err == nil || !strings.Contains(err.Error(), "system.reports_daily") {
+        t.Errorf("expected a collision error for system.reports_daily, got %v", err)
+    }
+}
@@ -0,0 +1,101 @@
+// cors.go
+package server
+
+import (
+    "gosql/setup"
+    "net/http"
+    "path"
+    "strconv"
+    "strings"
+)
+
+// CORSMiddleware applies a CORS policy to every response and answers preflight requests
+// Origins that the policy does not allow receive no CORS headers, so browsers block them
+func CORSMiddleware(policy setup.CORSPolicy, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        origin := r.Header.Get("Origin")
+        header := w.Header()
+        header.Add("Vary", "Origin")
+
+        preflight := r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != ""
+        if preflight {
+            header.Add("Vary", "Access-Control-Request-Method")
+            header.Add("Vary", "Access-Control-Request-Headers")
+        }
+
+        if origin == "" || !originAllowed(policy, origin) {
+            if preflight {
+                w.WriteHeader(http.StatusNoContent)
+                return
+            }
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        // A wildcard cannot be combined with credentials, so echo the origin instead;
+        // Config.Validate refuses that combination, but a policy set in code may have it
+        if policy.AllowsAnyOrigin() && !policy.AllowCredentials {
+            header.Set("Access-Control-Allow-Origin", "*")
+        } else {
+            header.Set("Access-Control-Allow-Origin", origin)
+        }
+        if policy.AllowCredentials {
+            header.Set("Access-Control-Allow-Credentials", "true")
+        }
+
+        if !preflight {
+            if len(policy.ExposedHeaders) > 0 {
+                header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+            }
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        if !containsFold(policy.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+            header.Del("Access-Control-Allow-Origin")
+            header.Del("Access-Control-Allow-Credentials")
+            w.WriteHeader(http.StatusNoContent)
+            return
+        }
+
+        header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+        if containsFold(policy.AllowedHeaders, "*") {
+            if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+                header.Set("Access-Control-Allow-Headers", requested)
+            }
+        } else if len(policy.AllowedHeaders) > 0 {
+            header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+        }
+        if policy.MaxAge > 0 {
+            header.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+        }
+        w.WriteHeader(http.StatusNoContent)
+    })
+}
+
+// originAllowed reports whether an origin matches the policy's exact origins or patterns
+func originAllowed(policy setup.CORSPolicy, origin string) bool {
+    origin = strings.ToLower(origin)
+    for _, allowed := range policy.AllowedOrigins {
+        allowed = strings.ToLower(strings.TrimSpace(allowed))
+        if allowed == "*" || allowed == origin {
+            return true
+        }
+        if strings.Contains(allowed, "*") {
+            if matched, _ := path.Match(allowed, origin); matched {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// containsFold reports whether list contains value, ignoring case
+func containsFold(list []string, value string) bool {
+    for _, item := range list {
+        if strings.EqualFold(strings.TrimSpace(item), value) {
+            return true
+        }
+    }
+    return false
+}
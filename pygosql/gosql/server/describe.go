@@ -0,0 +1,157 @@
+// describe.go
+package server
+
+import (
+    "gosql/auth"
+    "gosql/database"
+    "log/slog"
+    "strings"
+)
+
+// templateVariables are filled in by ProcessSQLTemplate rather than sent by the client
+var templateVariables = map[string]bool{
+    "table":   true,
+    "columns": true,
+    "values":  true,
+    "updates": true,
+}
+
+// IsTemplateVariable reports whether {{name}} is filled in by the server rather than
+// with the client's parameter of that name
+func IsTemplateVariable(name string) bool {
+    return templateVariables[name]
+}
+
+// DescribeEndpoint fills in the endpoint's description, parameters and result columns
+// from the SQL file's metadata header, its placeholders and database introspection
+func DescribeEndpoint(endpoint *Endpoint, db *database.Database) {
+    sqlFile, err := database.LoadSQL(endpoint.SQLPath)
+    if err != nil {
+        endpoint.LoadError = err.Error()
+        return
+    }
+    if sqlFile.IsEmpty() {
+        endpoint.LoadError = "SQL file is empty"
+        return
+    }
+
+    meta := database.ParseMetadata(sqlFile.Content)
+    placeholders := database.FindPlaceholders(sqlFile.Content)
+    endpoint.Description = meta.Description
+    for _, scopes := range meta.Tags["scope"] {
+        endpoint.Scopes = append(endpoint.Scopes, strings.Fields(scopes)...)
+    }
+    endpoint.RateLimit = meta.Tag("ratelimit")
+    endpoint.Positional = placeholders.Positional
+    endpoint.ReturnsRows = database.ReturnsRows(sqlFile.Content)
+
+    // Table columns provide types for parameters named after them
+    var tableColumns []database.Column
+    if endpoint.TableName != "" && db != nil {
+        tableColumns, _ = db.TableColumns(endpoint.TableName)
+    }
+    columnTypes := make(map[string]string)
+    for _, column := range tableColumns {
+        columnTypes[column.Name] = database.JSONType(column.Type)
+    }
+
+    pathParams := make(map[string]bool)
+    for _, name := range PathParamNames(endpoint.Path) {
+        pathParams[name] = true
+    }
+    location := func(name string) string {
+        switch {
+        case pathParams[name]:
+            return "path"
+        case endpoint.Method == "POST" || endpoint.Method == "PUT":
+            return "body"
+        default:
+            return "query"
+        }
+    }
+
+    seen := make(map[string]bool)
+    addParam := func(param database.Param) {
+        // auth_ parameters come from the caller's credentials, not the request
+        if seen[param.Name] || auth.IsReserved(param.Name) {
+            return
+        }
+        seen[param.Name] = true
+        if param.Type == "" {
+            param.Type = columnTypes[param.Name]
+        }
+        param.In = location(param.Name)
+        if param.In == "path" {
+            param.Required = true
+        }
+        endpoint.Params = append(endpoint.Params, param)
+    }
+
+    for _, param := range meta.Params {
+        addParam(param)
+    }
+    for _, name := range PathParamNames(endpoint.Path) {
+        addParam(database.Param{Name: name, Required: true})
+    }
+    for _, name := range placeholders.Named {
+        addParam(database.Param{Name: name, Required: true})
+    }
+    usesColumns := false
+    for _, name := range placeholders.Templates {
+        if templateVariables[name] {
+            usesColumns = usesColumns || name != "table"
+            continue
+        }
+        addParam(database.Param{Name: name, Required: true})
+    }
+
+    // {{columns}}/{{values}}/{{updates}} are generated from whatever columns the client sends
+    endpoint.BodyColumns = usesColumns
+    if usesColumns {
+        for _, column := range tableColumns {
+            if column.PrimaryKey && strings.Contains(strings.ToUpper(column.Type), "INT") {
+                continue
+            }
+            addParam(database.Param{
+                Name:     column.Name,
+                Required: column.NotNull && !column.HasDefault && endpoint.Method == "POST",
+            })
+        }
+    }
+
+    // Result columns come from running the statement in a rolled back transaction
+    query := strings.ReplaceAll(sqlFile.Content, "{{table}}", endpoint.TableName)
+    if db == nil || !endpoint.ReturnsRows || strings.Contains(query, "{{") {
+        return
+    }
+    columns, err := db.QueryColumns(query, placeholders)
+    if err != nil {
+        slog.Warn("could not describe result columns", "sql_file", endpoint.SQLPath, "error", err)
+        return
+    }
+    for i := range columns {
+        if columns[i].Type == "" {
+            for _, column := range tableColumns {
+                if column.Name == columns[i].Name {
+                    columns[i].Type = column.Type
+                    columns[i].NotNull = column.NotNull
+                }
+            }
+        }
+    }
+    endpoint.Columns = columns
+}
+
+// PathParamNames returns the wildcard names in a route such as "/api/v1/users/{id}"
+func PathParamNames(route string) []string {
+    var names []string
+    for _, segment := range strings.Split(route, "/") {
+        if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+            name := strings.TrimSuffix(strings.Trim(segment, "{}"), "...")
+            if name != "" && name != "$" {
+                names = append(names, name)
+            }
+        }
+    }
+    return names
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+// disk_unix.go
+package server
+
+import "syscall"
+
+// freeDiskSpace returns the bytes available to unprivileged users on the filesystem
+// holding dir
+func freeDiskSpace(dir string) (uint64, error) {
+    var stat syscall.Statfs_t
+    if err := syscall.Statfs(dir, &stat); err != nil {
+        return 0, err
+    }
+    return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+// disk_windows.go
+package server
+
+import (
+    "syscall"
+    "unsafe"
+)
+
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// freeDiskSpace returns the bytes available to the current user on the volume
+// holding dir
+func freeDiskSpace(dir string) (uint64, error) {
+    path, err := syscall.UTF16PtrFromString(dir)
+    if err != nil {
+        return 0, err
+    }
+    var available uint64
+    ok, _, err := getDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(&available)), 0, 0)
+    if ok == 0 {
+        return 0, err
+    }
+    return available, nil
+}
@@ -0,0 +1,163 @@
+// docs.go
+package server
+
+import (
+    _ "embed"
+    "encoding/json"
+    "gosql/database"
+    "gosql/logging"
+    "html/template"
+    "net/http"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+//go:embed docs.html
+var docsPage string
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsPage))
+
+// docsGroup holds the endpoints of one table (or the universal endpoints) on the HTML page
+type docsGroup struct {
+    Name      string
+    Endpoints []docsEndpoint
+}
+
+// docsEndpoint is the view model of one endpoint on the HTML page
+type docsEndpoint struct {
+    ID          string
+    Method      string
+    Path        string
+    Description string
+    SQLPath     string
+    SQL         string
+    Params      []database.Param
+    Example     string
+}
+
+// WantsHTML reports whether the client prefers an HTML response over JSON
+// Browsers list text/html explicitly, API clients usually send */* or application/json
+func WantsHTML(r *http.Request) bool {
+    htmlQ, jsonQ := -1.0, -1.0
+    for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+        mediaType, q := parseAcceptPart(part)
+        switch mediaType {
+        case "text/html":
+            htmlQ = max(htmlQ, q)
+        case "application/json":
+            jsonQ = max(jsonQ, q)
+        }
+    }
+    return htmlQ > 0 && htmlQ >= jsonQ
+}
+
+// parseAcceptPart splits an Accept header element into its media type and quality
+func parseAcceptPart(part string) (string, float64) {
+    fields := strings.Split(part, ";")
+    mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+    q := 1.0
+    for _, field := range fields[1:] {
+        key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+        if key == "q" {
+            if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+                q = parsed
+            }
+        }
+    }
+    return mediaType, q
+}
+
+// DocsHTMLHandler renders the browsable documentation page with a try-it console
+func (s *Server) DocsHTMLHandler(w http.ResponseWriter, r *http.Request) {
+    endpoints := s.Endpoints()
+    groups := make(map[string]*docsGroup)
+    for i, endpoint := range endpoints {
+        name := endpoint.TableName
+        if name == "" {
+            name = "universal"
+        }
+        group, ok := groups[name]
+        if !ok {
+            group = &docsGroup{Name: name}
+            groups[name] = group
+        }
+
+        sqlSource := ""
+        if sqlFile, err := database.LoadSQL(endpoint.SQLPath); err == nil {
+            sqlSource = sqlFile.Content
+        }
+
+        group.Endpoints = append(group.Endpoints, docsEndpoint{
+            ID:          "endpoint-" + strconv.Itoa(i),
+            Method:      endpoint.Method,
+            Path:        endpoint.Path,
+            Description: endpoint.Description,
+            SQLPath:     filepath.ToSlash(endpoint.SQLPath),
+            SQL:         sqlSource,
+            Params:      endpoint.Params,
+            Example:     exampleResponse(endpoint),
+        })
+    }
+
+    sorted := make([]*docsGroup, 0, len(groups))
+    for _, group := range groups {
+        sort.Slice(group.Endpoints, func(i, j int) bool { return group.Endpoints[i].Path < group.Endpoints[j].Path })
+        sorted = append(sorted, group)
+    }
+    sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+    data := map[string]interface{}{
+        "Version": APIVersion,
+        "BaseURL": s.config.BaseURL,
+        "Groups":  sorted,
+        "Total":   len(endpoints),
+        "System":  EnabledSystemRoutes(s.config),
+        "Auth":    s.config.EnableAuth,
+    }
+
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    if err := docsTemplate.Execute(w, data); err != nil {
+        logging.FromContext(r.Context()).Error("failed to render documentation page", "error", err)
+    }
+}
+
+// exampleResponse builds an illustrative JSON response from the endpoint's result columns
+func exampleResponse(endpoint Endpoint) string {
+    var data interface{} = []interface{}{1, 1}
+    if endpoint.ReturnsRows {
+        header := make([]interface{}, len(endpoint.Columns))
+        row := make([]interface{}, len(endpoint.Columns))
+        for i, column := range endpoint.Columns {
+            header[i] = column.Name
+            row[i] = exampleValue(database.JSONType(column.Type))
+        }
+        data = []interface{}{header, row}
+    }
+
+    example, err := json.MarshalIndent(map[string]interface{}{
+        "success": true,
+        "data":    data,
+    }, "", "  ")
+    if err != nil {
+        return ""
+    }
+    return string(example)
+}
+
+// exampleValue returns a placeholder value for a JSON type
+func exampleValue(jsonType string) interface{} {
+    switch jsonType {
+    case "integer":
+        return 1
+    case "number":
+        return 1.5
+    case "boolean":
+        return true
+    case "string":
+        return "text"
+    default:
+        return nil
+    }
+}
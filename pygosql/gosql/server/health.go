@@ -0,0 +1,223 @@
+// health.go
+package server
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "gosql/auth"
+    "gosql/database"
+    "net/http"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// HealthCheck reports why a dependency of the server is not ready, or nil when it is
+type HealthCheck func(ctx context.Context) error
+
+// CheckTimeout bounds how long a single readiness check may run
+const CheckTimeout = 5 * time.Second
+
+// CheckResult is the outcome of one readiness check as reported by /readyz
+type CheckResult struct {
+    Status     string  `json:"status"`          // "ok" or "fail"
+    Error      string  `json:"error,omitempty"` // Why the check failed
+    DurationMS float64 `json:"duration_ms"`     // Time the check took
+}
+
+// healthChecks is the registry of named readiness checks
+type healthChecks struct {
+    mu     sync.RWMutex           // Guards checks against registration while serving
+    checks map[string]HealthCheck // Checks by name
+}
+
+// RegisterCheck adds a readiness check run by /readyz, replacing any check with the
+// same name
+func (s *Server) RegisterCheck(name string, check HealthCheck) {
+    s.checks.mu.Lock()
+    defer s.checks.mu.Unlock()
+    if s.checks.checks == nil {
+        s.checks.checks = make(map[string]HealthCheck)
+    }
+    s.checks.checks[name] = check
+}
+
+// registerDefaultChecks registers the built-in readiness checks for the configuration
+func (s *Server) registerDefaultChecks() {
+    if s.db != nil {
+        s.RegisterCheck("database", s.db.Ping)
+        if s.config.ReadyWriteProbe {
+            s.RegisterCheck("write", s.db.WriteProbe)
+        }
+        s.RegisterCheck("migrations", s.checkMigrations)
+        if s.config.MinFreeDiskMB > 0 {
+            s.RegisterCheck("disk", s.checkDiskSpace)
+        }
+    }
+    s.RegisterCheck("endpoints", s.checkEndpoints)
+}
+
+// RunChecks runs every registered check concurrently and reports whether all passed
+func (s *Server) RunChecks(ctx context.Context) (map[string]CheckResult, bool) {
+    s.checks.mu.RLock()
+    checks := make(map[string]HealthCheck, len(s.checks.checks))
+    for name, check := range s.checks.checks {
+        checks[name] = check
+    }
+    s.checks.mu.RUnlock()
+
+    var mu sync.Mutex
+    var wg sync.WaitGroup
+    results := make(map[string]CheckResult, len(checks))
+    healthy := true
+    for name, check := range checks {
+        wg.Add(1)
+        go func(name string, check HealthCheck) {
+            defer wg.Done()
+            checkCtx, cancel := context.WithTimeout(ctx, CheckTimeout)
+            defer cancel()
+
+            start := time.Now()
+            err := runCheck(checkCtx, check)
+            result := CheckResult{Status: "ok", DurationMS: float64(time.Since(start).Microseconds()) / 1000}
+            if err != nil {
+                result.Status, result.Error = "fail", err.Error()
+            }
+
+            mu.Lock()
+            defer mu.Unlock()
+            results[name] = result
+            healthy = healthy && err == nil
+        }(name, check)
+    }
+    wg.Wait()
+    return results, healthy
+}
+
+// runCheck runs a check, failing it when it outlives its context
+func runCheck(ctx context.Context, check HealthCheck) error {
+    done := make(chan error, 1)
+    go func() { done <- check(ctx) }()
+    select {
+    case err := <-done:
+        return err
+    case <-ctx.Done():
+        return fmt.Errorf("timed out after %s", CheckTimeout)
+    }
+}
+
+// checkMigrations fails while migration files have not been applied
+func (s *Server) checkMigrations(ctx context.Context) error {
+    migrations, err := database.LoadMigrations(s.config.MigrationsPath())
+    if err != nil {
+        return err
+    }
+    pending, err := s.db.PendingMigrations(migrations)
+    if err != nil {
+        return err
+    }
+    if len(pending) > 0 {
+        names := make([]string, len(pending))
+        for i, migration := range pending {
+            names[i] = migration.String()
+        }
+        return fmt.Errorf("%d pending migration(s): %s", len(pending), strings.Join(names, ", "))
+    }
+    return nil
+}
+
+// checkDiskSpace fails when the database directory has less free space than configured
+func (s *Server) checkDiskSpace(ctx context.Context) error {
+    dir := filepath.Dir(s.db.GetPath())
+    free, err := freeDiskSpace(dir)
+    if err != nil {
+        return fmt.Errorf("failed to read free space of %s: %w", dir, err)
+    }
+    if need := uint64(s.config.MinFreeDiskMB) << 20; free < need {
+        return fmt.Errorf("%d MB free in %s, need %d MB", free>>20, dir, s.config.MinFreeDiskMB)
+    }
+    return nil
+}
+
+// checkEndpoints fails when any SQL file could not be loaded as an endpoint
+func (s *Server) checkEndpoints(ctx context.Context) error {
+    var failures []string
+    for _, endpoint := range s.Endpoints() {
+        if endpoint.LoadError != "" {
+            failures = append(failures, fmt.Sprintf("%s: %s", endpoint.SQLPath, endpoint.LoadError))
+        }
+    }
+    if len(failures) > 0 {
+        sort.Strings(failures)
+        return errors.New(strings.Join(failures, "; "))
+    }
+    return nil
+}
+
+// LivezHandler reports that the process is up and serving requests
+// It checks nothing else, so a failing dependency does not get the process restarted
+func (s *Server) LivezHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "OPTIONS" {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+    if r.Method != "GET" {
+        s.WriteJSONResponse(w, http.StatusMethodNotAllowed, map[string]interface{}{
+            "success": false,
+            "error":   "Only GET method allowed for liveness check",
+        })
+        return
+    }
+
+    s.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+        "status":    "ok",
+        "timestamp": time.Now().Format(time.RFC3339),
+    })
+}
+
+// ReadyzHandler runs the readiness checks and responds 503 when any of them fails
+// The checks and their errors, which name files and migrations, are only reported to
+// authenticated callers, or to everyone with ReadyDetails
+func (s *Server) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "OPTIONS" {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+    if r.Method != "GET" {
+        s.WriteJSONResponse(w, http.StatusMethodNotAllowed, map[string]interface{}{
+            "success": false,
+            "error":   "Only GET method allowed for readiness check",
+        })
+        return
+    }
+
+    results, ready := s.RunChecks(r.Context())
+    status, code := "ready", http.StatusOK
+    if !ready {
+        status, code = "not ready", http.StatusServiceUnavailable
+    }
+    response := map[string]interface{}{
+        "status":    status,
+        "timestamp": time.Now().Format(time.RFC3339),
+    }
+    if s.config.ReadyDetails || s.authenticated(r) {
+        response["checks"] = results
+    }
+    s.WriteJSONResponse(w, code, response)
+}
+
+// authenticated reports whether the request carries valid credentials, also on routes
+// exempt from authentication
+func (s *Server) authenticated(r *http.Request) bool {
+    if auth.FromContext(r.Context()) != nil {
+        return true
+    }
+    if s.auth == nil {
+        return false
+    }
+    _, err := s.auth.Authenticate(r)
+    return err == nil
+}
@@ -0,0 +1,96 @@
+// listen.go
+package server
+
+import (
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "strconv"
+    "time"
+)
+
+// Listen opens a TCP listener for every bind address and the Unix socket, if configured
+// A bind address of "none" disables TCP so the server is only reachable through the socket
+func (s *Server) Listen() ([]net.Listener, error) {
+    var listeners []net.Listener
+    closeAll := func() {
+        for _, l := range listeners {
+            l.Close()
+        }
+    }
+
+    // Port 0 picks an ephemeral port once, then every other address shares it
+    port := s.config.Port
+    for _, address := range s.config.BindAddresses {
+        if address == "none" {
+            continue
+        }
+        l, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+        if err != nil {
+            closeAll()
+            return nil, fmt.Errorf("failed to listen on %s port %d: %w", address, port, err)
+        }
+        port = l.Addr().(*net.TCPAddr).Port
+        listeners = append(listeners, l)
+    }
+
+    if s.config.UnixSocket != "" {
+        l, err := listenUnix(s.config.UnixSocket, s.config.SocketMode)
+        if err != nil {
+            closeAll()
+            return nil, err
+        }
+        listeners = append(listeners, l)
+    }
+
+    if len(listeners) == 0 {
+        return nil, errors.New("no listeners configured: set a bind address or a Unix socket")
+    }
+    return listeners, nil
+}
+
+// listenUnix listens on a Unix socket, replacing a stale socket file left by a crashed server
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+    if info, err := os.Stat(path); err == nil {
+        if info.Mode()&os.ModeSocket == 0 {
+            return nil, fmt.Errorf("%s exists and is not a socket", path)
+        }
+        if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+            conn.Close()
+            return nil, fmt.Errorf("another server is already listening on %s", path)
+        }
+        os.Remove(path)
+    }
+
+    l, err := net.Listen("unix", path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to listen on socket %s: %w", path, err)
+    }
+    if err := os.Chmod(path, mode); err != nil {
+        l.Close()
+        return nil, fmt.Errorf("failed to set mode of socket %s: %w", path, err)
+    }
+    return l, nil
+}
+
+// listenerURL returns the address clients use to reach a listener
+func (s *Server) listenerURL(l net.Listener) string {
+    if l.Addr().Network() == "unix" {
+        return "unix:" + l.Addr().String()
+    }
+    host, port, _ := net.SplitHostPort(l.Addr().String())
+    if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+        host = "localhost"
+    }
+    return fmt.Sprintf("%s://%s", s.listenerScheme(l), net.JoinHostPort(host, port))
+}
+
+// listenerScheme returns the scheme a listener is served with: Unix sockets are always
+// plain HTTP, TCP listeners follow the configuration
+func (s *Server) listenerScheme(l net.Listener) string {
+    if l.Addr().Network() == "unix" {
+        return "http"
+    }
+    return s.config.Scheme()
+}
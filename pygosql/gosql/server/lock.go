@@ -0,0 +1,121 @@
+// lock.go
+package server
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "gosql/setup"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// LockInfo is the content of the lock file held while a server runs
+type LockInfo struct {
+    PID      int    `json:"pid"`      // Process serving the database
+    Database string `json:"database"` // Absolute database path
+    SQLRoot  string `json:"sql_root"` // Absolute SQL root
+}
+
+// LockPath returns the lock file kept next to the database, or "" for in-memory databases
+func LockPath(databasePath string) string {
+    if databasePath == "" || databasePath == ":memory:" || strings.HasPrefix(databasePath, "file:") {
+        return ""
+    }
+    return databasePath + ".lock"
+}
+
+// errLockHeld is returned by lockFile when another open file holds the lock
+var errLockHeld = errors.New("lock is held")
+
+// Lock is a lock file held while a server runs on a database
+type Lock struct {
+    path string   // Lock file, empty once released or when none is needed
+    file *os.File // Open lock file, which holds the operating system lock
+}
+
+// AcquireLock records this process in the lock file of cfg's database, refusing when
+// another server, in this process or another, holds it
+// The lock is an operating system lock on the open file, which ends with the process, so a
+// file left behind by a crashed server is simply taken over. Take it before opening the
+// database, so a refused server never touches the schema
+func AcquireLock(cfg setup.Config) (*Lock, error) {
+    path := LockPath(cfg.DatabasePath)
+    if path == "" {
+        return &Lock{}, nil
+    }
+    database, _ := filepath.Abs(cfg.DatabasePath)
+    sqlRoot, _ := filepath.Abs(cfg.SQLRoot)
+    content, err := json.Marshal(LockInfo{PID: os.Getpid(), Database: database, SQLRoot: sqlRoot})
+    if err != nil {
+        return nil, err
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return nil, fmt.Errorf("failed to create database directory: %w", err)
+    }
+
+    file, err := lockFile(path)
+    if errors.Is(err, errLockHeld) {
+        return nil, lockHeldError(cfg, path)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to create lock file %s: %w", path, err)
+    }
+    if err := file.Truncate(0); err == nil {
+        _, err = file.WriteAt(append(content, '\n'), 0)
+    }
+    if err != nil {
+        unlockFile(file, path)
+        return nil, fmt.Errorf("failed to write lock file %s: %w", path, err)
+    }
+    return &Lock{path: path, file: file}, nil
+}
+
+// LockHolder returns the server holding the lock on cfg's database, or nil when none does
+func LockHolder(cfg setup.Config) *LockInfo {
+    path := LockPath(cfg.DatabasePath)
+    if path == "" {
+        return nil
+    }
+    if _, err := os.Stat(path); err != nil {
+        return nil
+    }
+    file, err := lockFile(path)
+    if err == nil {
+        // Nobody holds it; leave the file for AcquireLock to take over
+        file.Close()
+        return nil
+    }
+    holder := readLockInfo(path)
+    return &holder
+}
+
+// lockHeldError describes the server holding the lock on cfg's database
+func lockHeldError(cfg setup.Config, path string) error {
+    holder := readLockInfo(path)
+    switch holder.PID {
+    case 0:
+        return fmt.Errorf("%s is already served by another process (see %s)", cfg.DatabasePath, path)
+    case os.Getpid():
+        return fmt.Errorf("%s is already served by this process (SQL root %s)", cfg.DatabasePath, holder.SQLRoot)
+    }
+    return fmt.Errorf("%s is already served by process %d (SQL root %s)", cfg.DatabasePath, holder.PID, holder.SQLRoot)
+}
+
+// readLockInfo reads the holder recorded in a lock file, zero when it cannot be read
+func readLockInfo(path string) LockInfo {
+    var holder LockInfo
+    if data, err := os.ReadFile(path); err == nil {
+        json.Unmarshal(data, &holder)
+    }
+    return holder
+}
+
+// Release removes the lock file and drops the lock; it is safe to call more than once
+func (l *Lock) Release() {
+    if l != nil && l.file != nil {
+        unlockFile(l.file, l.path)
+        l.path, l.file = "", nil
+    }
+}
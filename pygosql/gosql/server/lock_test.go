@@ -0,0 +1,79 @@
+// lock_test.go
+package server
+
+import (
+    "gosql/setup"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// lockConfig returns a configuration for a database in a temporary directory
+func lockConfig(t *testing.T) setup.Config {
+    t.Helper()
+    cfg := setup.DefaultConfig()
+    cfg.DatabasePath = filepath.Join(t.TempDir(), "app.db")
+    return cfg
+}
+
+func TestAcquireLockRefusesSecondHolder(t *testing.T) {
+    cfg := lockConfig(t)
+    lock, err := AcquireLock(cfg)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if holder := LockHolder(cfg); holder == nil || holder.PID != os.Getpid() {
+        t.Errorf("LockHolder = %+v, want this process", holder)
+    }
+
+    // The same process is refused too, so two apps cannot serve one database
+    if _, err := AcquireLock(cfg); err == nil || !strings.Contains(err.Error(), "this process") {
+        t.Fatalf("second AcquireLock: %v", err)
+    }
+
+    lock.Release()
+    lock.Release()
+    if _, err := os.Stat(LockPath(cfg.DatabasePath)); !os.IsNotExist(err) {
+        t.Errorf("lock file left after Release: %v", err)
+    }
+    if holder := LockHolder(cfg); holder != nil {
+        t.Errorf("LockHolder after Release = %+v", holder)
+    }
+    lock, err = AcquireLock(cfg)
+    if err != nil {
+        t.Fatalf("AcquireLock after Release: %v", err)
+    }
+    lock.Release()
+}
+
+func TestAcquireLockTakesOverLeftoverFile(t *testing.T) {
+    cfg := lockConfig(t)
+    // A file without an operating system lock, as a crashed server leaves behind
+    leftover := `{"pid": 1, "database": "elsewhere"}` + strings.Repeat(" ", 100)
+    if err := os.WriteFile(LockPath(cfg.DatabasePath), []byte(leftover), 0644); err != nil {
+        t.Fatal(err)
+    }
+    if holder := LockHolder(cfg); holder != nil {
+        t.Errorf("LockHolder of a leftover file = %+v", holder)
+    }
+
+    lock, err := AcquireLock(cfg)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer lock.Release()
+    if holder := readLockInfo(LockPath(cfg.DatabasePath)); holder.PID != os.Getpid() {
+        t.Errorf("lock file records pid %d, want %d", holder.PID, os.Getpid())
+    }
+}
+
+func TestAcquireLockWithoutFile(t *testing.T) {
+    cfg := setup.DefaultConfig()
+    cfg.DatabasePath = ":memory:"
+    lock, err := AcquireLock(cfg)
+    if err != nil {
+        t.Fatal(err)
+    }
+    lock.Release()
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+
+// lock_unix.go
+package server
+
+import (
+    "fmt"
+    "os"
+    "syscall"
+)
+
+// lockFile opens path and takes an exclusive flock on it without waiting, returning
+// errLockHeld when another open file holds it, even one in this process
+// The kernel drops the flock when its holder exits, so a crashed server leaves no lock
+func lockFile(path string) (*os.File, error) {
+    for attempt := 0; attempt < 3; attempt++ {
+        file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+        if err != nil {
+            return nil, err
+        }
+        if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+            file.Close()
+            if err == syscall.EWOULDBLOCK {
+                return nil, errLockHeld
+            }
+            return nil, err
+        }
+        // A holder releasing between the open and the flock removed the file we locked;
+        // only the file still at path counts
+        locked, err := file.Stat()
+        current, statErr := os.Stat(path)
+        if err == nil && statErr == nil && os.SameFile(locked, current) {
+            return file, nil
+        }
+        file.Close()
+    }
+    return nil, fmt.Errorf("lock file %s keeps being replaced", path)
+}
+
+// unlockFile removes the lock file before closing it, so nobody can lock a file that is
+// about to disappear
+func unlockFile(file *os.File, path string) {
+    os.Remove(path)
+    file.Close()
+}
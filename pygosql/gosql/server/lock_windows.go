@@ -0,0 +1,39 @@
+//go:build windows
+
+// lock_windows.go
+package server
+
+import (
+    "os"
+    "syscall"
+)
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION, which syscall does not define
+const errorSharingViolation syscall.Errno = 32
+
+// lockFile opens path without sharing write access, returning errLockHeld when another
+// handle has it open, even one in this process
+// Windows closes the handle when its holder exits, so a crashed server leaves no lock
+func lockFile(path string) (*os.File, error) {
+    name, err := syscall.UTF16PtrFromString(path)
+    if err != nil {
+        return nil, err
+    }
+    // Others may still read the file to find out who holds it
+    handle, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, syscall.FILE_SHARE_READ,
+        nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+    if err == errorSharingViolation {
+        return nil, errLockHeld
+    }
+    if err != nil {
+        return nil, &os.PathError{Op: "open", Path: path, Err: err}
+    }
+    return os.NewFile(uintptr(handle), path), nil
+}
+
+// unlockFile closes the lock file and removes it; Windows refuses to remove a file
+// another handle has opened in the meantime, which leaves that holder's lock alone
+func unlockFile(file *os.File, path string) {
+    file.Close()
+    os.Remove(path)
+}
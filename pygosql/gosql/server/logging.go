@@ -0,0 +1,68 @@
+// logging.go
+package server
+
+import (
+    "gosql/logging"
+    "gosql/tracing"
+    "net/http"
+)
+
+// RequestIDHeader carries the request id in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLogger gives each request a logger carrying a request id, method, path and
+// trace id when traced, which handlers retrieve with logging.FromContext
+// The id comes from a well-formed incoming X-Request-ID header when present and is
+// echoed back in the response header
+func RequestLogger(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requestID := r.Header.Get(RequestIDHeader)
+        if !validRequestID(requestID) {
+            requestID = logging.NewRequestID()
+        }
+        w.Header().Set(RequestIDHeader, requestID)
+
+        logger := logging.FromContext(r.Context()).With(
+            "request_id", requestID,
+            "method", r.Method,
+            "path", r.URL.Path,
+        )
+        if span := tracing.SpanFromContext(r.Context()); span != nil {
+            logger = logger.With("trace_id", span.Context.TraceID.String())
+        }
+        next.ServeHTTP(w, r.WithContext(logging.NewContext(r.Context(), logger)))
+    })
+}
+
+// withEndpoint adds the matched endpoint to the request-scoped logger
+func withEndpoint(r *http.Request, endpoint Endpoint) *http.Request {
+    logger := logging.FromContext(r.Context()).With("endpoint", endpoint.Method+" "+endpoint.Path)
+    return r.WithContext(logging.NewContext(r.Context(), logger))
+}
+
+// validRequestID accepts ids of up to 128 visible ASCII characters, so a client cannot
+// inject whitespace or control characters into log lines
+func validRequestID(id string) bool {
+    if id == "" || len(id) > 128 {
+        return false
+    }
+    for i := 0; i < len(id); i++ {
+        if id[i] <= ' ' || id[i] > '~' {
+            return false
+        }
+    }
+    return true
+}
+
+// withRequestID adds the request id to error response bodies so a failure reported by
+// a client can be matched to its log lines
+func withRequestID(w http.ResponseWriter, data interface{}) interface{} {
+    body, ok := data.(map[string]interface{})
+    if !ok || body["error"] == nil {
+        return data
+    }
+    if requestID := w.Header().Get(RequestIDHeader); requestID != "" {
+        body["request_id"] = requestID
+    }
+    return body
+}
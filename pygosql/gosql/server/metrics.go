@@ -0,0 +1,204 @@
+// metrics.go
+package server
+
+import (
+    "context"
+    "fmt"
+    "gosql/metrics"
+    "gosql/ratelimit"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Phases a SQL endpoint request is timed in
+const (
+    PhaseParse   = "parse"   // Extracting request parameters
+    PhaseExecute = "execute" // Expanding the template and running the statement
+    PhaseEncode  = "encode"  // Writing the JSON response
+)
+
+// requestMetrics holds the per-endpoint request metrics
+type requestMetrics struct {
+    requests *metrics.CounterVec   // Requests by endpoint and status
+    duration *metrics.HistogramVec // Total handling time by endpoint
+    phases   *metrics.HistogramVec // Handling time by endpoint and phase
+    rows     *metrics.CounterVec   // Rows returned by endpoint
+}
+
+// newRequestMetrics creates empty request metrics
+func newRequestMetrics() *requestMetrics {
+    return &requestMetrics{
+        requests: metrics.NewCounterVec("gosql_http_requests_total",
+            "SQL endpoint requests by status code.", "method", "path", "status"),
+        duration: metrics.NewHistogramVec("gosql_http_request_duration_seconds",
+            "Time to handle SQL endpoint requests.", metrics.DefaultBuckets, "method", "path"),
+        phases: metrics.NewHistogramVec("gosql_http_request_phase_seconds",
+            "Time SQL endpoint requests spent parsing parameters, executing SQL and encoding the response.",
+            metrics.DefaultBuckets, "method", "path", "phase"),
+        rows: metrics.NewCounterVec("gosql_rows_returned_total",
+            "Rows returned by row-returning SQL endpoints.", "method", "path"),
+    }
+}
+
+// observe records a finished request
+func (m *requestMetrics) observe(endpoint Endpoint, status int, elapsed time.Duration, stats *requestStats) {
+    m.requests.Inc(endpoint.Method, endpoint.Path, strconv.Itoa(status))
+    m.duration.Observe(elapsed.Seconds(), endpoint.Method, endpoint.Path)
+    for _, phase := range []string{PhaseParse, PhaseExecute, PhaseEncode} {
+        if elapsed, ok := stats.phases[phase]; ok {
+            m.phases.Observe(elapsed.Seconds(), endpoint.Method, endpoint.Path, phase)
+        }
+    }
+    if stats.rows > 0 {
+        m.rows.Add(float64(stats.rows), endpoint.Method, endpoint.Path)
+    }
+}
+
+// requestStats collects what is known about a request while it is handled, for metrics
+// and the access log: the matched route, the caller, phase durations and the row count
+type requestStats struct {
+    route  string                   // Matched endpoint route, empty for system endpoints
+    user   string                   // Authenticated principal, empty when anonymous
+    phases map[string]time.Duration // Duration of each completed phase
+    rows   int                      // Rows in the result
+}
+
+type statsKey struct{}
+
+// newRequestStats returns empty request stats
+func newRequestStats() *requestStats {
+    return &requestStats{phases: make(map[string]time.Duration)}
+}
+
+// withStats returns a context that handlers record into
+func withStats(ctx context.Context, stats *requestStats) context.Context {
+    return context.WithValue(ctx, statsKey{}, stats)
+}
+
+// statsFrom returns the request's stats, or nil when the request is not measured
+func statsFrom(ctx context.Context) *requestStats {
+    stats, _ := ctx.Value(statsKey{}).(*requestStats)
+    return stats
+}
+
+// phase records the time since start for a phase; it does nothing on nil stats
+func (t *requestStats) phase(name string, start time.Time) {
+    if t != nil {
+        t.phases[name] = time.Since(start)
+    }
+}
+
+// setRows records the number of rows returned; it does nothing on nil stats
+func (t *requestStats) setRows(rows int) {
+    if t != nil {
+        t.rows = rows
+    }
+}
+
+// statusRecorder remembers the status code and body size written through it
+type statusRecorder struct {
+    http.ResponseWriter
+    status int   // Status code, 200 until WriteHeader is called
+    bytes  int64 // Body bytes written
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+    r.status = status
+    r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+    n, err := r.ResponseWriter.Write(b)
+    r.bytes += int64(n)
+    return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+    return r.ResponseWriter
+}
+
+// MetricsHandler serves server metrics in the Prometheus text exposition format
+func (s *Server) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "OPTIONS" {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    if r.Method != "GET" {
+        s.WriteJSONResponse(w, http.StatusMethodNotAllowed, map[string]interface{}{
+            "success": false,
+            "error":   "Only GET method allowed for metrics",
+        })
+        return
+    }
+
+    var b strings.Builder
+    s.writeRequestMetrics(&b)
+    s.writeDatabaseMetrics(&b)
+    s.writeRateLimitMetrics(&b)
+
+    w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    w.Write([]byte(b.String()))
+}
+
+// writeRequestMetrics writes the per-endpoint request counters and histograms
+func (s *Server) writeRequestMetrics(b *strings.Builder) {
+    s.metrics.requests.Write(b)
+    s.metrics.duration.Write(b)
+    s.metrics.phases.Write(b)
+    s.metrics.rows.Write(b)
+}
+
+// writeDatabaseMetrics writes connection pool gauges, lock waits and file sizes
+func (s *Server) writeDatabaseMetrics(b *strings.Builder) {
+    if s.db == nil {
+        return
+    }
+    stats := s.db.Stats()
+
+    metrics.WriteSample(b, "gosql_db_max_open_connections", "Maximum open connections allowed by the pool.", "gauge", float64(stats.Pool.MaxOpenConnections))
+    metrics.WriteSample(b, "gosql_db_open_connections", "Open connections, in use and idle.", "gauge", float64(stats.Pool.OpenConnections))
+    metrics.WriteSample(b, "gosql_db_in_use_connections", "Connections currently in use.", "gauge", float64(stats.Pool.InUse))
+    metrics.WriteSample(b, "gosql_db_idle_connections", "Idle connections.", "gauge", float64(stats.Pool.Idle))
+    metrics.WriteSample(b, "gosql_db_wait_count_total", "Times a statement waited for a pool connection.", "counter", float64(stats.Pool.WaitCount))
+    metrics.WriteSample(b, "gosql_db_wait_duration_seconds_total", "Time spent waiting for pool connections.", "counter", stats.Pool.WaitDuration.Seconds())
+    metrics.WriteSample(b, "gosql_db_lock_acquired_total", "Statements that acquired the database write lock.", "counter", float64(stats.LockAcquired))
+    metrics.WriteSample(b, "gosql_db_lock_wait_seconds_total", "Time statements spent waiting for the database write lock.", "counter", stats.LockWait.Seconds())
+    metrics.WriteSample(b, "gosql_db_file_size_bytes", "Size of the SQLite database file.", "gauge", float64(stats.FileSize))
+    metrics.WriteSample(b, "gosql_db_wal_size_bytes", "Size of the SQLite write-ahead log.", "gauge", float64(stats.WALSize))
+}
+
+// writeRateLimitMetrics writes the rate limiter counters and configured limits
+func (s *Server) writeRateLimitMetrics(b *strings.Builder) {
+    counts, clients := s.limiter.Stats()
+
+    metrics.WriteHeader(b, "gosql_ratelimit_requests_total", "Requests checked by the rate limiter, by outcome.", "counter")
+    for _, scope := range ratelimit.Scopes(counts) {
+        method, path, _ := strings.Cut(scope, " ")
+        fmt.Fprintf(b, "gosql_ratelimit_requests_total%s %d\n", metrics.Labels([]string{"method", "path", "result"}, []string{method, path, "allowed"}), counts[scope].Allowed)
+        fmt.Fprintf(b, "gosql_ratelimit_requests_total%s %d\n", metrics.Labels([]string{"method", "path", "result"}, []string{method, path, "limited"}), counts[scope].Limited)
+    }
+
+    metrics.WriteSample(b, "gosql_ratelimit_clients", "Clients with a partially drained token bucket.", "gauge", float64(clients))
+
+    routes := s.routes.Load()
+    limits := make([]ratelimit.Limit, len(routes.endpoints))
+    for i, endpoint := range routes.endpoints {
+        limit, ok := routes.limits[endpointKey(endpoint)]
+        if !ok {
+            limit = s.defaultLimit
+        }
+        limits[i] = limit
+    }
+    metrics.WriteHeader(b, "gosql_ratelimit_limit_rate", "Configured requests per second per client, 0 when unlimited.", "gauge")
+    for i, endpoint := range routes.endpoints {
+        fmt.Fprintf(b, "gosql_ratelimit_limit_rate%s %g\n", metrics.Labels([]string{"method", "path"}, []string{endpoint.Method, endpoint.Path}), limits[i].Rate)
+    }
+    metrics.WriteHeader(b, "gosql_ratelimit_limit_burst", "Configured burst size per client.", "gauge")
+    for i, endpoint := range routes.endpoints {
+        fmt.Fprintf(b, "gosql_ratelimit_limit_burst%s %d\n", metrics.Labels([]string{"method", "path"}, []string{endpoint.Method, endpoint.Path}), limits[i].Burst)
+    }
+}
@@ -0,0 +1,180 @@
+// metrics_test.go
+package server
+
+import (
+    "gosql/database"
+    "gosql/setup"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// newMetricsServer serves GET /api/v1/items over a two-row table with the given rate limit;
+// configure, when given, adjusts the configuration first
+func newMetricsServer(t *testing.T, rateLimit string, configure ...func(*setup.Config)) *Server {
+    t.Helper()
+    dir := t.TempDir()
+    db, err := database.NewDatabase(database.Config{
+        Path:              filepath.Join(dir, "app.db"),
+        CreateIfNotExists: true,
+        Schema:            "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT); INSERT INTO items (name) VALUES ('a'), ('b');",
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    sqlPath := filepath.Join(dir, "sql", "GET", "items.sql")
+    if err := os.MkdirAll(filepath.Dir(sqlPath), 0755); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(sqlPath, []byte("SELECT * FROM items;\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    cfg := setup.DefaultConfig()
+    cfg.DatabasePath = filepath.Join(dir, "app.db")
+    cfg.SQLRoot = filepath.Join(dir, "sql")
+    cfg.RateLimit = rateLimit
+    for _, apply := range configure {
+        apply(&cfg)
+    }
+    s, err := NewServer(cfg, []Endpoint{AssembleEndpoint(sqlPath, db, cfg.BaseURL)}, db)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return s
+}
+
+// get sends a GET request through the server's handler and returns the recorder
+func get(s *Server, path string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+    return w
+}
+
+// scrape returns the /metrics page
+func scrape(t *testing.T, s *Server) string {
+    t.Helper()
+    w := get(s, "/metrics")
+    if w.Code != http.StatusOK {
+        t.Fatalf("GET /metrics: status %d", w.Code)
+    }
+    if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+        t.Errorf("GET /metrics: Content-Type %q", contentType)
+    }
+    return w.Body.String()
+}
+
+// expectLines fails for every line missing from the scraped page
+func expectLines(t *testing.T, page string, lines ...string) {
+    t.Helper()
+    for _, line := range lines {
+        if !strings.Contains("\n"+page, "\n"+line+"\n") {
+            t.Errorf("metrics are missing %q", line)
+        }
+    }
+}
+
+func TestMetricsCountRequests(t *testing.T) {
+    s := newMetricsServer(t, "")
+    for i := 0; i < 3; i++ {
+        if w := get(s, "/api/v1/items"); w.Code != http.StatusOK {
+            t.Fatalf("GET /api/v1/items: status %d: %s", w.Code, w.Body.String())
+        }
+    }
+
+    labels := `method="GET",path="/api/v1/items"`
+    expectLines(t, scrape(t, s),
+        "# TYPE gosql_http_requests_total counter",
+        `gosql_http_requests_total{`+labels+`,status="200"} 3`,
+        "# TYPE gosql_http_request_duration_seconds histogram",
+        `gosql_http_request_duration_seconds_bucket{`+labels+`,le="+Inf"} 3`,
+        `gosql_http_request_duration_seconds_count{`+labels+`} 3`,
+        `gosql_http_request_phase_seconds_count{`+labels+`,phase="parse"} 3`,
+        `gosql_http_request_phase_seconds_count{`+labels+`,phase="execute"} 3`,
+        `gosql_http_request_phase_seconds_count{`+labels+`,phase="encode"} 3`,
+        `gosql_rows_returned_total{`+labels+`} 6`,
+    )
+}
+
+func TestMetricsHistogramBucketsAreCumulative(t *testing.T) {
+    s := newMetricsServer(t, "")
+    get(s, "/api/v1/items")
+
+    prefix := `gosql_http_request_duration_seconds_bucket{method="GET",path="/api/v1/items",le=`
+    previous, buckets := -1, 0
+    for _, line := range strings.Split(scrape(t, s), "\n") {
+        if !strings.HasPrefix(line, prefix) {
+            continue
+        }
+        buckets++
+        fields := strings.Fields(line)
+        count, err := strconv.Atoi(fields[len(fields)-1])
+        if err != nil {
+            t.Fatalf("bad bucket line %q", line)
+        }
+        if count < previous {
+            t.Errorf("bucket counts decrease at %q", line)
+        }
+        previous = count
+    }
+    if buckets < 2 || previous != 1 {
+        t.Errorf("expected cumulative buckets ending at 1, got %d buckets ending at %d", buckets, previous)
+    }
+}
+
+func TestMetricsCountRateLimits(t *testing.T) {
+    s := newMetricsServer(t, "2/m")
+    codes := make([]int, 3)
+    for i := range codes {
+        codes[i] = get(s, "/api/v1/items").Code
+    }
+    if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+        t.Fatalf("unexpected status codes %v", codes)
+    }
+
+    labels := `method="GET",path="/api/v1/items"`
+    expectLines(t, scrape(t, s),
+        `gosql_http_requests_total{`+labels+`,status="200"} 2`,
+        `gosql_http_requests_total{`+labels+`,status="429"} 1`,
+        "# TYPE gosql_ratelimit_requests_total counter",
+        `gosql_ratelimit_requests_total{`+labels+`,result="allowed"} 2`,
+        `gosql_ratelimit_requests_total{`+labels+`,result="limited"} 1`,
+        "gosql_ratelimit_clients 1",
+        `gosql_ratelimit_limit_burst{`+labels+`} 2`,
+    )
+}
+
+func TestMetricsIncludeDatabaseStats(t *testing.T) {
+    s := newMetricsServer(t, "")
+    get(s, "/api/v1/items")
+    page := scrape(t, s)
+    for _, name := range []string{"gosql_db_open_connections", "gosql_db_lock_acquired_total", "gosql_db_file_size_bytes"} {
+        if !strings.Contains(page, "\n"+name+" ") {
+            t.Errorf("metrics are missing %s", name)
+        }
+    }
+}
+
+func TestMetricsCountGraphQLRequests(t *testing.T) {
+    s := newMetricsServer(t, "2/m", func(cfg *setup.Config) { cfg.EnableGraphQL = true })
+    codes := make([]int, 3)
+    for i := range codes {
+        codes[i] = get(s, "/graphql?query=%7Bitems%7Bid%7D%7D").Code
+    }
+    if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+        t.Fatalf("unexpected status codes %v", codes)
+    }
+
+    labels := `method="GET",path="/graphql"`
+    expectLines(t, scrape(t, s),
+        `gosql_http_requests_total{`+labels+`,status="200"} 2`,
+        `gosql_http_requests_total{`+labels+`,status="429"} 1`,
+        `gosql_http_request_duration_seconds_count{`+labels+`} 3`,
+    )
+}
@@ -0,0 +1,350 @@
+// openapi.go
+package server
+
+import (
+    "fmt"
+    "gosql/database"
+    "gosql/ratelimit"
+    "gosql/setup"
+    "net/http"
+    "path/filepath"
+    "regexp"
+    "strings"
+)
+
+// OpenAPIHandler serves an OpenAPI 3.1 document describing every registered endpoint
+func (s *Server) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "OPTIONS" {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    if r.Method != "GET" {
+        s.WriteJSONResponse(w, http.StatusMethodNotAllowed, map[string]interface{}{
+            "success": false,
+            "error":   "Only GET method allowed for OpenAPI document",
+        })
+        return
+    }
+
+    s.WriteJSONResponse(w, http.StatusOK, BuildOpenAPI(s.config, s.Endpoints()))
+}
+
+// BuildOpenAPI generates an OpenAPI 3.1 document from the endpoint list
+func BuildOpenAPI(cfg setup.Config, endpoints []Endpoint) map[string]interface{} {
+    paths := make(map[string]interface{})
+
+    // Routes such as /users/by-email and /users/by_email, or one route under two methods,
+    // share a name; later ones get the method and then a number appended
+    operationIDs := make(map[string]bool)
+    addOperation := func(path, method string, operation map[string]interface{}) {
+        item, ok := paths[path].(map[string]interface{})
+        if !ok {
+            item = make(map[string]interface{})
+            paths[path] = item
+        }
+        id := operation["operationId"].(string)
+        if operationIDs[id] {
+            base := id + "_" + strings.ToLower(method)
+            id = base
+            for n := 2; operationIDs[id]; n++ {
+                id = fmt.Sprintf("%s_%d", base, n)
+            }
+            operation["operationId"] = id
+        }
+        operationIDs[id] = true
+        item[strings.ToLower(method)] = operation
+    }
+
+    for _, endpoint := range endpoints {
+        addOperation(endpoint.Path, endpoint.Method, endpointOperation(cfg, endpoint))
+    }
+
+    for _, route := range EnabledSystemRoutes(cfg) {
+        operation := map[string]interface{}{
+            "operationId": "system_" + operationName(route.Path),
+            "summary":     route.Description,
+            "tags":        []string{"system"},
+            "responses": map[string]interface{}{
+                "200": map[string]interface{}{
+                    "description": route.Description,
+                    "content": map[string]interface{}{
+                        "application/json": map[string]interface{}{
+                            "schema": map[string]interface{}{"type": "object"},
+                        },
+                    },
+                },
+            },
+        }
+        if cfg.EnableAuth && IsAuthExempt(cfg, route.Path) {
+            operation["security"] = []map[string]interface{}{}
+        }
+        if route.Path == GraphQLPath && rateLimited(cfg.RateLimit) {
+            operation["responses"].(map[string]interface{})["429"] = map[string]interface{}{"$ref": "#/components/responses/TooManyRequests"}
+        }
+        addOperation(route.Path, route.Method, operation)
+    }
+
+    doc := map[string]interface{}{
+        "openapi": "3.1.0",
+        "info": map[string]interface{}{
+            "title":       "GoSQL HTTP API",
+            "version":     APIVersion,
+            "description": "Endpoints generated from the SQL files under " + cfg.SQLRoot,
+        },
+        "servers": []map[string]interface{}{
+            {"url": fmt.Sprintf("%s://localhost:%d", cfg.Scheme(), cfg.Port)},
+        },
+        "paths": paths,
+        "components": map[string]interface{}{
+            "schemas": map[string]interface{}{
+                "Error": map[string]interface{}{
+                    "type":     "object",
+                    "required": []string{"success", "error"},
+                    "properties": map[string]interface{}{
+                        "success": map[string]interface{}{"const": false},
+                        "error":   map[string]interface{}{"type": "string"},
+                    },
+                },
+                "ExecResult": map[string]interface{}{
+                    "type":        "array",
+                    "description": "Result of a statement that does not return rows",
+                    "prefixItems": []map[string]interface{}{
+                        {"type": "integer", "description": "Rows affected"},
+                        {"type": "integer", "description": "Last insert id"},
+                    },
+                    "items": false,
+                },
+            },
+            "responses": map[string]interface{}{
+                "BadRequest":       errorResponse("Invalid parameters or constraint violation"),
+                "MethodNotAllowed": errorResponse("HTTP method not allowed for this endpoint"),
+                "ServerError":      errorResponse("SQL execution failed"),
+                "TooManyRequests":  tooManyRequestsResponse(),
+            },
+        },
+    }
+
+    if cfg.EnableAuth {
+        components := doc["components"].(map[string]interface{})
+        components["securitySchemes"] = map[string]interface{}{
+            "apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+            "bearer": map[string]interface{}{"type": "http", "scheme": "bearer"},
+        }
+        responses := components["responses"].(map[string]interface{})
+        responses["Unauthorized"] = errorResponse("Missing or invalid API key or bearer token")
+        responses["Forbidden"] = errorResponse("The key lacks a scope required by the endpoint")
+        doc["security"] = []map[string]interface{}{{"apiKey": []string{}}, {"bearer": []string{}}}
+    }
+
+    return doc
+}
+
+// endpointOperation builds the OpenAPI operation object for a SQL endpoint
+func endpointOperation(cfg setup.Config, endpoint Endpoint) map[string]interface{} {
+    tag := endpoint.TableName
+    if tag == "" {
+        tag = "universal"
+    }
+
+    summary := endpoint.Description
+    if summary == "" {
+        summary = fmt.Sprintf("Execute %s", filepath.Base(endpoint.SQLPath))
+    }
+
+    operation := map[string]interface{}{
+        "operationId":             operationName(strings.TrimPrefix(endpoint.Path, cfg.BaseURL)),
+        "summary":                 summary,
+        "tags":                    []string{tag},
+        "x-gosql-sql-path":        filepath.ToSlash(endpoint.SQLPath),
+        "x-gosql-positional-args": endpoint.Positional,
+        "responses": map[string]interface{}{
+            "200": map[string]interface{}{
+                "description": "SQL executed successfully",
+                "content": map[string]interface{}{
+                    "application/json": map[string]interface{}{
+                        "schema": map[string]interface{}{
+                            "type":     "object",
+                            "required": []string{"success", "data"},
+                            "properties": map[string]interface{}{
+                                "success": map[string]interface{}{"const": true},
+                                "data":    resultSchema(endpoint),
+                            },
+                        },
+                    },
+                },
+            },
+            "400": map[string]interface{}{"$ref": "#/components/responses/BadRequest"},
+            "405": map[string]interface{}{"$ref": "#/components/responses/MethodNotAllowed"},
+            "500": map[string]interface{}{"$ref": "#/components/responses/ServerError"},
+        },
+    }
+
+    limit := endpoint.RateLimit
+    if limit == "" {
+        limit = cfg.RateLimit
+    }
+    if rateLimited(limit) {
+        operation["responses"].(map[string]interface{})["429"] = map[string]interface{}{"$ref": "#/components/responses/TooManyRequests"}
+    }
+
+    if cfg.EnableAuth {
+        responses := operation["responses"].(map[string]interface{})
+        responses["401"] = map[string]interface{}{"$ref": "#/components/responses/Unauthorized"}
+        responses["403"] = map[string]interface{}{"$ref": "#/components/responses/Forbidden"}
+        if len(endpoint.Scopes) > 0 {
+            operation["x-gosql-scopes"] = endpoint.Scopes
+        }
+    }
+
+    var parameters []map[string]interface{}
+    bodyProperties := make(map[string]interface{})
+    var bodyRequired []string
+
+    for _, param := range endpoint.Params {
+        schema := paramSchema(param)
+        if param.In == "body" {
+            bodyProperties[param.Name] = schema
+            if param.Required {
+                bodyRequired = append(bodyRequired, param.Name)
+            }
+            continue
+        }
+
+        parameter := map[string]interface{}{
+            "name":     param.Name,
+            "in":       param.In,
+            "required": param.Required,
+            "schema":   schema,
+        }
+        if param.Description != "" {
+            parameter["description"] = param.Description
+        }
+        parameters = append(parameters, parameter)
+    }
+
+    if len(parameters) > 0 {
+        operation["parameters"] = parameters
+    }
+
+    if endpoint.Method == "POST" || endpoint.Method == "PUT" {
+        body := map[string]interface{}{
+            "type":                 "object",
+            "properties":           bodyProperties,
+            "additionalProperties": true,
+        }
+        if len(bodyRequired) > 0 {
+            body["required"] = bodyRequired
+        }
+        operation["requestBody"] = map[string]interface{}{
+            "required": len(bodyRequired) > 0,
+            "content": map[string]interface{}{
+                "application/json": map[string]interface{}{"schema": body},
+            },
+        }
+    }
+
+    return operation
+}
+
+// resultSchema describes the "data" field returned by database.ExecSQL for an endpoint
+func resultSchema(endpoint Endpoint) map[string]interface{} {
+    if !endpoint.ReturnsRows {
+        return map[string]interface{}{"$ref": "#/components/schemas/ExecResult"}
+    }
+
+    schema := map[string]interface{}{
+        "type":        "array",
+        "minItems":    1,
+        "description": "The first row holds the column names, the following rows hold values in column order",
+    }
+    if len(endpoint.Columns) == 0 {
+        schema["items"] = map[string]interface{}{"type": "array"}
+        return schema
+    }
+
+    names := make([]map[string]interface{}, len(endpoint.Columns))
+    values := make([]map[string]interface{}, len(endpoint.Columns))
+    for i, column := range endpoint.Columns {
+        names[i] = map[string]interface{}{"const": column.Name}
+        values[i] = columnSchema(column)
+    }
+
+    schema["prefixItems"] = []map[string]interface{}{
+        {"type": "array", "prefixItems": names, "items": false},
+    }
+    schema["items"] = map[string]interface{}{
+        "type":        "array",
+        "prefixItems": values,
+        "items":       false,
+    }
+    return schema
+}
+
+// columnSchema returns the JSON schema of a result column
+func columnSchema(column database.Column) map[string]interface{} {
+    schema := map[string]interface{}{"title": column.Name}
+    jsonType := database.JSONType(column.Type)
+    if jsonType == "" {
+        return schema
+    }
+    if column.NotNull {
+        schema["type"] = jsonType
+    } else {
+        schema["type"] = []string{jsonType, "null"}
+    }
+    return schema
+}
+
+// paramSchema returns the JSON schema of a request parameter
+func paramSchema(param database.Param) map[string]interface{} {
+    if param.Type == "" {
+        return map[string]interface{}{}
+    }
+    if param.Nullable {
+        return map[string]interface{}{"type": []string{param.Type, "null"}}
+    }
+    return map[string]interface{}{"type": param.Type}
+}
+
+// errorResponse builds a reusable OpenAPI response that carries the Error schema
+func errorResponse(description string) map[string]interface{} {
+    return map[string]interface{}{
+        "description": description,
+        "content": map[string]interface{}{
+            "application/json": map[string]interface{}{
+                "schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+            },
+        },
+    }
+}
+
+// tooManyRequestsResponse describes the 429 response of rate-limited operations
+func tooManyRequestsResponse() map[string]interface{} {
+    response := errorResponse("The client exceeded its rate limit")
+    response["headers"] = map[string]interface{}{
+        "Retry-After": map[string]interface{}{
+            "description": "Seconds until the request may be retried",
+            "required":    true,
+            "schema":      map[string]interface{}{"type": "integer", "minimum": 0},
+        },
+    }
+    return response
+}
+
+// rateLimited reports whether a rate limit specification limits requests
+func rateLimited(spec string) bool {
+    limit, err := ratelimit.ParseLimit(spec)
+    return err == nil && !limit.Unlimited()
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// operationName turns a route such as "/users/by_email" into "users_by_email"
+func operationName(route string) string {
+    name := strings.Trim(nonIdentifier.ReplaceAllString(route, "_"), "_")
+    if name == "" {
+        return "root"
+    }
+    return name
+}
@@ -0,0 +1,83 @@
+// openapi_test.go
+package server
+
+import (
+    "gosql/setup"
+    "testing"
+)
+
+// operation returns the operation for a path and lowercase method of an OpenAPI document
+func operation(t *testing.T, doc map[string]interface{}, path, method string) map[string]interface{} {
+    t.Helper()
+    item, _ := doc["paths"].(map[string]interface{})[path].(map[string]interface{})
+    op, ok := item[method].(map[string]interface{})
+    if !ok {
+        t.Fatalf("no %s %s operation", method, path)
+    }
+    return op
+}
+
+func TestOpenAPIOperationIDsAreUnique(t *testing.T) {
+    cfg := setup.DefaultConfig()
+    doc := BuildOpenAPI(cfg, []Endpoint{
+        {Method: "GET", Path: "/api/v1/users/by-email", SQLPath: "Tables/users/GET/by-email.sql", TableName: "users"},
+        {Method: "GET", Path: "/api/v1/users/by_email", SQLPath: "Tables/users/GET/by_email.sql", TableName: "users"},
+        {Method: "DELETE", Path: "/api/v1/users/by_email", SQLPath: "Tables/users/DELETE/by_email.sql", TableName: "users"},
+        {Method: "GET", Path: "/api/v1/users/by.email", SQLPath: "Tables/users/GET/by.email.sql", TableName: "users"},
+    })
+
+    tests := []struct {
+        path   string
+        method string
+        id     string
+    }{
+        {"/api/v1/users/by-email", "get", "users_by_email"},
+        {"/api/v1/users/by_email", "get", "users_by_email_get"},
+        {"/api/v1/users/by_email", "delete", "users_by_email_delete"},
+        {"/api/v1/users/by.email", "get", "users_by_email_get_2"},
+    }
+    for _, test := range tests {
+        if id := operation(t, doc, test.path, test.method)["operationId"]; id != test.id {
+            t.Errorf("%s %s: operationId %v, want %s", test.method, test.path, id, test.id)
+        }
+    }
+}
+
+func TestOpenAPIDocumentsRateLimits(t *testing.T) {
+    cfg := setup.DefaultConfig()
+    cfg.RateLimit = "10/s"
+    cfg.EnableGraphQL = true
+    doc := BuildOpenAPI(cfg, []Endpoint{
+        {Method: "GET", Path: "/api/v1/items", SQLPath: "GET/items.sql"},
+        {Method: "GET", Path: "/api/v1/export", SQLPath: "GET/export.sql", RateLimit: "none"},
+    })
+
+    tests := []struct {
+        path    string
+        method  string
+        limited bool
+    }{
+        {"/api/v1/items", "get", true},
+        {"/api/v1/export", "get", false},
+        {GraphQLPath, "post", true},
+        {"/health", "get", false},
+    }
+    for _, test := range tests {
+        _, limited := operation(t, doc, test.path, test.method)["responses"].(map[string]interface{})["429"]
+        if limited != test.limited {
+            t.Errorf("%s %s: 429 documented = %v, want %v", test.method, test.path, limited, test.limited)
+        }
+    }
+
+    responses := doc["components"].(map[string]interface{})["responses"].(map[string]interface{})
+    headers, _ := responses["TooManyRequests"].(map[string]interface{})["headers"].(map[string]interface{})
+    if _, ok := headers["Retry-After"]; !ok {
+        t.Errorf("TooManyRequests does not document Retry-After: %v", responses["TooManyRequests"])
+    }
+
+    cfg.RateLimit = ""
+    doc = BuildOpenAPI(cfg, []Endpoint{{Method: "GET", Path: "/api/v1/items", SQLPath: "GET/items.sql"}})
+    if _, limited := operation(t, doc, "/api/v1/items", "get")["responses"].(map[string]interface{})["429"]; limited {
+        t.Error("429 documented without a rate limit")
+    }
+}
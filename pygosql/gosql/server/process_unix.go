@@ -0,0 +1,12 @@
+//go:build !windows
+
+// process_unix.go
+package server
+
+import "syscall"
+
+// processAlive reports whether a process with the given id exists
+func processAlive(pid int) bool {
+    err := syscall.Kill(pid, 0)
+    return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+// process_windows.go
+package server
+
+import "os"
+
+// processAlive reports whether a process with the given id exists
+func processAlive(pid int) bool {
+    process, err := os.FindProcess(pid)
+    if err != nil {
+        return false
+    }
+    process.Release()
+    return true
+}
@@ -0,0 +1,96 @@
+// ratelimit.go
+package server
+
+import (
+    "fmt"
+    "gosql/ratelimit"
+    "math"
+    "net"
+    "net/http"
+    "strconv"
+    "time"
+)
+
+// setupRateLimits parses the default limit and creates the limiter
+func (s *Server) setupRateLimits() error {
+    defaultLimit, err := ratelimit.ParseLimit(s.config.RateLimit)
+    if err != nil {
+        return err
+    }
+
+    s.limiter = ratelimit.NewLimiter()
+    s.defaultLimit = defaultLimit
+    return nil
+}
+
+// endpointRateLimits parses the @ratelimit overrides of the endpoints by endpointKey
+func endpointRateLimits(endpoints []Endpoint) (map[string]ratelimit.Limit, error) {
+    limits := make(map[string]ratelimit.Limit)
+    for _, endpoint := range endpoints {
+        if endpoint.RateLimit == "" {
+            continue
+        }
+        limit, err := ratelimit.ParseLimit(endpoint.RateLimit)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", endpoint.SQLPath, err)
+        }
+        limits[endpointKey(endpoint)] = limit
+    }
+    return limits, nil
+}
+
+// endpointKey identifies an endpoint by method and route
+func endpointKey(endpoint Endpoint) string {
+    return endpoint.Method + " " + endpoint.Path
+}
+
+// rateLimit takes a token for the caller and sets the RateLimit-* headers
+// Callers over their limit get a 429 response and false is returned
+func (s *Server) rateLimit(w http.ResponseWriter, r *http.Request, endpoint Endpoint) bool {
+    scope := endpointKey(endpoint)
+    limit, override := s.routes.Load().limits[scope]
+    if !override {
+        limit = s.defaultLimit
+    }
+    if limit.Unlimited() {
+        return true
+    }
+
+    // Endpoint overrides get their own bucket; the default bucket is shared across endpoints
+    bucket := "default|" + clientKey(r)
+    if override {
+        bucket = scope + "|" + clientKey(r)
+    }
+    decision := s.limiter.Take(scope, bucket, limit)
+
+    header := w.Header()
+    header.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+    header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+    header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+    header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit, ceilSeconds(time.Duration(float64(decision.Limit)/limit.Rate*float64(time.Second)))))
+    if decision.Allowed {
+        return true
+    }
+
+    header.Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+    s.WriteJSONResponse(w, http.StatusTooManyRequests, map[string]interface{}{
+        "success": false,
+        "error":   fmt.Sprintf("Rate limit exceeded (%s). Retry in %d seconds", limit, ceilSeconds(decision.RetryAfter)),
+    })
+    return false
+}
+
+// clientKey identifies the caller by IP address; the limiter runs before authentication,
+// so credentials the client presents cannot pick a fresh bucket
+func clientKey(r *http.Request) string {
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        host = r.RemoteAddr
+    }
+    return "ip:" + host
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+    return int(math.Ceil(d.Seconds()))
+}
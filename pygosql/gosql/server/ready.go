@@ -0,0 +1,72 @@
+// ready.go
+package server
+
+import (
+    "encoding/json"
+    "fmt"
+    "net"
+    "os"
+)
+
+// ReadyInfo is the handshake line written once the server is accepting requests
+type ReadyInfo struct {
+    Event     string `json:"event"`            // Always "ready"
+    Port      int    `json:"port"`             // Bound TCP port, 0 when only the socket is served
+    Socket    string `json:"socket,omitempty"` // Unix socket path, when configured
+    BaseURL   string `json:"base_url"`         // Absolute URL of the API base
+    PID       int    `json:"pid"`              // Server process id
+    Endpoints int    `json:"endpoints"`        // Number of registered SQL endpoints
+    Version   string `json:"version"`          // API version
+}
+
+// readyInfo describes the open listeners for the startup handshake
+func (s *Server) readyInfo(listeners []net.Listener) ReadyInfo {
+    info := ReadyInfo{
+        Event:     "ready",
+        Socket:    s.config.UnixSocket,
+        PID:       os.Getpid(),
+        Endpoints: len(s.Endpoints()),
+        Version:   APIVersion,
+    }
+    // The first TCP listener gives the origin; with only the socket, clients reach it as
+    // http://localhost, whatever the TCP scheme
+    origin := ""
+    for _, l := range listeners {
+        if addr, ok := l.Addr().(*net.TCPAddr); ok {
+            info.Port = addr.Port
+            origin = s.listenerURL(l)
+            break
+        }
+    }
+    if origin == "" {
+        scheme := s.config.Scheme()
+        if len(listeners) > 0 {
+            scheme = s.listenerScheme(listeners[0])
+        }
+        origin = scheme + "://localhost"
+    }
+    info.BaseURL = origin + s.config.BaseURL
+    return info
+}
+
+// announceReady writes the handshake as a single JSON line to the configured descriptor
+// Descriptors other than stdout are closed afterwards so readers see end of file
+func (s *Server) announceReady(listeners []net.Listener) error {
+    if s.config.ReadyFD <= 0 {
+        return nil
+    }
+    line, err := json.Marshal(s.readyInfo(listeners))
+    if err != nil {
+        return err
+    }
+
+    out := os.Stdout
+    if s.config.ReadyFD != 1 {
+        out = os.NewFile(uintptr(s.config.ReadyFD), "ready-fd")
+        defer out.Close()
+    }
+    if _, err := out.Write(append(line, '\n')); err != nil {
+        return fmt.Errorf("failed to write ready line to descriptor %d: %w", s.config.ReadyFD, err)
+    }
+    return nil
+}
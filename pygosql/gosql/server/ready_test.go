@@ -0,0 +1,41 @@
+// ready_test.go
+package server
+
+import (
+    "net"
+    "path/filepath"
+    "strconv"
+    "testing"
+)
+
+func TestReadyInfoSchemePerListener(t *testing.T) {
+    socket, err := net.Listen("unix", filepath.Join(t.TempDir(), "gosql.sock"))
+    if err != nil {
+        t.Skipf("unix sockets unavailable: %v", err)
+    }
+    defer socket.Close()
+    tcp, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer tcp.Close()
+
+    s := newMetricsServer(t, "")
+    s.config.TLSAutoCert = true
+    cfg := s.config
+    port := tcp.Addr().(*net.TCPAddr).Port
+
+    tests := []struct {
+        name      string
+        listeners []net.Listener
+        want      string
+    }{
+        {"socket only", []net.Listener{socket}, "http://localhost" + cfg.BaseURL},
+        {"socket and TCP", []net.Listener{socket, tcp}, "https://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)) + cfg.BaseURL},
+    }
+    for _, test := range tests {
+        if got := s.readyInfo(test.listeners).BaseURL; got != test.want {
+            t.Errorf("%s: base_url %q, want %q", test.name, got, test.want)
+        }
+    }
+}
@@ -0,0 +1,30 @@
+// routes_test.go
+package server
+
+import "testing"
+
+func TestRouteAndMethodFromPath(t *testing.T) {
+    tests := []struct {
+        sqlPath string
+        method  string
+        route   string
+    }{
+        {"db/Tables/users/GET/select.sql", "GET", "/api/v1/users/select"},
+        {"db/Tables/users/GET/admin/list.sql", "GET", "/api/v1/users/admin/list"},
+        {"db/Tables/users/get/select.sql", "GET", "/api/v1/users/select"},
+        {"db/GET/reports/daily.sql", "GET", "/api/v1/reports/daily"},
+        {"db/post/reports/daily.sql", "POST", "/api/v1/reports/daily"},
+        // The method directory nearest the file decides both
+        {"db/GET/admin/DELETE/x.sql", "DELETE", "/api/v1/x"},
+        {"db/Tables/users/GET/admin/DELETE/x.sql", "DELETE", "/api/v1/users/x"},
+        {"/srv/get/app/sql/PUT/items.sql", "PUT", "/api/v1/items"},
+    }
+    for _, test := range tests {
+        if method := MethodFromPath(test.sqlPath); method != test.method {
+            t.Errorf("MethodFromPath(%q) = %s, want %s", test.sqlPath, method, test.method)
+        }
+        if route := RouteFromPath(test.sqlPath, "/api/v1"); route != test.route {
+            t.Errorf("RouteFromPath(%q) = %s, want %s", test.sqlPath, route, test.route)
+        }
+    }
+}
@@ -5,100 +5,300 @@ import (
     "context"
     "encoding/json"
     "fmt"
+    "gosql/auth"
+    "gosql/certs"
+    "gosql/database"
+    "gosql/graphql"
+    "gosql/logging"
+    "gosql/ratelimit"
     "gosql/setup"
-    "log"
+    "gosql/tracing"
+    "log/slog"
+    "net"
     "net/http"
-    "os"
-    "os/signal"
-    "syscall"
+    "sync"
+    "sync/atomic"
     "time"
 )
 
+// ShutdownTimeout bounds how long Start waits for in-flight requests when it stops
+const ShutdownTimeout = 30 * time.Second
+
 // Server manages the HTTP server with configured endpoints and middleware
 type Server struct {
-    config    setup.Config   // Server configuration
-    endpoints []Endpoint     // List of configured endpoints
-    mux       *http.ServeMux // HTTP request multiplexer
-    server    *http.Server   // Underlying HTTP server
+    config       setup.Config               // Server configuration
+    db           *database.Database         // Database backing the endpoints
+    server       *http.Server               // Underlying HTTP server
+    auth         *auth.Authenticator        // Request authenticator, nil when authentication is off
+    limiter      *ratelimit.Limiter         // Token buckets per client
+    defaultLimit ratelimit.Limit            // Limit for endpoints without a @ratelimit override
+    certs        *certs.Reloader            // TLS certificate source, nil when serving plain HTTP
+    metrics      *requestMetrics            // Per-endpoint request metrics
+    tracer       *tracing.Tracer            // Span exporter, nil when tracing is off
+    accessLog    *AccessLogger              // Access log writer, nil when the access log is off
+    routes       atomic.Pointer[routeTable] // Endpoints being served, replaced by SetEndpoints
+    shutdown     sync.Once                  // Runs the shutdown sequence once
+    shutdownErr  error                      // Result of the shutdown sequence
+    checks       healthChecks               // Readiness checks run by /readyz
+}
+
+// routeTable is an immutable set of served endpoints with their multiplexer,
+// GraphQL handler and rate limit overrides
+type routeTable struct {
+    endpoints []Endpoint                 // Endpoints in registration order
+    mux       *http.ServeMux             // Routes system and SQL endpoints
+    graphql   http.Handler               // GraphQL handler, nil when disabled
+    limits    map[string]ratelimit.Limit // @ratelimit overrides by endpointKey
+}
+
+// APIVersion is the version reported by the documentation endpoints
+const APIVersion = "1.0.0"
+
+// SystemRoute describes a built-in endpoint registered alongside the SQL endpoints
+type SystemRoute struct {
+    Path        string // HTTP route path
+    Method      string // HTTP method
+    Description string // Short description shown in the API documentation
+}
+
+// SystemRoutes lists the built-in endpoints that SQL endpoints must not shadow
+var SystemRoutes = []SystemRoute{
+    {Path: "/", Method: "GET", Description: "API documentation"},
+    {Path: "/health", Method: "GET", Description: "Health check"},
+    {Path: "/livez", Method: "GET", Description: "Liveness probe"},
+    {Path: "/readyz", Method: "GET", Description: "Readiness probe with database, migration, disk and endpoint checks"},
+    {Path: "/openapi.json", Method: "GET", Description: "OpenAPI 3.1 document"},
+    {Path: "/_codegen/python", Method: "GET", Description: "Typed Python client module"},
+    {Path: "/metrics", Method: "GET", Description: "Prometheus metrics"},
+    {Path: GraphQLPath, Method: "POST", Description: "GraphQL endpoint generated from the database schema"},
+}
+
+// GraphQLPath is the route of the optional GraphQL endpoint
+const GraphQLPath = "/graphql"
+
+// EnabledSystemRoutes returns the built-in endpoints served with the given configuration
+func EnabledSystemRoutes(cfg setup.Config) []SystemRoute {
+    routes := make([]SystemRoute, 0, len(SystemRoutes))
+    for _, route := range SystemRoutes {
+        if route.Path == GraphQLPath && !cfg.EnableGraphQL {
+            continue
+        }
+        routes = append(routes, route)
+    }
+    return routes
 }
 
 // NewServer creates a new Server instance with the given configuration and endpoints
-func NewServer(cfg setup.Config, endpoints []Endpoint) *Server {
+func NewServer(cfg setup.Config, endpoints []Endpoint, db *database.Database) (*Server, error) {
     s := &Server{
-        config:    cfg,
-        endpoints: endpoints,
-        mux:       http.NewServeMux(),
+        config:  cfg,
+        db:      db,
+        metrics: newRequestMetrics(),
+    }
+
+    if cfg.EnableAuth {
+        authenticator, err := NewAuthenticator(cfg, db)
+        if err != nil {
+            return nil, fmt.Errorf("failed to set up authentication: %w", err)
+        }
+        s.auth = authenticator
+    }
+
+    tracer, err := NewTracer(cfg.TraceExporter, cfg.TraceFile, cfg.TraceEndpoint)
+    if err != nil {
+        return nil, fmt.Errorf("failed to set up tracing: %w", err)
+    }
+    s.tracer = tracer
+
+    accessLog, err := NewAccessLogger(cfg.AccessLog, cfg.AccessLogFormat)
+    if err != nil {
+        return nil, fmt.Errorf("failed to set up access log: %w", err)
+    }
+    s.accessLog = accessLog
+
+    if err := s.setupRateLimits(); err != nil {
+        return nil, fmt.Errorf("failed to set up rate limits: %w", err)
     }
 
-    // Setup routes immediately
-    s.SetupRoutes()
+    s.registerDefaultChecks()
+
+    if err := s.SetEndpoints(endpoints); err != nil {
+        return nil, err
+    }
 
     // Create HTTP server with timeouts
     s.server = &http.Server{
-        Addr:         fmt.Sprintf(":%d", cfg.Port),
-        Handler:      s.mux,
+        Addr:         net.JoinHostPort(firstOr(cfg.BindAddresses, ""), fmt.Sprint(cfg.Port)),
+        Handler:      s.Handler(),
         ReadTimeout:  15 * time.Second,
         WriteTimeout: 15 * time.Second,
         IdleTimeout:  60 * time.Second,
     }
 
-    return s
+    if err := s.setupTLS(); err != nil {
+        return nil, fmt.Errorf("failed to set up TLS: %w", err)
+    }
+
+    return s, nil
+}
+
+// firstOr returns the first element of list, or fallback when it is empty
+func firstOr(list []string, fallback string) string {
+    if len(list) > 0 {
+        return list[0]
+    }
+    return fallback
+}
+
+// Handler returns the multiplexer wrapped in the server-wide middleware
+// Requests are routed with the endpoints current when they arrive
+func (s *Server) Handler() http.Handler {
+    var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        s.routes.Load().mux.ServeHTTP(w, r)
+    })
+    if s.config.EnableCORS {
+        handler = CORSMiddleware(s.config.CORS, handler)
+    }
+    return s.traceRequests(RequestLogger(s.accessLog.Middleware(handler)))
+}
+
+// Endpoints returns the SQL endpoints being served
+func (s *Server) Endpoints() []Endpoint {
+    return s.routes.Load().endpoints
 }
 
-// SetupRoutes registers all endpoint handlers with the HTTP multiplexer
-func (s *Server) SetupRoutes() {
+// SetEndpoints replaces the served SQL endpoints without interrupting requests in flight
+// The GraphQL schema is rebuilt too, so it reflects the current database schema
+// The endpoints are checked first; on error the current endpoints keep being served
+func (s *Server) SetEndpoints(endpoints []Endpoint) error {
+    if err := ValidateEndpoints(s.config, endpoints); err != nil {
+        return err
+    }
+    limits, err := endpointRateLimits(endpoints)
+    if err != nil {
+        return fmt.Errorf("failed to set up rate limits: %w", err)
+    }
+
+    routes := &routeTable{endpoints: endpoints, mux: http.NewServeMux(), limits: limits}
+
     // Register system endpoints
-    s.mux.HandleFunc("/health", s.HealthHandler)
-    s.mux.HandleFunc("/", s.RootHandler)
+    routes.mux.HandleFunc("/health", s.requireAuth("/health", s.HealthHandler))
+    routes.mux.HandleFunc("/livez", s.requireAuth("/livez", s.LivezHandler))
+    routes.mux.HandleFunc("/readyz", s.requireAuth("/readyz", s.ReadyzHandler))
+    routes.mux.HandleFunc("/openapi.json", s.requireAuth("/openapi.json", s.OpenAPIHandler))
+    routes.mux.HandleFunc("/_codegen/python", s.requireAuth("/_codegen/python", s.CodegenPythonHandler))
+    routes.mux.HandleFunc("/metrics", s.requireAuth("/metrics", s.MetricsHandler))
+    routes.mux.HandleFunc("/", s.requireAuth("/", s.RootHandler))
+
+    if s.config.EnableGraphQL {
+        schema, err := graphql.NewSQLiteSchema(s.db)
+        if err != nil {
+            slog.Error("failed to build GraphQL schema", "error", err)
+        } else {
+            handler := graphql.NewHandler(schema)
+            handler.Authorize = s.authorizeGraphQL
+            routes.graphql = handler
+            routes.mux.HandleFunc(GraphQLPath, s.graphqlHandler())
+            slog.Debug("registered GraphQL endpoint", "path", GraphQLPath)
+        }
+    }
 
     // Register API endpoints
-    for _, endpoint := range s.endpoints {
-        log.Printf("Registering endpoint: %s %s -> %s", endpoint.Method, endpoint.Path, endpoint.SQLPath)
-        s.mux.HandleFunc(endpoint.Path, s.wrapHandler(endpoint))
+    for _, endpoint := range endpoints {
+        slog.Debug("registering endpoint", "method", endpoint.Method, "path", endpoint.Path, "sql_file", endpoint.SQLPath)
+        routes.mux.HandleFunc(endpoint.Path, s.wrapHandler(endpoint))
     }
 
-    log.Printf("Registered %d API endpoints", len(s.endpoints))
+    s.routes.Store(routes)
+    slog.Info("registered API endpoints", "count", len(endpoints))
+    return nil
 }
 
 // wrapHandler wraps endpoint handlers with middleware (CORS, method validation, etc.)
 func (s *Server) wrapHandler(endpoint Endpoint) http.HandlerFunc {
+    return s.instrument(endpoint, endpoint.Scopes, func(w http.ResponseWriter, r *http.Request) {
+        // Validate HTTP method
+        if r.Method != endpoint.Method {
+            s.WriteJSONResponse(w, http.StatusMethodNotAllowed, map[string]interface{}{
+                "success": false,
+                "error":   fmt.Sprintf("Method %s not allowed. Expected %s", r.Method, endpoint.Method),
+            })
+            return
+        }
+
+        logging.FromContext(r.Context()).Debug("executing endpoint", "sql_file", endpoint.SQLPath)
+
+        // Call the actual endpoint handler
+        endpoint.Handler(w, r)
+    })
+}
+
+// graphqlHandler sends /graphql through the same rate limiting, authentication and
+// metrics as the SQL endpoints, counted under the request's method
+func (s *Server) graphqlHandler() http.HandlerFunc {
+    var scopes []string
+    if s.config.GraphQLRead != "" {
+        scopes = []string{s.config.GraphQLRead}
+    }
+    get := s.instrument(Endpoint{Method: "GET", Path: GraphQLPath}, scopes, s.GraphQLHandler)
+    post := s.instrument(Endpoint{Method: "POST", Path: GraphQLPath}, scopes, s.GraphQLHandler)
     return func(w http.ResponseWriter, r *http.Request) {
-        // Enable CORS if configured
-        if s.config.EnableCORS {
-            s.EnableCORS(w, r)
+        if r.Method == "GET" {
+            get(w, r)
+            return
         }
+        post(w, r)
+    }
+}
 
-        // Handle preflight requests
+// instrument runs a handler behind rate limiting and authentication, and records the
+// request's metrics and span name under the endpoint
+// The rate limit comes first and is keyed by client IP, so floods of bad credentials are
+// throttled before any key lookup
+func (s *Server) instrument(endpoint Endpoint, scopes []string, handler http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        // Preflight requests are answered by the CORS middleware; other OPTIONS succeed here
         if r.Method == "OPTIONS" {
             w.WriteHeader(http.StatusOK)
             return
         }
+        r = withEndpoint(r, endpoint)
+        if span := tracing.SpanFromContext(r.Context()); span != nil {
+            span.SetName(endpoint.Method + " " + endpoint.Path)
+            span.SetAttribute("http.route", endpoint.Path)
+        }
 
-        // Validate HTTP method
-        if r.Method != endpoint.Method {
-            s.WriteJSONResponse(w, http.StatusMethodNotAllowed, map[string]interface{}{
-                "success": false,
-                "error":   fmt.Sprintf("Method %s not allowed. Expected %s", r.Method, endpoint.Method),
-            })
+        // Count every outcome, including rejected requests, and time the handler's phases
+        start := time.Now()
+        stats := statsFrom(r.Context())
+        if stats == nil {
+            stats = newRequestStats()
+            r = r.WithContext(withStats(r.Context(), stats))
+        }
+        stats.route = endpoint.Path
+        recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+        w = recorder
+        defer func() {
+            s.metrics.observe(endpoint, recorder.status, time.Since(start), stats)
+        }()
+
+        // Throttle per client IP before authenticating or touching the database
+        if !s.rateLimit(w, r, endpoint) {
             return
         }
 
-        // Add debug information if enabled
-        if s.config.DebugMode {
-            log.Printf("%s %s - Executing %s", r.Method, r.URL.Path, endpoint.SQLPath)
+        // Authenticate the caller and check the endpoint's scopes
+        r, ok := s.authorize(w, r, scopes)
+        if !ok {
+            return
         }
 
-        // Call the actual endpoint handler
-        endpoint.Handler(w, r)
+        handler(w, r)
     }
 }
 
 // HealthHandler responds to health check requests with server status information
 func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
-    if s.config.EnableCORS {
-        s.EnableCORS(w, r)
-    }
-
     if r.Method == "OPTIONS" {
         w.WriteHeader(http.StatusOK)
         return
@@ -112,25 +312,41 @@ func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    status, code := "healthy", http.StatusOK
+    if s.db != nil && !s.db.IsHealthy() {
+        status, code = "unhealthy", http.StatusServiceUnavailable
+    }
+
     healthData := map[string]interface{}{
-        "status":      "healthy",
+        "status":      status,
         "timestamp":   time.Now().Format(time.RFC3339),
-        "endpoints":   len(s.endpoints),
+        "endpoints":   len(s.Endpoints()),
         "port":        s.config.Port,
         "base_url":    s.config.BaseURL,
         "cors_enabled": s.config.EnableCORS,
         "debug_mode":  s.config.DebugMode,
     }
 
-    s.WriteJSONResponse(w, http.StatusOK, healthData)
+    s.WriteJSONResponse(w, code, healthData)
 }
 
-// RootHandler serves the root endpoint with API documentation and available endpoints
-func (s *Server) RootHandler(w http.ResponseWriter, r *http.Request) {
-    if s.config.EnableCORS {
-        s.EnableCORS(w, r)
+// GraphQLHandler serves GraphQL queries and mutations against the database schema
+func (s *Server) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "OPTIONS" {
+        w.WriteHeader(http.StatusOK)
+        return
     }
 
+    graphqlHandler := s.routes.Load().graphql
+    if graphqlHandler == nil {
+        http.NotFound(w, r)
+        return
+    }
+    graphqlHandler.ServeHTTP(w, r)
+}
+
+// RootHandler serves the root endpoint with API documentation and available endpoints
+func (s *Server) RootHandler(w http.ResponseWriter, r *http.Request) {
     if r.Method == "OPTIONS" {
         w.WriteHeader(http.StatusOK)
         return
@@ -150,83 +366,138 @@ func (s *Server) RootHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    // Browsers get the HTML documentation page, API clients keep the JSON listing
+    if WantsHTML(r) {
+        s.DocsHTMLHandler(w, r)
+        return
+    }
+
     // Build endpoint documentation
-    endpointDocs := make([]map[string]interface{}, 0, len(s.endpoints))
-    for _, endpoint := range s.endpoints {
+    endpoints := s.Endpoints()
+    endpointDocs := make([]map[string]interface{}, 0, len(endpoints))
+    for _, endpoint := range endpoints {
         endpointDocs = append(endpointDocs, map[string]interface{}{
             "path":         endpoint.Path,
             "method":       endpoint.Method,
             "table_name":   endpoint.TableName,
+            "function":     FunctionName(endpoint, s.config.BaseURL),
             "is_universal": endpoint.IsUniversal,
             "sql_path":     endpoint.SQLPath,
         })
     }
 
+    systemRoutes := EnabledSystemRoutes(s.config)
+    systemDocs := make([]map[string]interface{}, 0, len(systemRoutes))
+    for _, route := range systemRoutes {
+        systemDocs = append(systemDocs, map[string]interface{}{
+            "path":        route.Path,
+            "method":      route.Method,
+            "description": route.Description,
+        })
+    }
+
     rootData := map[string]interface{}{
         "message":     "GoSQL HTTP API Server",
-        "version":     "1.0.0",
+        "version":     APIVersion,
         "base_url":    s.config.BaseURL,
         "endpoints":   endpointDocs,
-        "system_endpoints": []map[string]interface{}{
-            {"path": "/", "method": "GET", "description": "API documentation"},
-            {"path": "/health", "method": "GET", "description": "Health check"},
-        },
-        "total_endpoints": len(s.endpoints),
+        "system_endpoints": systemDocs,
+        "total_endpoints": len(endpoints),
         "timestamp":       time.Now().Format(time.RFC3339),
     }
 
     s.WriteJSONResponse(w, http.StatusOK, rootData)
 }
 
-// Start begins listening for HTTP requests on the configured port
-func (s *Server) Start() error {
-    // Setup graceful shutdown
-    stop := make(chan os.Signal, 1)
-    signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-
-    // Start server in goroutine
-    go func() {
-        log.Printf("🚀 Server starting on port %d", s.config.Port)
-        log.Printf("📡 API base URL: http://localhost:%d%s", s.config.Port, s.config.BaseURL)
-        log.Printf("❤️  Health check: http://localhost:%d/health", s.config.Port)
-        log.Printf("📚 Documentation: http://localhost:%d/", s.config.Port)
-        log.Printf("🔧 Debug mode: %v", s.config.DebugMode)
-        log.Printf("🌐 CORS enabled: %v", s.config.EnableCORS)
-
-        if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-            log.Fatalf("Server failed to start: %v", err)
-        }
-    }()
-
-    // Wait for interrupt signal
-    <-stop
-    log.Println("🛑 Shutting down server...")
+// Start listens on every configured address and serves until ctx is cancelled, the
+// watched parent process goes away or a listener fails, then shuts down gracefully
+// Listening errors are returned before serving begins
+// Start installs no signal handlers; callers cancel ctx on the signals they handle
+func (s *Server) Start(ctx context.Context) error {
+    listeners, err := s.Listen()
+    if err != nil {
+        return err
+    }
+    if ready := s.readyInfo(listeners); ready.Port != 0 {
+        s.config.Port = ready.Port
+    }
 
-    return s.Shutdown()
-}
+    origin := s.listenerURL(listeners[0])
+    for _, l := range listeners {
+        slog.Info("server listening", "url", s.listenerURL(l))
+    }
+    slog.Info("serving API",
+        "base_url", origin+s.config.BaseURL,
+        "health", origin+"/health",
+        "docs", origin+"/",
+        "debug", s.config.DebugMode,
+        "cors", s.config.EnableCORS)
+
+    // Serve every listener in its own goroutine
+    // Decided up front because Serve fills in TLSConfig for HTTP/2
+    // Unix sockets stay plain HTTP: the file mode guards them and local clients speak http
+    useTLS := s.server.TLSConfig != nil
+    errs := make(chan error, len(listeners))
+    for _, l := range listeners {
+        go func(l net.Listener) {
+            if useTLS && l.Addr().Network() != "unix" {
+                errs <- s.server.ServeTLS(l, "", "")
+            } else {
+                errs <- s.server.Serve(l)
+            }
+        }(l)
+    }
 
-// Shutdown gracefully stops the HTTP server
-func (s *Server) Shutdown() error {
-    // Create shutdown context with timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-    defer cancel()
+    if err := s.announceReady(listeners); err != nil {
+        slog.Error("failed to announce readiness", "error", err)
+    }
 
-    // Attempt graceful shutdown
-    if err := s.server.Shutdown(ctx); err != nil {
-        log.Printf("❌ Server forced to shutdown: %v", err)
-        return err
+    // Wait for cancellation, the launching process going away, or a listener failure
+    select {
+    case <-ctx.Done():
+        slog.Info("shutting down server", "reason", context.Cause(ctx))
+    case reason := <-s.watchParent():
+        slog.Info("shutting down server", "reason", reason)
+    case err := <-errs:
+        if err == http.ErrServerClosed {
+            // Shutdown was called directly; wait for it to finish
+            return s.shutdownNow()
+        }
+        s.shutdownNow()
+        return fmt.Errorf("server failed: %w", err)
     }
 
-    log.Println("✅ Server gracefully stopped")
-    return nil
+    return s.shutdownNow()
 }
 
-// EnableCORS adds CORS headers to HTTP responses if enabled in config
-func (s *Server) EnableCORS(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Access-Control-Allow-Origin", "*")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-    w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
+// shutdownNow shuts down with ShutdownTimeout to finish requests in flight
+func (s *Server) shutdownNow() error {
+    ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+    defer cancel()
+    return s.Shutdown(ctx)
+}
+
+// Shutdown gracefully stops the HTTP server, waiting for requests in flight until ctx
+// is done, then flushes spans and closes the access log
+// Only the first call has an effect; later calls return its result
+func (s *Server) Shutdown(ctx context.Context) error {
+    s.shutdown.Do(func() {
+        // Attempt graceful shutdown, then flush spans of the requests that just finished
+        if err := s.server.Shutdown(ctx); err != nil {
+            slog.Error("server forced to shut down", "error", err)
+            s.shutdownErr = err
+            return
+        }
+        if err := s.tracer.Shutdown(ctx); err != nil {
+            slog.Warn("failed to flush trace spans", "error", err)
+        }
+        if err := s.accessLog.Close(); err != nil {
+            slog.Warn("failed to close access log", "error", err)
+        }
+
+        slog.Info("server stopped")
+    })
+    return s.shutdownErr
 }
 
 // WriteJSONResponse writes a JSON response with the given status code and data
@@ -234,8 +505,8 @@ func (s *Server) WriteJSONResponse(w http.ResponseWriter, statusCode int, data i
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(statusCode)
 
-    if err := json.NewEncoder(w).Encode(data); err != nil {
-        log.Printf("Failed to encode JSON response: %v", err)
+    if err := json.NewEncoder(w).Encode(withRequestID(w, data)); err != nil {
+        slog.Error("failed to encode JSON response", "error", err)
         // Try to write a basic error response
         w.WriteHeader(http.StatusInternalServerError)
         fmt.Fprintf(w, `{"success":false,"error":"JSON encoding failed"}`)
@@ -2,15 +2,24 @@
 package server
 
 import (
+    "context"
+    "database/sql"
     "encoding/json"
     "fmt"
+    "gosql/auth"
     "gosql/database"
+    "gosql/logging"
+    "gosql/setup"
+    "gosql/tracing"
+    "log/slog"
     "net/http"
     "path/filepath"
     "regexp"
     "strings"
+    "time"
     "os"
-    "log"
+    "sort"
+    "unicode"
 )
 
 // Endpoint represents an HTTP endpoint with its routing and SQL execution details
@@ -21,6 +30,15 @@ type Endpoint struct {
     SQLPath     string            // Path to the SQL file
     TableName   string            // Table name (empty for universal endpoints)
     IsUniversal bool              // Whether this is a universal endpoint
+    Description string            // Description from the SQL file's @description header
+    Params      []database.Param  // Declared and inferred request parameters
+    Columns     []database.Column // Result columns for row-returning queries
+    Positional  int               // Number of positional ? parameters in the SQL
+    ReturnsRows bool              // Whether the SQL is a row-returning SELECT
+    BodyColumns bool              // Whether {{columns}}/{{values}}/{{updates}} are built from the request
+    Scopes      []string          // Scopes a caller needs, from the SQL file's @scope headers
+    RateLimit   string            // Per-client limit override from the SQL file's @ratelimit header
+    LoadError   string            // Why the SQL file could not be read, empty when it loaded
 }
 
 // GlobSQLFiles recursively finds all .sql files in the given root directory
@@ -32,6 +50,11 @@ func GlobSQLFiles(rootPath string) ([]string, error) {
             return err
         }
 
+        // Migrations change the schema; they are not endpoints
+        if info.IsDir() && path == filepath.Join(rootPath, database.MigrationsDir) {
+            return filepath.SkipDir
+        }
+
         if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".sql") {
             sqlFiles = append(sqlFiles, path)
         }
@@ -47,7 +70,9 @@ func GlobSQLFiles(rootPath string) ([]string, error) {
 }
 
 // RouteFromPath converts a SQL file path to an HTTP route path
+// Folders nested below the method directory are preserved in the route
 // Example: "db/Tables/users/GET/select.sql" -> "/api/v1/users/select"
+// Example: "db/GET/reports/daily.sql" -> "/api/v1/reports/daily"
 func RouteFromPath(sqlPath string, baseURL string) string {
     // Normalize path separators
     normalizedPath := filepath.ToSlash(sqlPath)
@@ -64,19 +89,43 @@ func RouteFromPath(sqlPath string, baseURL string) string {
         }
     }
 
+    methodIndex := methodDirIndex(parts)
     if tablesIndex == -1 || tablesIndex+3 >= len(parts) {
         // Not a table-specific path, treat as universal
-        filename := filepath.Base(sqlPath)
-        name := strings.TrimSuffix(filename, ".sql")
-        return fmt.Sprintf("%s/%s", baseURL, name)
+        if methodIndex < 0 {
+            methodIndex = len(parts) - 2
+        }
+        return fmt.Sprintf("%s/%s", baseURL, routeSegments(parts[methodIndex+1:]))
     }
 
-    // Extract table name and endpoint name
+    // Extract table name and everything below the method directory
     tableName := parts[tablesIndex+1]
-    filename := parts[len(parts)-1]
-    endpointName := strings.TrimSuffix(filename, ".sql")
+    if methodIndex <= tablesIndex {
+        methodIndex = tablesIndex + 2
+    }
+
+    return fmt.Sprintf("%s/%s/%s", baseURL, tableName, routeSegments(parts[methodIndex+1:]))
+}
+
+// methodDirIndex returns the index of the innermost HTTP method directory in a split
+// path, matched case-insensitively, or -1 when the file is not inside one
+// RouteFromPath and MethodFromPath both use it, so "GET/admin/DELETE/x.sql" is DELETE /x
+func methodDirIndex(parts []string) int {
+    for i := len(parts) - 2; i >= 0; i-- {
+        switch strings.ToUpper(parts[i]) {
+        case "GET", "POST", "PUT", "DELETE":
+            return i
+        }
+    }
+    return -1
+}
 
-    return fmt.Sprintf("%s/%s/%s", baseURL, tableName, endpointName)
+// routeSegments joins the path components below a method directory into a route suffix
+func routeSegments(parts []string) string {
+    segments := make([]string, len(parts))
+    copy(segments, parts)
+    segments[len(segments)-1] = strings.TrimSuffix(segments[len(segments)-1], ".sql")
+    return strings.Join(segments, "/")
 }
 
 // MethodFromPath extracts the HTTP method from a SQL file path
@@ -86,16 +135,9 @@ func MethodFromPath(sqlPath string) string {
     normalizedPath := filepath.ToSlash(sqlPath)
     parts := strings.Split(normalizedPath, "/")
 
-    // Look for HTTP method directory names
-    httpMethods := []string{"GET", "POST", "PUT", "DELETE"}
-
-    for _, part := range parts {
-        upperPart := strings.ToUpper(part)
-        for _, method := range httpMethods {
-            if upperPart == method {
-                return method
-            }
-        }
+    // The method directory nearest the file, the same one the route starts below
+    if i := methodDirIndex(parts); i >= 0 {
+        return strings.ToUpper(parts[i])
     }
 
     // Fallback: infer from filename
@@ -117,29 +159,140 @@ func MethodFromPath(sqlPath string) string {
 }
 
 // ExecuteSQLFromPath loads and executes a SQL file with the provided parameters
+// Reserved auth_ parameters are never taken from params; they bind as NULL
 func ExecuteSQLFromPath(db *database.Database, sqlPath string, params map[string]interface{}) (interface{}, error) {
+    return ExecuteSQLAs(db, sqlPath, params, nil)
+}
+
+// ExecuteSQLAs executes a SQL file like ExecuteSQLFromPath, binding the reserved auth_
+// parameters the SQL references from the caller's identity (see auth.Params)
+func ExecuteSQLAs(db *database.Database, sqlPath string, params map[string]interface{}, reserved map[string]interface{}) (interface{}, error) {
+    return ExecuteSQLContext(context.Background(), db, sqlPath, params, reserved)
+}
+
+// ExecuteSQLContext is ExecuteSQLAs bound to a request context, logging to its request-scoped logger
+func ExecuteSQLContext(ctx context.Context, db *database.Database, sqlPath string, params map[string]interface{}, reserved map[string]interface{}) (interface{}, error) {
+    prepared, err := PrepareSQL(ctx, db, sqlPath, params, reserved)
+    if err != nil {
+        return nil, err
+    }
+
+    // Only names and redactable values are logged, never the expanded statement
+    logger := logging.FromContext(ctx)
+    if logger.Enabled(ctx, slog.LevelDebug) {
+        logger.DebugContext(ctx, "executing SQL file",
+            "sql_file", sqlPath,
+            "template_vars", prepared.Templates,
+            logging.Params("params", prepared.Params))
+    }
+
+    // Execute SQL
+    ctx, span := tracing.Start(ctx, "execute statement")
+    defer span.Finish()
+    span.SetAttribute("db.system", "sqlite")
+    span.SetAttribute("gosql.sql_file", sqlPath)
+    result, err := db.ExecSQLContext(ctx, prepared.SQL, prepared.Args...)
+    span.SetError(err)
+    if rows, ok := result.([][]interface{}); ok && len(rows) > 0 {
+        span.SetAttribute("db.response.returned_rows", len(rows)-1)
+    }
+    return result, err
+}
+
+// PreparedSQL is a SQL file's statement ready to run
+type PreparedSQL struct {
+    SQL       string                 // Statement with {{variable}} templates expanded
+    Args      []interface{}          // Bound arguments in order; sql.NamedArg for named parameters
+    Params    map[string]interface{} // Client parameters the statement was prepared with, auth_ ones removed
+    Templates []string               // {{variable}} names in the SQL file
+}
+
+// PrepareSQL loads a SQL file, expands its templates and builds its arguments exactly as
+// ExecuteSQLContext runs them; the database is only read for the table's columns when
+// the file uses {{columns}}, {{values}} or {{updates}}
+func PrepareSQL(ctx context.Context, db *database.Database, sqlPath string, params map[string]interface{}, reserved map[string]interface{}) (PreparedSQL, error) {
+    // Clients must not be able to impersonate another caller through auth_ parameters
+    clientParams := make(map[string]interface{}, len(params))
+    for key, value := range params {
+        if !auth.IsReserved(key) {
+            clientParams[key] = value
+        }
+    }
+    params = clientParams
+
     // Load SQL file
     sqlFile, err := database.LoadSQL(sqlPath)
     if err != nil {
-        return nil, fmt.Errorf("failed to load SQL file %s: %w", sqlPath, err)
+        return PreparedSQL{}, fmt.Errorf("failed to load SQL file %s: %w", sqlPath, err)
     }
 
     if sqlFile.IsEmpty() {
-        return nil, fmt.Errorf("SQL file is empty: %s", sqlPath)
+        return PreparedSQL{}, fmt.Errorf("SQL file is empty: %s", sqlPath)
     }
 
     // Extract table name and process template
+    _, span := tracing.Start(ctx, "expand template")
+    span.SetAttribute("gosql.sql_file", sqlPath)
     tableName := ExtractTableName(sqlPath)
-    processedSQL := ProcessSQLTemplate(sqlFile.Content, tableName, params)
+    templates := database.FindPlaceholders(sqlFile.Content).Templates
+    var columns []string
+    for _, name := range templates {
+        if IsTemplateVariable(name) && name != "table" && tableName != "" && db != nil {
+            tableColumns, err := db.TableColumns(tableName)
+            if err != nil {
+                span.Finish()
+                return PreparedSQL{}, fmt.Errorf("failed to read the columns of %s: %w", tableName, err)
+            }
+            for _, column := range tableColumns {
+                columns = append(columns, column.Name)
+            }
+            break
+        }
+    }
+    processedSQL, columnArgs := ProcessSQLTemplate(sqlFile.Content, tableName, columns, params)
+    span.Finish()
 
-    // Convert params map to slice for sql.DB
+    // Convert params map to slice for sql.DB, named so :name placeholders can bind
     var args []interface{}
-    for _, value := range params {
-        args = append(args, value)
+    for _, key := range sortedKeys(params) {
+        if isNamedArg(key) {
+            args = append(args, sql.Named(key, params[key]))
+        } else {
+            args = append(args, params[key])
+        }
+    }
+    args = append(args, columnArgs...)
+    for _, name := range database.FindPlaceholders(processedSQL).Named {
+        if auth.IsReserved(name) {
+            args = append(args, sql.Named(name, reserved[name]))
+        }
     }
 
-    // Execute SQL
-    return db.ExecSQL(processedSQL, args...)
+    return PreparedSQL{
+        SQL:       processedSQL,
+        Args:      args,
+        Params:    params,
+        Templates: templates,
+    }, nil
+}
+
+// sortedKeys returns the keys of a parameter map in a stable order
+func sortedKeys(params map[string]interface{}) []string {
+    keys := make([]string, 0, len(params))
+    for key := range params {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// isNamedArg reports whether a parameter name is accepted by sql.Named
+func isNamedArg(name string) bool {
+    if name == "" {
+        return false
+    }
+    first := rune(name[0])
+    return unicode.IsLetter(first)
 }
 
 // DefaultRoutesPerTable generates standard CRUD endpoints for a given table
@@ -187,7 +340,7 @@ func DefaultRoutesPerTable(tableName string, db *database.Database) []Endpoint {
 
 // AssembleEndpoint creates a complete Endpoint from a SQL file path and database connection
 func AssembleEndpoint(sqlPath string, db *database.Database, baseURL string) Endpoint {
-    return Endpoint{
+    endpoint := Endpoint{
         Path:        RouteFromPath(sqlPath, baseURL),
         Method:      MethodFromPath(sqlPath),
         Handler:     CreateHandler(db, sqlPath),
@@ -195,50 +348,67 @@ func AssembleEndpoint(sqlPath string, db *database.Database, baseURL string) End
         TableName:   ExtractTableName(sqlPath),
         IsUniversal: !strings.Contains(sqlPath, "Tables/"),
     }
+    DescribeEndpoint(&endpoint, db)
+    return endpoint
 }
 
 // CreateHandler creates an HTTP handler function that executes the SQL file at the given path
 func CreateHandler(db *database.Database, sqlPath string) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
-        // Set CORS headers
-        w.Header().Set("Access-Control-Allow-Origin", "*")
-        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
         // Handle preflight requests
         if r.Method == "OPTIONS" {
             w.WriteHeader(http.StatusOK)
             return
         }
 
+        stats := statsFrom(r.Context())
+
         // Extract parameters from request
+        start := time.Now()
+        _, span := tracing.Start(r.Context(), "extract params")
         params, err := ExtractRequestParams(r)
+        span.SetError(err)
+        span.Finish()
+        stats.phase(PhaseParse, start)
         if err != nil {
             WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Failed to extract parameters: %v", err))
             return
         }
 
-        // Execute SQL
-        result, err := ExecuteSQLFromPath(db, sqlPath, params)
+        // Execute SQL as the authenticated caller
+        start = time.Now()
+        result, err := ExecuteSQLContext(r.Context(), db, sqlPath, params, auth.Params(auth.FromContext(r.Context())))
+        stats.phase(PhaseExecute, start)
         if err != nil {
+            logger := logging.FromContext(r.Context())
+
             // Check if it's a constraint error (client error)
             if isConstraintError(err) {
-                log.Printf("   - Constraint violation: %v", err)
+                logger.Info("constraint violation", "error", err)
                 WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Data validation failed: %v", err))
                 return
             }
 
             // Otherwise it's a server error
-            log.Printf("   - Server error: %v", err)
+            logger.Error("SQL execution failed", "error", err)
             WriteErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("SQL execution failed: %v", err))
             return
         }
 
+        // Row results start with a header row
+        if rows, ok := result.([][]interface{}); ok && len(rows) > 0 {
+            stats.setRows(len(rows) - 1)
+        }
+
         // Write success response
+        start = time.Now()
+        _, span = tracing.Start(r.Context(), "encode response")
         WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
             "success": true,
             "data":    result,
         })
+        span.Finish()
+        stats.phase(PhaseEncode, start)
     }
 }
 
@@ -269,12 +439,11 @@ func ExtractTableName(sqlPath string) string {
     return "" // Not a table-specific path
 }
 
-func ProcessSQLTemplate(sqlContent string, tableName string, params map[string]interface{}) string {
-    log.Printf("   - ProcessSQLTemplate called:")
-    log.Printf("   - Original SQL: %s", sqlContent)
-    log.Printf("   - Table name: %s", tableName)
-    log.Printf("   - Parameters: %+v", params)
-
+// ProcessSQLTemplate expands {{variable}} templates from the table name and parameters
+// {{columns}}, {{values}} and {{updates}} are built from the parameters named after one of
+// columns: names are quoted and values bound as named arguments, which are returned
+// Nothing is logged here: the expanded statement can contain client values
+func ProcessSQLTemplate(sqlContent string, tableName string, columns []string, params map[string]interface{}) (string, []interface{}) {
     // Add table name to params for {{table}} replacement
     allParams := make(map[string]interface{})
     for k, v := range params {
@@ -282,35 +451,38 @@ func ProcessSQLTemplate(sqlContent string, tableName string, params map[string]i
     }
     if tableName != "" {
         allParams["table"] = tableName
-        log.Printf("   - Added table to params: %s", tableName)
     }
 
-    // SPECIAL HANDLING: Generate columns and values from actual JSON data
-    if len(params) > 0 {
-        var columns []string
-        var values []string
-
-        for key, value := range params {
-            columns = append(columns, key)
-            values = append(values, fmt.Sprintf("'%v'", value))
+    // Column templates never put client text into the statement: keys must name a real
+    // column and values are bound
+    var args []interface{}
+    var names, values, updates []string
+    for _, key := range sortedKeys(params) {
+        for _, column := range columns {
+            if !strings.EqualFold(key, column) {
+                continue
+            }
+            bind := fmt.Sprintf("gosql_column_%d", len(args))
+            args = append(args, sql.Named(bind, params[key]))
+            names = append(names, setup.QuoteName(column))
+            values = append(values, ":"+bind)
+            updates = append(updates, setup.QuoteName(column)+" = :"+bind)
+            break
         }
-
-        allParams["columns"] = strings.Join(columns, ", ")
+    }
+    if len(names) > 0 {
+        allParams["columns"] = strings.Join(names, ", ")
         allParams["values"] = strings.Join(values, ", ")
-
-        log.Printf("   - Generated columns: %s", allParams["columns"])
-        log.Printf("   - Generated values: %s", allParams["values"])
+        allParams["updates"] = strings.Join(updates, ", ")
+    } else {
+        delete(allParams, "columns")
+        delete(allParams, "values")
+        delete(allParams, "updates")
     }
 
-    log.Printf("   - All available params: %+v", allParams)
-
     // Regex to find all {{variable}} patterns
     re := regexp.MustCompile(`\{\{(\w+)\}\}`)
 
-    // Find all matches first for logging
-    matches := re.FindAllString(sqlContent, -1)
-    log.Printf("   - Found template variables: %v", matches)
-
     result := re.ReplaceAllStringFunc(sqlContent, func(match string) string {
         // Extract variable name from {{variable}}
         varName := strings.Trim(match, "{}")
@@ -318,19 +490,14 @@ func ProcessSQLTemplate(sqlContent string, tableName string, params map[string]i
         // If variable exists in params, replace it
         if value, exists := allParams[varName]; exists {
             replacement := fmt.Sprintf("%v", value)
-            log.Printf("   - Replacing %s with '%s'", match, replacement)
             return replacement
         }
 
         // Leave unmatched templates as-is
-        log.Printf("   - No replacement found for %s - leaving as-is", match)
         return match
     })
 
-    log.Printf("Final SQL: %s", result)
-    log.Printf("ProcessSQLTemplate complete\n")
-
-    return result
+    return result, args
 }
 
 // Helper functions
@@ -346,6 +513,11 @@ func ExtractRequestParams(r *http.Request) (map[string]interface{}, error) {
         }
     }
 
+    // Extract wildcard segments such as {id} from the matched route
+    for _, name := range PathParamNames(r.Pattern) {
+        params[name] = r.PathValue(name)
+    }
+
     // Extract from body for POST/PUT requests
     if r.Method == "POST" || r.Method == "PUT" {
         var bodyParams map[string]interface{}
@@ -363,7 +535,7 @@ func ExtractRequestParams(r *http.Request) (map[string]interface{}, error) {
 func WriteJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(statusCode)
-    json.NewEncoder(w).Encode(data)
+    json.NewEncoder(w).Encode(withRequestID(w, data))
 }
 
 // WriteErrorResponse writes a JSON error response
@@ -0,0 +1,44 @@
+// template_test.go
+package server
+
+import (
+    "database/sql"
+    "testing"
+)
+
+func TestProcessSQLTemplateBindsColumnValues(t *testing.T) {
+    columns := []string{"id", "name", "first name"}
+    params := map[string]interface{}{
+        "name":                         "O'Brien",
+        "first name":                   "Pat",
+        "user_id = 'x', name":          "injected",
+        "name = name WHERE 1 = 1; --": "injected",
+    }
+
+    tests := []struct {
+        sql  string
+        want string
+    }{
+        {"INSERT INTO {{table}} ({{columns}}) VALUES ({{values}});", `INSERT INTO users ("first name", name) VALUES (:gosql_column_0, :gosql_column_1);`},
+        {"UPDATE {{table}} SET {{updates}} WHERE user_id = :auth_sub;", `UPDATE users SET "first name" = :gosql_column_0, name = :gosql_column_1 WHERE user_id = :auth_sub;`},
+    }
+    for _, test := range tests {
+        got, args := ProcessSQLTemplate(test.sql, "users", columns, params)
+        if got != test.want {
+            t.Errorf("ProcessSQLTemplate(%q)\n got: %s\nwant: %s", test.sql, got, test.want)
+        }
+        if len(args) != 2 {
+            t.Fatalf("expected 2 bound arguments, got %v", args)
+        }
+        if arg := args[1].(sql.NamedArg); arg.Name != "gosql_column_1" || arg.Value != "O'Brien" {
+            t.Errorf("unexpected argument %+v", arg)
+        }
+    }
+}
+
+func TestProcessSQLTemplateLeavesColumnTemplatesWithoutColumns(t *testing.T) {
+    got, args := ProcessSQLTemplate("UPDATE t SET {{updates}};", "t", []string{"id"}, map[string]interface{}{"other": 1})
+    if got != "UPDATE t SET {{updates}};" || len(args) != 0 {
+        t.Errorf("got %q with %v", got, args)
+    }
+}
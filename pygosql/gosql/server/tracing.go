@@ -0,0 +1,61 @@
+// tracing.go
+package server
+
+import (
+    "errors"
+    "fmt"
+    "gosql/tracing"
+    "net/http"
+    "os"
+)
+
+// ServiceName is the service.name reported with exported spans
+const ServiceName = "gosql"
+
+// NewTracer creates the tracer for the configured exporter, or nil when tracing is off
+func NewTracer(exporter, file, endpoint string) (*tracing.Tracer, error) {
+    switch exporter {
+    case "", "none":
+        return nil, nil
+    case "stderr":
+        // Not stdout, which carries the ready line launchers parse
+        return tracing.NewTracer(tracing.NewWriterExporter(os.Stderr), ServiceName), nil
+    case "file":
+        if file == "" {
+            return nil, errors.New("the file trace exporter needs a trace file")
+        }
+        fileExporter, err := tracing.NewFileExporter(file)
+        if err != nil {
+            return nil, err
+        }
+        return tracing.NewTracer(fileExporter, ServiceName), nil
+    case "otlp":
+        return tracing.NewTracer(tracing.NewOTLPExporter(endpoint), ServiceName), nil
+    default:
+        return nil, fmt.Errorf("unknown trace exporter %q: use stderr, file or otlp", exporter)
+    }
+}
+
+// traceRequests starts a server span for each request, continuing the client's trace
+// when it sends a W3C traceparent header
+func (s *Server) traceRequests(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        // Named by method only until wrapHandler knows the route, keeping span names bounded
+        ctx, span := s.tracer.StartServer(r.Context(), r.Method, r.Header)
+        if span == nil {
+            next.ServeHTTP(w, r)
+            return
+        }
+        span.SetAttribute("http.request.method", r.Method)
+        span.SetAttribute("url.path", r.URL.Path)
+
+        recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+        next.ServeHTTP(recorder, r.WithContext(ctx))
+
+        span.SetAttribute("http.response.status_code", recorder.status)
+        if recorder.status >= 500 {
+            span.SetError(errors.New(http.StatusText(recorder.status)))
+        }
+        span.Finish()
+    })
+}
@@ -0,0 +1,119 @@
+// validate.go
+package server
+
+import (
+    "fmt"
+    "gosql/setup"
+    "net/http"
+    "sort"
+    "strings"
+)
+
+// RouteConflict describes a route that more than one SQL file (or a system endpoint) maps to
+type RouteConflict struct {
+    Path    string   // Conflicting route path
+    Sources []string // "METHOD sql/path" entries that claim the route
+    Reason  string   // Why the routes cannot be registered together
+}
+
+// RouteConflictError reports every route conflict found during startup validation
+type RouteConflictError struct {
+    Conflicts []RouteConflict
+}
+
+// Error lists each conflicting route with the SQL files that map to it
+func (e *RouteConflictError) Error() string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "%d route conflict(s) detected:", len(e.Conflicts))
+    for _, c := range e.Conflicts {
+        fmt.Fprintf(&b, "\n  %s (%s)", c.Path, c.Reason)
+        for _, source := range c.Sources {
+            fmt.Fprintf(&b, "\n    - %s", source)
+        }
+    }
+    return b.String()
+}
+
+// ValidateEndpoints checks that all endpoints can be registered on one http.ServeMux
+// alongside the system endpoints enabled by cfg
+// Returns a *RouteConflictError listing every conflicting SQL file instead of letting
+// the mux panic on the first duplicate
+func ValidateEndpoints(cfg setup.Config, endpoints []Endpoint) error {
+    var conflicts []RouteConflict
+
+    // Group sources by exact route path, system routes included
+    byPath := make(map[string][]string)
+    var paths []string
+    addSource := func(path, source string) {
+        if _, seen := byPath[path]; !seen {
+            paths = append(paths, path)
+        }
+        byPath[path] = append(byPath[path], source)
+    }
+    for _, route := range EnabledSystemRoutes(cfg) {
+        addSource(route.Path, fmt.Sprintf("%s %s (system endpoint)", route.Method, route.Path))
+    }
+    for _, endpoint := range endpoints {
+        addSource(endpoint.Path, fmt.Sprintf("%s %s", endpoint.Method, endpoint.SQLPath))
+    }
+
+    var unique []string
+    for _, path := range paths {
+        if len(byPath[path]) > 1 {
+            conflicts = append(conflicts, RouteConflict{
+                Path:    path,
+                Sources: byPath[path],
+                Reason:  "duplicate route",
+            })
+            continue
+        }
+        if err := tryRegister(path); err != nil {
+            conflicts = append(conflicts, RouteConflict{
+                Path:    path,
+                Sources: byPath[path],
+                Reason:  fmt.Sprintf("invalid route: %v", err),
+            })
+            continue
+        }
+        unique = append(unique, path)
+    }
+
+    // Wildcard segments like {id} can overlap without being identical
+    for i := 0; i < len(unique); i++ {
+        for j := i + 1; j < len(unique); j++ {
+            a, b := unique[i], unique[j]
+            if !strings.Contains(a, "{") && !strings.Contains(b, "{") {
+                continue
+            }
+            if err := tryRegister(a, b); err != nil {
+                conflicts = append(conflicts, RouteConflict{
+                    Path:    a + " <-> " + b,
+                    Sources: append(append([]string{}, byPath[a]...), byPath[b]...),
+                    Reason:  "overlapping routes",
+                })
+            }
+        }
+    }
+
+    if len(conflicts) == 0 {
+        return nil
+    }
+
+    sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+    return &RouteConflictError{Conflicts: conflicts}
+}
+
+// tryRegister registers the given patterns on a scratch mux and converts a panic into an error
+func tryRegister(patterns ...string) (err error) {
+    defer func() {
+        if r := recover(); r != nil {
+            err = fmt.Errorf("%v", r)
+        }
+    }()
+
+    mux := http.NewServeMux()
+    for _, pattern := range patterns {
+        mux.HandleFunc(pattern, func(http.ResponseWriter, *http.Request) {})
+    }
+    return nil
+}
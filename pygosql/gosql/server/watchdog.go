@@ -0,0 +1,36 @@
+// watchdog.go
+package server
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "time"
+)
+
+// watchParent reports on the returned channel when the configured parent process exits
+// or stdin is closed, so a server launched by another program does not outlive it
+// The channel is nil, and never ready, when neither watch is enabled
+func (s *Server) watchParent() <-chan string {
+    if s.config.ParentPID <= 0 && !s.config.WatchStdin {
+        return nil
+    }
+    gone := make(chan string, 2)
+
+    if s.config.ParentPID > 0 {
+        go func(pid int) {
+            for processAlive(pid) {
+                time.Sleep(time.Second)
+            }
+            gone <- fmt.Sprintf("parent process %d exited", pid)
+        }(s.config.ParentPID)
+    }
+
+    if s.config.WatchStdin {
+        go func() {
+            io.Copy(io.Discard, os.Stdin)
+            gone <- "stdin closed"
+        }()
+    }
+    return gone
+}
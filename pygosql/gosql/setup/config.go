@@ -1,35 +1,137 @@
 // config.go
 package setup
 
+import (
+    "gosql/database"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
 const (
     DefaultDBPath     = "gosql_dir/app.db"
     DefaultSchemaPath = "gosql_dir/db/schema.sql"
     DefaultSQLRoot    = "gosql_dir/db"
     BaseURL           = "/api/v1"
     DefaultPort       = 2222
+    DefaultBind       = "127.0.0.1"
     ModuleName        = "gosql"
 )
 
 // Config holds all configuration settings for the GoSQL application
 type Config struct {
-    DatabasePath string // Path to the SQLite database file
-    SQLRoot      string // Root directory containing SQL files
-    SchemaPath   string // Path to schema.sql file
-    BaseURL      string // Base URL prefix for API endpoints
-    Port         int    // HTTP server port
-    EnableCORS   bool   // Whether to enable CORS headers
-    DebugMode    bool   // Whether to include debug information in responses
+    DatabasePath    string      // Path to the SQLite database file
+    SQLRoot         string      // Root directory containing SQL files
+    SchemaPath      string      // Path to schema.sql file
+    BaseURL         string      // Base URL prefix for API endpoints
+    Port            int         // HTTP server port
+    BindAddresses   []string    // Addresses to listen on with Port; "none" serves only the Unix socket
+    UnixSocket      string      // Unix socket path to also listen on, empty for none
+    SocketMode      os.FileMode // Permission bits of the Unix socket file
+    ReadyFD         int         // Descriptor the JSON ready line is written to: 1 for stdout, 0 for none
+    ParentPID       int         // Shut down when this process exits, 0 to not watch
+    WatchStdin      bool        // Shut down when stdin is closed
+    LogLevel        string      // Minimum log level: debug, info, warn or error
+    LogFormat       string      // Log output format: text or json
+    LogRedact       []string    // Parameter and column names whose values are never logged; matched as case-insensitive substrings
+    TraceExporter   string      // Where request spans go: stderr, file or otlp; empty to disable tracing
+    TraceFile       string      // JSON lines file written by the file exporter
+    TraceEndpoint   string      // OTLP/HTTP traces URL, empty for a collector on localhost:4318
+    AccessLog       string      // Access log sink: stderr, stdout or a file path; empty to disable
+    AccessLogFormat string      // Access log format: common, combined or json
+    ReadyWriteProbe bool        // Whether /readyz writes to a scratch table to prove the database accepts writes
+    ReadyDetails    bool        // Whether /readyz reports its checks to callers without credentials
+    MinFreeDiskMB   int         // Free space the database directory needs for /readyz to pass, 0 to not check
+    EnableCORS      bool        // Whether to enable CORS headers
+    CORS            CORSPolicy  // Cross-origin policy applied when EnableCORS is set
+    DebugMode       bool        // Whether to include debug information in responses
+    EnableGraphQL   bool        // Whether to serve the GraphQL endpoint at /graphql
+    GraphQLRead     string      // Scope required to query /graphql when auth is on
+    GraphQLWrite    string      // Scope additionally required for GraphQL mutations when auth is on
+    EnableAuth      bool        // Whether requests must present an API key or bearer token
+    AuthKeysFile    string      // JSON file of hashed keys, used alongside the gosql_keys table
+    AuthExempt      []string    // System endpoint paths served without credentials
+    JWTKeyFile      string      // JWKS, JWK or PEM file of keys that verify bearer JWTs
+    JWTSecret       string      // Shared HS256 secret for bearer JWTs
+    JWTIssuer       string      // Required iss claim of bearer JWTs, empty for any
+    JWTAudience     string      // Required aud claim of bearer JWTs, empty for any
+    JWTAllowNoExp   bool        // Whether bearer JWTs without an exp claim are accepted
+    TLSCertFile     string      // PEM certificate file, enables HTTPS with TLSKeyFile
+    TLSKeyFile      string      // PEM private key file for TLSCertFile
+    TLSAutoCert     bool        // Whether to serve a generated self-signed localhost certificate when no files are set
+    TLSMinVersion   string      // Minimum TLS version: 1.2 or 1.3
+    TLSClientCA     string      // PEM CA certificates that client certificates must chain to, empty to not require them
+    RateLimit       string      // Default per-client limit such as "10/s" or "600/m burst 50", empty for none
 }
 
 // DefaultConfig returns a Config struct with sensible default values
 func DefaultConfig() Config {
     return Config{
-        DatabasePath: DefaultDBPath,
-        SQLRoot:      DefaultSQLRoot,
-        SchemaPath:   DefaultSchemaPath,
-        BaseURL:      BaseURL,
-        Port:         DefaultPort,
-        EnableCORS:   true,
-        DebugMode:    true,
+        DatabasePath:    DefaultDBPath,
+        SQLRoot:         DefaultSQLRoot,
+        SchemaPath:      DefaultSchemaPath,
+        BaseURL:         BaseURL,
+        Port:            DefaultPort,
+        BindAddresses:   []string{DefaultBind},
+        SocketMode:      0600,
+        ReadyFD:         1,
+        LogLevel:        "info",
+        LogFormat:       "text",
+        LogRedact:       DefaultRedact(),
+        AccessLogFormat: "common",
+        MinFreeDiskMB:   100,
+        EnableCORS:      true,
+        CORS:            DefaultCORSPolicy(),
+        DebugMode:       true,
+        AuthExempt:      []string{"/health", "/livez", "/readyz"},
+        TLSMinVersion:   "1.2",
+    }
+}
+
+// DefaultRedact lists the parameter and column names whose values are hidden in logs
+func DefaultRedact() []string {
+    return []string{"password", "passwd", "secret", "token", "api_key", "key_hash", "email", "phone", "ssn"}
+}
+
+// Scheme returns "https" when the server is configured for TLS, "http" otherwise
+func (c Config) Scheme() string {
+    if c.TLSCertFile != "" || c.TLSAutoCert {
+        return "https"
+    }
+    return "http"
+}
+
+// MigrationsPath returns the directory holding the numbered migration files
+func (c Config) MigrationsPath() string {
+    return filepath.Join(c.SQLRoot, database.MigrationsDir)
+}
+
+// CORSPolicy configures which browser origins may call the API
+type CORSPolicy struct {
+    AllowedOrigins   []string // Origins allowed to call the API; "*" for any, or patterns such as https://*.example.com
+    AllowedMethods   []string // Methods allowed in cross-origin requests
+    AllowedHeaders   []string // Request headers allowed in cross-origin requests; "*" for any
+    ExposedHeaders   []string // Response headers scripts may read
+    AllowCredentials bool     // Whether cookies and Authorization headers may be sent
+    MaxAge           int      // Seconds a preflight response may be cached
+}
+
+// DefaultCORSPolicy allows any origin without credentials
+func DefaultCORSPolicy() CORSPolicy {
+    return CORSPolicy{
+        AllowedOrigins: []string{"*"},
+        AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+        AllowedHeaders: []string{"Content-Type", "Authorization", "X-Requested-With", "X-API-Key"},
+        MaxAge:         86400, // 24 hours
     }
-}
\ No newline at end of file
+}
+
+// AllowsAnyOrigin reports whether the policy lists the "*" origin
+func (p CORSPolicy) AllowsAnyOrigin() bool {
+    for _, origin := range p.AllowedOrigins {
+        if strings.TrimSpace(origin) == "*" {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,432 @@
+// configfile.go
+package setup
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// ConfigEntry is one key and value read from a config file
+// Nested keys are joined with dots and lists are joined with commas
+type ConfigEntry struct {
+    Key   string // Dotted key path, e.g. "tls.cert"
+    Value string // Scalar value, or comma separated list items
+    Line  int    // Line the value was set on, 0 when unknown
+}
+
+// ParseConfigFile reads a YAML, TOML or JSON config file, chosen by its extension
+// Only the subset of YAML and TOML needed for flat settings is supported: nested
+// mappings or tables, scalars, quoted strings and lists
+func ParseConfigFile(path string) ([]ConfigEntry, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read config file: %w", err)
+    }
+
+    var entries []ConfigEntry
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        entries, err = parseYAML(string(data))
+    case ".toml":
+        entries, err = parseTOML(string(data))
+    case ".json":
+        entries, err = parseJSON(data)
+    default:
+        return nil, fmt.Errorf("config file %s: unknown format, use .yaml, .toml or .json", path)
+    }
+    if err != nil {
+        return entries, fmt.Errorf("config file %s: %w", path, err)
+    }
+    return entries, nil
+}
+
+// yamlParent is a mapping key whose value is on the following, more indented lines
+type yamlParent struct {
+    indent   int      // Indentation of the key
+    key      string   // Dotted key path
+    line     int      // Line of the key
+    children bool     // Whether nested keys followed
+    items    []string // Block list items
+}
+
+// parseYAML parses block mappings, block and flow lists, and plain or quoted scalars
+func parseYAML(text string) ([]ConfigEntry, error) {
+    var entries []ConfigEntry
+    var errs []error
+    var stack []*yamlParent
+
+    // closeParent emits the list or empty value of a key that had no nested keys
+    closeParent := func(parent *yamlParent) {
+        if !parent.children {
+            entries = append(entries, ConfigEntry{Key: parent.key, Value: strings.Join(parent.items, ","), Line: parent.line})
+        }
+    }
+
+    for number, raw := range strings.Split(text, "\n") {
+        line := number + 1
+        content := strings.TrimRight(stripComment(raw), " \r")
+        trimmed := strings.TrimLeft(content, " ")
+        if trimmed == "" || trimmed == "---" {
+            continue
+        }
+        indent := len(content) - len(trimmed)
+        if strings.HasPrefix(trimmed, "\t") || strings.Contains(content[:indent], "\t") {
+            errs = append(errs, fmt.Errorf("line %d: indent with spaces, not tabs", line))
+            continue
+        }
+
+        // List items belong to the innermost key that is still open
+        if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+            if len(stack) == 0 || stack[len(stack)-1].children {
+                errs = append(errs, fmt.Errorf("line %d: list item without a key", line))
+                continue
+            }
+            value, err := parseScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+            if err != nil {
+                errs = append(errs, fmt.Errorf("line %d: %v", line, err))
+                continue
+            }
+            parent := stack[len(stack)-1]
+            parent.items = append(parent.items, value)
+            continue
+        }
+
+        key, value, ok := splitYAMLKey(trimmed)
+        if !ok {
+            errs = append(errs, fmt.Errorf("line %d: expected key: value", line))
+            continue
+        }
+        for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+            closeParent(stack[len(stack)-1])
+            stack = stack[:len(stack)-1]
+        }
+        if len(stack) > 0 {
+            parent := stack[len(stack)-1]
+            if len(parent.items) > 0 {
+                errs = append(errs, fmt.Errorf("line %d: key %q mixed with list items", line, key))
+                continue
+            }
+            parent.children = true
+            key = parent.key + "." + key
+        }
+
+        if value == "" {
+            stack = append(stack, &yamlParent{indent: indent, key: key, line: line})
+            continue
+        }
+        if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") || strings.HasPrefix(value, "{") || strings.HasPrefix(value, "&") || strings.HasPrefix(value, "*") {
+            errs = append(errs, fmt.Errorf("line %d: block scalars, flow mappings, anchors and aliases are not supported", line))
+            continue
+        }
+        parsed, err := parseValue(value)
+        if err != nil {
+            errs = append(errs, fmt.Errorf("line %d: %v", line, err))
+            continue
+        }
+        entries = append(entries, ConfigEntry{Key: key, Value: parsed, Line: line})
+    }
+    for i := len(stack) - 1; i >= 0; i-- {
+        closeParent(stack[i])
+    }
+    return entries, errors.Join(errs...)
+}
+
+// splitYAMLKey splits "key: value" and unquotes a quoted key
+func splitYAMLKey(content string) (string, string, bool) {
+    var key, rest string
+    if content[0] == '"' || content[0] == '\'' {
+        end := strings.IndexByte(content[1:], content[0])
+        if end < 0 {
+            return "", "", false
+        }
+        key, rest = content[1:end+1], content[end+2:]
+        if !strings.HasPrefix(rest, ":") {
+            return "", "", false
+        }
+        rest = rest[1:]
+    } else {
+        i := strings.Index(content, ": ")
+        if i < 0 {
+            if !strings.HasSuffix(content, ":") {
+                return "", "", false
+            }
+            i = len(content) - 1
+        }
+        key, rest = content[:i], content[i+1:]
+    }
+    key = strings.TrimSpace(key)
+    return key, strings.TrimSpace(rest), key != ""
+}
+
+// parseTOML parses tables, dotted and quoted keys, strings, numbers, booleans and
+// arrays, which may span lines
+func parseTOML(text string) ([]ConfigEntry, error) {
+    var entries []ConfigEntry
+    var errs []error
+    table := ""
+
+    lines := strings.Split(text, "\n")
+    for i := 0; i < len(lines); i++ {
+        line := i + 1
+        content := strings.TrimSpace(stripComment(lines[i]))
+        if content == "" {
+            continue
+        }
+
+        if strings.HasPrefix(content, "[[") {
+            errs = append(errs, fmt.Errorf("line %d: arrays of tables are not supported", line))
+            continue
+        }
+        if strings.HasPrefix(content, "[") {
+            if !strings.HasSuffix(content, "]") {
+                errs = append(errs, fmt.Errorf("line %d: unterminated table header", line))
+                continue
+            }
+            name, err := parseTOMLKey(content[1 : len(content)-1])
+            if err != nil {
+                errs = append(errs, fmt.Errorf("line %d: %v", line, err))
+                continue
+            }
+            table = name
+            continue
+        }
+
+        rawKey, value, ok := strings.Cut(content, "=")
+        if !ok {
+            errs = append(errs, fmt.Errorf("line %d: expected key = value", line))
+            continue
+        }
+        key, err := parseTOMLKey(rawKey)
+        if err != nil {
+            errs = append(errs, fmt.Errorf("line %d: %v", line, err))
+            continue
+        }
+        if table != "" {
+            key = table + "." + key
+        }
+
+        // Arrays continue until their brackets balance
+        value = strings.TrimSpace(value)
+        for strings.HasPrefix(value, "[") && !balanced(value) && i+1 < len(lines) {
+            i++
+            value += " " + strings.TrimSpace(stripComment(lines[i]))
+        }
+        if strings.HasPrefix(value, `"""`) || strings.HasPrefix(value, "'''") || strings.HasPrefix(value, "{") {
+            errs = append(errs, fmt.Errorf("line %d: multi-line strings and inline tables are not supported", line))
+            continue
+        }
+        parsed, err := parseValue(value)
+        if err != nil {
+            errs = append(errs, fmt.Errorf("line %d: %v", line, err))
+            continue
+        }
+        entries = append(entries, ConfigEntry{Key: key, Value: parsed, Line: line})
+    }
+    return entries, errors.Join(errs...)
+}
+
+// parseTOMLKey joins the parts of a bare, quoted or dotted key with dots
+func parseTOMLKey(raw string) (string, error) {
+    var parts []string
+    for _, part := range splitOutsideQuotes(raw, '.') {
+        part, err := parseScalar(strings.TrimSpace(part))
+        if err != nil {
+            return "", err
+        }
+        if part == "" {
+            return "", fmt.Errorf("empty key in %q", strings.TrimSpace(raw))
+        }
+        parts = append(parts, part)
+    }
+    return strings.Join(parts, "."), nil
+}
+
+// parseJSON flattens a JSON object; its line numbers are not tracked
+func parseJSON(data []byte) ([]ConfigEntry, error) {
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    decoder.UseNumber()
+    var root map[string]interface{}
+    if err := decoder.Decode(&root); err != nil {
+        return nil, err
+    }
+
+    var entries []ConfigEntry
+    var flatten func(prefix string, object map[string]interface{}) error
+    flatten = func(prefix string, object map[string]interface{}) error {
+        for _, key := range sortedKeys(object) {
+            path := key
+            if prefix != "" {
+                path = prefix + "." + key
+            }
+            if nested, ok := object[key].(map[string]interface{}); ok {
+                if err := flatten(path, nested); err != nil {
+                    return err
+                }
+                continue
+            }
+            value, err := jsonScalar(object[key])
+            if err != nil {
+                return fmt.Errorf("%s: %v", path, err)
+            }
+            entries = append(entries, ConfigEntry{Key: path, Value: value})
+        }
+        return nil
+    }
+    return entries, flatten("", root)
+}
+
+// jsonScalar formats a JSON value as a setting value, joining arrays with commas
+func jsonScalar(value interface{}) (string, error) {
+    switch v := value.(type) {
+    case nil:
+        return "", nil
+    case string:
+        return v, nil
+    case bool:
+        return strconv.FormatBool(v), nil
+    case json.Number:
+        return v.String(), nil
+    case []interface{}:
+        items := make([]string, len(v))
+        for i, item := range v {
+            s, err := jsonScalar(item)
+            if err != nil || strings.Contains(s, ",") {
+                return "", fmt.Errorf("arrays may only hold scalars without commas")
+            }
+            items[i] = s
+        }
+        return strings.Join(items, ","), nil
+    }
+    return "", fmt.Errorf("unsupported value %v", value)
+}
+
+// parseValue parses a scalar or a flow list such as [a, "b"], joining list items with commas
+func parseValue(value string) (string, error) {
+    if !strings.HasPrefix(value, "[") {
+        return parseScalar(value)
+    }
+    if !strings.HasSuffix(value, "]") {
+        return "", fmt.Errorf("unterminated list %s", value)
+    }
+    var items []string
+    for _, item := range splitOutsideQuotes(value[1:len(value)-1], ',') {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        parsed, err := parseScalar(item)
+        if err != nil {
+            return "", err
+        }
+        items = append(items, parsed)
+    }
+    return strings.Join(items, ","), nil
+}
+
+// parseScalar unquotes a double or single quoted string; other values are kept as
+// written, with null and ~ meaning empty
+func parseScalar(value string) (string, error) {
+    switch {
+    case strings.HasPrefix(value, `"`):
+        unquoted, err := strconv.Unquote(value)
+        if err != nil {
+            return "", fmt.Errorf("invalid quoted string %s", value)
+        }
+        return unquoted, nil
+    case strings.HasPrefix(value, "'"):
+        if len(value) < 2 || !strings.HasSuffix(value, "'") {
+            return "", fmt.Errorf("invalid quoted string %s", value)
+        }
+        return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+    case value == "null" || value == "~":
+        return "", nil
+    }
+    return value, nil
+}
+
+// stripComment removes a # comment that is outside quotes and starts the line or
+// follows whitespace
+func stripComment(line string) string {
+    var quote byte
+    for i := 0; i < len(line); i++ {
+        c := line[i]
+        switch {
+        case quote != 0:
+            if c == '\\' && quote == '"' {
+                i++
+            } else if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'':
+            quote = c
+        case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+            return line[:i]
+        }
+    }
+    return line
+}
+
+// splitOutsideQuotes splits s at sep characters that are not inside quotes
+func splitOutsideQuotes(s string, sep byte) []string {
+    var parts []string
+    var quote byte
+    start := 0
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        switch {
+        case quote != 0:
+            if c == '\\' && quote == '"' {
+                i++
+            } else if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'':
+            quote = c
+        case c == sep:
+            parts = append(parts, s[start:i])
+            start = i + 1
+        }
+    }
+    return append(parts, s[start:])
+}
+
+// balanced reports whether the brackets outside quotes in s are closed
+func balanced(s string) bool {
+    depth := 0
+    var quote byte
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        switch {
+        case quote != 0:
+            if c == '\\' && quote == '"' {
+                i++
+            } else if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'':
+            quote = c
+        case c == '[':
+            depth++
+        case c == ']':
+            depth--
+        }
+    }
+    return depth <= 0
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
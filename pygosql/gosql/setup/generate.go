@@ -0,0 +1,255 @@
+// generate.go
+package setup
+
+import (
+    "errors"
+    "fmt"
+    "gosql/database"
+    "path/filepath"
+    "regexp"
+    "strings"
+)
+
+// EndpointMethods are the HTTP methods an endpoint SQL file can be generated for
+var EndpointMethods = []string{"GET", "POST", "PUT", "DELETE"}
+
+// TableEndpoints are the endpoint names and methods generated for a new table
+var TableEndpoints = []struct{ Method, Name string }{
+    {"GET", "select"},
+    {"POST", "insert"},
+    {"PUT", "update"},
+    {"DELETE", "delete"},
+}
+
+// ErrNothingToUpdate is returned for a PUT endpoint on a table with only key columns
+var ErrNothingToUpdate = errors.New("no columns to update")
+
+var endpointName = regexp.MustCompile(`^\w+(/\w+)*$`)
+
+// EndpointFile returns the path of a table endpoint's SQL file:
+// <Root>/Tables/<table>/<METHOD>/<name>.sql, where name may contain / for nested routes
+func (d *Dir) EndpointFile(table, method, name string) string {
+    return filepath.Join(d.Tables, table, method, filepath.FromSlash(name)+".sql")
+}
+
+// EndpointSpec describes an endpoint SQL file to generate for a table
+type EndpointSpec struct {
+    Table       string            // Table the endpoint reads or writes
+    Method      string            // HTTP method: GET, POST, PUT or DELETE
+    Name        string            // File name without .sql; / separates nested route segments
+    Columns     []database.Column // Columns of the table in the live schema
+    By          []string          // Key columns; empty to pick them as GenerateEndpointSQL describes
+    Description string            // @description text; empty for a generated one
+}
+
+// GenerateEndpointSQL writes an endpoint SQL file for a table from its columns, with a
+// metadata header declaring every bound parameter
+// Rows are selected, updated or deleted by the key columns: spec.By when given, else the
+// columns named in a by_<column>[_and_<column>] endpoint name, else the primary key
+// (no filter for GET)
+func GenerateEndpointSQL(spec EndpointSpec) (string, error) {
+    table, name, columns := spec.Table, spec.Name, spec.Columns
+    method := strings.ToUpper(spec.Method)
+    if !isEndpointMethod(method) {
+        return "", fmt.Errorf("method %q: use GET, POST, PUT or DELETE", spec.Method)
+    }
+    if !endpointName.MatchString(name) {
+        return "", fmt.Errorf("endpoint name %q: use letters, digits and underscores, with / for nested routes", name)
+    }
+    if len(columns) == 0 {
+        return "", fmt.Errorf("table %s has no columns", table)
+    }
+
+    byName := make(map[string]database.Column, len(columns))
+    for _, column := range columns {
+        byName[strings.ToLower(column.Name)] = column
+    }
+
+    // Key columns
+    var keys []database.Column
+    for _, name := range spec.By {
+        column, ok := byName[strings.ToLower(name)]
+        if !ok {
+            return "", fmt.Errorf("table %s has no column %q", table, name)
+        }
+        keys = append(keys, column)
+    }
+    if len(keys) == 0 {
+        keys = columnsFromName(filepath.Base(name), byName)
+    }
+    if len(keys) == 0 && method != "GET" {
+        for _, column := range columns {
+            if column.PrimaryKey {
+                keys = append(keys, column)
+            }
+        }
+        if len(keys) == 0 {
+            keys = []database.Column{{Name: "rowid", Type: "INTEGER", NotNull: true}}
+        }
+    }
+    isKey := make(map[string]bool, len(keys))
+    for _, key := range keys {
+        isKey[key.Name] = true
+    }
+
+    // Columns written by POST and PUT; an INTEGER PRIMARY KEY is assigned by SQLite and
+    // PUT leaves the keys and primary key alone
+    var writable []database.Column
+    for _, column := range columns {
+        if method == "PUT" && (isKey[column.Name] || column.PrimaryKey) {
+            continue
+        }
+        if method == "POST" && column.PrimaryKey && strings.EqualFold(column.Type, "INTEGER") {
+            continue
+        }
+        writable = append(writable, column)
+    }
+
+    var b strings.Builder
+    var params []database.Column
+    where := func() {
+        if len(keys) == 0 {
+            return
+        }
+        conditions := make([]string, len(keys))
+        for i, key := range keys {
+            conditions[i] = fmt.Sprintf("%s = :%s", QuoteName(key.Name), key.Name)
+        }
+        fmt.Fprintf(&b, "\nWHERE %s", strings.Join(conditions, "\n  AND "))
+        params = append(params, keys...)
+    }
+
+    description := spec.Description
+    if description == "" {
+        description = describeEndpoint(table, method, keys)
+    }
+    switch method {
+    case "GET":
+        names := make([]string, len(columns))
+        for i, column := range columns {
+            names[i] = QuoteName(column.Name)
+        }
+        fmt.Fprintf(&b, "SELECT %s\nFROM %s", strings.Join(names, ", "), QuoteName(table))
+        where()
+        for _, column := range columns {
+            if column.PrimaryKey {
+                fmt.Fprintf(&b, "\nORDER BY %s", QuoteName(column.Name))
+                break
+            }
+        }
+    case "POST":
+        if len(writable) == 0 {
+            fmt.Fprintf(&b, "INSERT INTO %s DEFAULT VALUES", QuoteName(table))
+            break
+        }
+        names := make([]string, len(writable))
+        values := make([]string, len(writable))
+        for i, column := range writable {
+            names[i] = QuoteName(column.Name)
+            values[i] = ":" + column.Name
+        }
+        fmt.Fprintf(&b, "INSERT INTO %s (%s)\nVALUES (%s)", QuoteName(table), strings.Join(names, ", "), strings.Join(values, ", "))
+        params = append(params, writable...)
+    case "PUT":
+        if len(writable) == 0 {
+            return "", fmt.Errorf("table %s: %w besides %s", table, ErrNothingToUpdate, joinNames(keys))
+        }
+        params = append(params, writable...)
+        assignments := make([]string, len(writable))
+        for i, column := range writable {
+            assignments[i] = fmt.Sprintf("%s = :%s", QuoteName(column.Name), column.Name)
+        }
+        fmt.Fprintf(&b, "UPDATE %s\nSET %s", QuoteName(table), strings.Join(assignments, ",\n    "))
+        where()
+    case "DELETE":
+        fmt.Fprintf(&b, "DELETE FROM %s", QuoteName(table))
+        where()
+    }
+    b.WriteString(";\n")
+
+    for _, column := range params {
+        if !plainName.MatchString(column.Name) {
+            return "", fmt.Errorf("column %q cannot be bound as a named parameter; write this endpoint by hand", column.Name)
+        }
+    }
+
+    var header strings.Builder
+    fmt.Fprintf(&header, "-- @description %s\n", description)
+    for _, column := range params {
+        decl := column.Name
+        if jsonType := database.JSONType(column.Type); jsonType != "" {
+            decl += " " + jsonType
+        }
+        // Every named parameter must be bound, so each one is required; nullable
+        // columns accept null
+        decl += " required"
+        if !column.NotNull && !column.PrimaryKey {
+            decl += " nullable"
+        }
+        fmt.Fprintf(&header, "-- @param %s\n", decl)
+    }
+    return header.String() + b.String(), nil
+}
+
+// columnsFromName returns the columns named by a by_<column>[_and_<column>] endpoint
+// name, or nil when the name does not follow that form or names unknown columns
+func columnsFromName(name string, byName map[string]database.Column) []database.Column {
+    rest, ok := strings.CutPrefix(strings.ToLower(name), "by_")
+    if !ok {
+        return nil
+    }
+    var columns []database.Column
+    for _, part := range strings.Split(rest, "_and_") {
+        column, ok := byName[part]
+        if !ok {
+            return nil
+        }
+        columns = append(columns, column)
+    }
+    return columns
+}
+
+// describeEndpoint writes the default @description of a generated endpoint
+func describeEndpoint(table, method string, keys []database.Column) string {
+    verb := map[string]string{"GET": "Fetch", "POST": "Insert", "PUT": "Update", "DELETE": "Delete"}[method]
+    switch {
+    case method == "POST":
+        return fmt.Sprintf("%s a row into %s", verb, table)
+    case len(keys) == 0:
+        return fmt.Sprintf("%s all %s", verb, table)
+    default:
+        return fmt.Sprintf("%s %s by %s", verb, table, joinNames(keys))
+    }
+}
+
+// joinNames lists column names as "a", "a and b" or "a, b and c"
+func joinNames(columns []database.Column) string {
+    names := make([]string, len(columns))
+    for i, column := range columns {
+        names[i] = column.Name
+    }
+    if len(names) <= 1 {
+        return strings.Join(names, "")
+    }
+    return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// isEndpointMethod reports whether method is one of EndpointMethods
+func isEndpointMethod(method string) bool {
+    for _, known := range EndpointMethods {
+        if method == known {
+            return true
+        }
+    }
+    return false
+}
+
+var plainName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QuoteName quotes a table or column name unless it is a plain identifier
+func QuoteName(name string) string {
+    if plainName.MatchString(name) {
+        return name
+    }
+    return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
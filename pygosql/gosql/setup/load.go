@@ -0,0 +1,202 @@
+// load.go
+package setup
+
+import (
+    "errors"
+    "fmt"
+    "gosql/certs"
+    "gosql/ratelimit"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// ConfigFiles are the config file names looked for in the working directory, in order
+var ConfigFiles = []string{"gosql.yaml", "gosql.yml", "gosql.toml", "gosql.json"}
+
+// ConfigEnv names the environment variable holding the config file path
+const ConfigEnv = EnvPrefix + "CONFIG"
+
+// LoadOptions are the inputs merged into a Config by Load
+type LoadOptions struct {
+    File  string            // Config file path; empty to use GOSQL_CONFIG or look for ConfigFiles
+    Env   []string          // Environment as KEY=value pairs, usually os.Environ()
+    Flags map[string]string // Flags given on the command line, by setting name
+}
+
+// Sources records where each setting's effective value came from, by setting name:
+// "default", "file <path>:<line>", "env GOSQL_<NAME>", "flag -<name>" or "derived"
+type Sources map[string]string
+
+// Load builds the configuration from, in increasing precedence, the defaults, the
+// config file, GOSQL_* environment variables and command line flags, then validates it
+// Every problem found is reported in the returned error, not just the first
+func Load(opts LoadOptions) (Config, Sources, error) {
+    cfg := DefaultConfig()
+    sources := make(Sources, len(Settings))
+    for _, setting := range Settings {
+        sources[setting.Name] = "default"
+    }
+    var errs []error
+
+    set := func(name, value, source string) {
+        setting, ok := LookupSetting(name)
+        if !ok {
+            errs = append(errs, fmt.Errorf("%s: unknown setting %q", source, NormalizeKey(name)))
+            return
+        }
+        if err := setting.Set(&cfg, value); err != nil {
+            errs = append(errs, fmt.Errorf("%s: %s %q: %v", source, setting.Name, value, err))
+            return
+        }
+        sources[setting.Name] = source
+    }
+
+    env := make(map[string]string)
+    for _, pair := range opts.Env {
+        if key, value, ok := strings.Cut(pair, "="); ok && strings.HasPrefix(key, EnvPrefix) {
+            env[key] = value
+        }
+    }
+
+    // Config file
+    path := opts.File
+    if path == "" {
+        path = env[ConfigEnv]
+    }
+    if path == "" {
+        path = findConfigFile()
+    }
+    if path != "" {
+        entries, err := ParseConfigFile(path)
+        if err != nil {
+            errs = append(errs, err)
+        }
+        for _, entry := range entries {
+            source := "file " + path
+            if entry.Line > 0 {
+                source = fmt.Sprintf("file %s:%d", path, entry.Line)
+            }
+            set(entry.Key, entry.Value, source)
+        }
+    }
+
+    // Environment variables, in a stable order so errors are reproducible
+    for _, key := range sortedKeys(env) {
+        if key == ConfigEnv {
+            continue
+        }
+        set(strings.TrimPrefix(key, EnvPrefix), env[key], "env "+key)
+    }
+
+    // Flags
+    for _, name := range sortedKeys(opts.Flags) {
+        set(name, opts.Flags[name], "flag -"+name)
+    }
+
+    // The schema lives in the SQL root unless it was set explicitly
+    if sources["schema"] == "default" {
+        cfg.SchemaPath = filepath.Join(cfg.SQLRoot, "schema.sql")
+        sources["schema"] = "derived"
+    }
+
+    // Verifying JWTs only makes sense when requests are authenticated
+    if cfg.JWTKeyFile != "" || cfg.JWTSecret != "" {
+        if sources["auth"] == "default" {
+            cfg.EnableAuth = true
+            sources["auth"] = "derived"
+        } else if !cfg.EnableAuth {
+            errs = append(errs, fmt.Errorf("jwt-keys and jwt-secret need auth, which %s turns off", sources["auth"]))
+        }
+    }
+
+    if err := cfg.Validate(); err != nil {
+        errs = append(errs, err)
+    }
+    return cfg, sources, errors.Join(errs...)
+}
+
+// findConfigFile returns the first of ConfigFiles present in the working directory
+func findConfigFile() string {
+    for _, name := range ConfigFiles {
+        if _, err := os.Stat(name); err == nil {
+            return name
+        }
+    }
+    return ""
+}
+
+// Validate checks the configuration and reports every invalid value
+func (c Config) Validate() error {
+    var errs []error
+    check := func(ok bool, format string, args ...interface{}) {
+        if !ok {
+            errs = append(errs, fmt.Errorf(format, args...))
+        }
+    }
+    oneOf := func(value string, allowed ...string) bool {
+        for _, a := range allowed {
+            if value == a {
+                return true
+            }
+        }
+        return false
+    }
+
+    check(c.Port >= 0 && c.Port <= 65535, "port %d: must be 0-65535, 0 picks a free port", c.Port)
+    check(len(c.BindAddresses) > 0 || c.UnixSocket != "", "bind: no addresses to listen on")
+    check(c.ReadyFD >= 0, "ready-fd %d: must not be negative", c.ReadyFD)
+    check(c.ParentPID >= 0, "parent-pid %d: must not be negative", c.ParentPID)
+    check(c.DatabasePath != "", "db: cannot be empty")
+    check(c.SQLRoot != "", "sql: cannot be empty")
+    check(c.BaseURL == "" || strings.HasPrefix(c.BaseURL, "/"), "base %q: must start with /", c.BaseURL)
+    check(c.CORS.MaxAge >= 0, "cors-max-age %d: must not be negative", c.CORS.MaxAge)
+    // Credentials for any origin would let every site call the API as the user
+    check(!c.EnableCORS || !c.CORS.AllowCredentials || !c.CORS.AllowsAnyOrigin(),
+        "cors-credentials cannot be combined with the * origin: list trusted origins with cors-origins")
+    check(c.TLSCertFile == "" || c.TLSKeyFile != "", "tls-key: required with tls-cert")
+    check(c.TLSKeyFile == "" || c.TLSCertFile != "", "tls-cert: required with tls-key")
+    if _, err := certs.ParseVersion(c.TLSMinVersion); err != nil {
+        errs = append(errs, fmt.Errorf("tls-min-version: %v", err))
+    }
+    if _, err := ratelimit.ParseLimit(c.RateLimit); err != nil {
+        errs = append(errs, fmt.Errorf("rate-limit: %v", err))
+    }
+    check(oneOf(c.LogLevel, "debug", "info", "warn", "error"), "log-level %q: use debug, info, warn or error", c.LogLevel)
+    check(oneOf(c.LogFormat, "text", "json"), "log-format %q: use text or json", c.LogFormat)
+    check(oneOf(c.TraceExporter, "", "none", "stderr", "file", "otlp"), "trace %q: use stderr, file or otlp", c.TraceExporter)
+    check(c.TraceExporter != "file" || c.TraceFile != "", "trace-file: required with trace file")
+    check(oneOf(c.AccessLogFormat, "common", "combined", "json"), "access-log-format %q: use common, combined or json", c.AccessLogFormat)
+    check(c.MinFreeDiskMB >= 0, "min-free-disk-mb %d: must not be negative", c.MinFreeDiskMB)
+    // GraphQL bypasses the @scope of the SQL files, so it gets scopes of its own
+    check(!c.EnableGraphQL || !c.EnableAuth || c.GraphQLRead != "" && c.GraphQLWrite != "",
+        "graphql with auth: set graphql-read-scope and graphql-write-scope, otherwise any key can read and change every table")
+    // Row-level security lives in the SQL files' :auth_ filters, which GraphQL never runs
+    check(!c.EnableGraphQL || c.JWTKeyFile == "" && c.JWTSecret == "",
+        "graphql cannot be combined with jwt-keys or jwt-secret: GraphQL ignores the :auth_ row filters of the SQL files")
+    return errors.Join(errs...)
+}
+
+// PrintConfig writes every setting's effective value and its source, hiding secrets
+func PrintConfig(w io.Writer, cfg Config, sources Sources) {
+    width := 0
+    for _, setting := range Settings {
+        width = max(width, len(setting.Name))
+    }
+    for _, setting := range Settings {
+        value := setting.Get(&cfg)
+        if setting.Secret && value != "" {
+            value = "<set>"
+        }
+        fmt.Fprintf(w, "%-*s = %-24s # %s\n", width, setting.Name, quoteValue(value), sources[setting.Name])
+    }
+}
+
+// quoteValue quotes values that would otherwise be ambiguous when printed
+func quoteValue(value string) string {
+    if value == "" || strings.ContainsAny(value, " #\"") {
+        return fmt.Sprintf("%q", value)
+    }
+    return value
+}
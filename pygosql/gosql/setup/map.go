@@ -176,11 +176,13 @@ func (d *Dir) DiscoverTables() ([]string, error) {
 
     for _, match := range matches {
         if len(match) > 1 {
-            tableName := strings.ToLower(match[1])
+            // Keep the name as declared; SQLite compares table names case-insensitively
+            tableName := match[1]
+            key := strings.ToLower(tableName)
             // Skip sqlite internal tables
-            if !strings.HasPrefix(tableName, "sqlite_") && !seenTables[tableName] {
+            if !strings.HasPrefix(key, "sqlite_") && !seenTables[key] {
                 tables = append(tables, tableName)
-                seenTables[tableName] = true
+                seenTables[key] = true
             }
         }
     }
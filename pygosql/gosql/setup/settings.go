@@ -0,0 +1,196 @@
+// settings.go
+package setup
+
+import (
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// Setting is one configuration value that can come from the config file, a GOSQL_*
+// environment variable or a command line flag
+type Setting struct {
+    Name   string                             // Flag name and config file key; GOSQL_<NAME> in the environment
+    Usage  string                             // One-line description for help output
+    Bool   bool                               // Whether the flag may be given without a value
+    Secret bool                               // Whether the value is hidden when printing the configuration
+    Get    func(c *Config) string             // Formats the current value
+    Set    func(c *Config, value string) error // Parses and stores a value
+}
+
+// EnvName returns the environment variable that sets the setting
+func (s Setting) EnvName() string {
+    return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.Name, "-", "_"))
+}
+
+// EnvPrefix starts the name of every configuration environment variable
+const EnvPrefix = "GOSQL_"
+
+// Settings lists every configurable value in the order configuration is printed
+var Settings = []Setting{
+    intSetting("port", "HTTP server port; 0 picks a free port", func(c *Config) *int { return &c.Port }),
+    listSetting("bind", "Comma separated addresses to listen on; none for only the Unix socket", func(c *Config) *[]string { return &c.BindAddresses }),
+    stringSetting("socket", "Unix socket path to also listen on", func(c *Config) *string { return &c.UnixSocket }),
+    {
+        Name:  "socket-mode",
+        Usage: "Octal permission bits of the Unix socket",
+        Get:   func(c *Config) string { return fmt.Sprintf("%04o", c.SocketMode) },
+        Set: func(c *Config, value string) error {
+            mode, err := strconv.ParseUint(value, 8, 32)
+            if err != nil || mode > 0777 {
+                return fmt.Errorf("must be octal permission bits such as 0660")
+            }
+            c.SocketMode = os.FileMode(mode)
+            return nil
+        },
+    },
+    intSetting("ready-fd", "Descriptor to write the JSON ready line to; 1 for stdout, 0 for none", func(c *Config) *int { return &c.ReadyFD }),
+    intSetting("parent-pid", "Shut down when this process exits", func(c *Config) *int { return &c.ParentPID }),
+    boolSetting("watch-stdin", "Shut down when stdin is closed", func(c *Config) *bool { return &c.WatchStdin }),
+    stringSetting("db", "Database file path", func(c *Config) *string { return &c.DatabasePath }),
+    stringSetting("sql", "SQL files root directory", func(c *Config) *string { return &c.SQLRoot }),
+    stringSetting("schema", "Schema file applied on startup (default: <sql>/schema.sql)", func(c *Config) *string { return &c.SchemaPath }),
+    stringSetting("base", "API base URL", func(c *Config) *string { return &c.BaseURL }),
+    boolSetting("debug", "Enable debug mode", func(c *Config) *bool { return &c.DebugMode }),
+    boolSetting("cors", "Enable CORS", func(c *Config) *bool { return &c.EnableCORS }),
+    listSetting("cors-origins", "Comma separated allowed origins; * or patterns like https://*.example.com", func(c *Config) *[]string { return &c.CORS.AllowedOrigins }),
+    listSetting("cors-methods", "Comma separated methods allowed cross-origin", func(c *Config) *[]string { return &c.CORS.AllowedMethods }),
+    listSetting("cors-headers", "Comma separated request headers allowed cross-origin", func(c *Config) *[]string { return &c.CORS.AllowedHeaders }),
+    listSetting("cors-expose", "Comma separated response headers exposed to scripts", func(c *Config) *[]string { return &c.CORS.ExposedHeaders }),
+    boolSetting("cors-credentials", "Allow credentialed cross-origin requests", func(c *Config) *bool { return &c.CORS.AllowCredentials }),
+    intSetting("cors-max-age", "Seconds browsers may cache preflight responses", func(c *Config) *int { return &c.CORS.MaxAge }),
+    boolSetting("graphql", "Serve a GraphQL endpoint at /graphql", func(c *Config) *bool { return &c.EnableGraphQL }),
+    stringSetting("graphql-read-scope", "Scope a key needs to query /graphql when auth is on", func(c *Config) *string { return &c.GraphQLRead }),
+    stringSetting("graphql-write-scope", "Scope a key also needs for GraphQL mutations when auth is on", func(c *Config) *string { return &c.GraphQLWrite }),
+    boolSetting("auth", "Require an API key or bearer token", func(c *Config) *bool { return &c.EnableAuth }),
+    stringSetting("auth-keys", "JSON file of hashed API keys", func(c *Config) *string { return &c.AuthKeysFile }),
+    listSetting("auth-exempt", "Comma separated system endpoints served without credentials", func(c *Config) *[]string { return &c.AuthExempt }),
+    stringSetting("jwt-keys", "JWKS, JWK or PEM file of keys that verify bearer JWTs", func(c *Config) *string { return &c.JWTKeyFile }),
+    secretSetting("jwt-secret", "HS256 secret for bearer JWTs (prefer GOSQL_JWT_SECRET)", func(c *Config) *string { return &c.JWTSecret }),
+    stringSetting("jwt-issuer", "Required iss claim of bearer JWTs", func(c *Config) *string { return &c.JWTIssuer }),
+    stringSetting("jwt-audience", "Required aud claim of bearer JWTs", func(c *Config) *string { return &c.JWTAudience }),
+    boolSetting("jwt-allow-no-exp", "Accept bearer JWTs without an exp claim", func(c *Config) *bool { return &c.JWTAllowNoExp }),
+    stringSetting("tls-cert", "PEM certificate file; serves HTTPS with tls-key", func(c *Config) *string { return &c.TLSCertFile }),
+    stringSetting("tls-key", "PEM private key file for tls-cert", func(c *Config) *string { return &c.TLSKeyFile }),
+    boolSetting("tls-auto", "Serve HTTPS with a generated self-signed localhost certificate", func(c *Config) *bool { return &c.TLSAutoCert }),
+    stringSetting("tls-min-version", "Minimum TLS version: 1.2 or 1.3", func(c *Config) *string { return &c.TLSMinVersion }),
+    stringSetting("tls-client-ca", "PEM CA file; require client certificates signed by it", func(c *Config) *string { return &c.TLSClientCA }),
+    stringSetting("rate-limit", "Default per-client limit, e.g. 10/s or 600/m burst 50", func(c *Config) *string { return &c.RateLimit }),
+    stringSetting("log-level", "Minimum log level: debug, info, warn or error", func(c *Config) *string { return &c.LogLevel }),
+    stringSetting("log-format", "Log output format: text or json", func(c *Config) *string { return &c.LogFormat }),
+    listSetting("log-redact", "Comma separated parameter and column names whose values are never logged", func(c *Config) *[]string { return &c.LogRedact }),
+    stringSetting("trace", "Export request spans to stderr, file or otlp", func(c *Config) *string { return &c.TraceExporter }),
+    stringSetting("trace-file", "JSON lines file for trace file", func(c *Config) *string { return &c.TraceFile }),
+    stringSetting("trace-endpoint", "OTLP/HTTP traces URL for trace otlp (default: http://localhost:4318/v1/traces)", func(c *Config) *string { return &c.TraceEndpoint }),
+    stringSetting("access-log", "Write an access log to stderr, stdout or this file", func(c *Config) *string { return &c.AccessLog }),
+    stringSetting("access-log-format", "Access log format: common, combined or json", func(c *Config) *string { return &c.AccessLogFormat }),
+    boolSetting("ready-write-probe", "Make /readyz write to a scratch table to prove the database accepts writes", func(c *Config) *bool { return &c.ReadyWriteProbe }),
+    boolSetting("ready-details", "Report the /readyz checks to callers without credentials", func(c *Config) *bool { return &c.ReadyDetails }),
+    intSetting("min-free-disk-mb", "Free megabytes the database directory needs for /readyz to pass, 0 to not check", func(c *Config) *int { return &c.MinFreeDiskMB }),
+}
+
+// LookupSetting finds a setting by name, accepting config file spellings such as
+// tls_cert or tls.cert
+func LookupSetting(name string) (Setting, bool) {
+    name = NormalizeKey(name)
+    for _, setting := range Settings {
+        if setting.Name == name {
+            return setting, true
+        }
+    }
+    return Setting{}, false
+}
+
+// NormalizeKey lowercases a key and joins its words with dashes, so the config file
+// keys tls_cert, tls.cert and nested tls: {cert: ...} all name the tls-cert setting
+func NormalizeKey(key string) string {
+    key = strings.ToLower(strings.TrimSpace(key))
+    return strings.NewReplacer("_", "-", ".", "-").Replace(key)
+}
+
+func stringSetting(name, usage string, field func(*Config) *string) Setting {
+    return Setting{
+        Name:  name,
+        Usage: usage,
+        Get:   func(c *Config) string { return *field(c) },
+        Set: func(c *Config, value string) error {
+            *field(c) = value
+            return nil
+        },
+    }
+}
+
+func secretSetting(name, usage string, field func(*Config) *string) Setting {
+    setting := stringSetting(name, usage, field)
+    setting.Secret = true
+    return setting
+}
+
+func intSetting(name, usage string, field func(*Config) *int) Setting {
+    return Setting{
+        Name:  name,
+        Usage: usage,
+        Get:   func(c *Config) string { return strconv.Itoa(*field(c)) },
+        Set: func(c *Config, value string) error {
+            n, err := strconv.Atoi(value)
+            if err != nil {
+                return fmt.Errorf("must be an integer")
+            }
+            *field(c) = n
+            return nil
+        },
+    }
+}
+
+func boolSetting(name, usage string, field func(*Config) *bool) Setting {
+    return Setting{
+        Name:  name,
+        Usage: usage,
+        Bool:  true,
+        Get:   func(c *Config) string { return strconv.FormatBool(*field(c)) },
+        Set: func(c *Config, value string) error {
+            b, err := ParseBool(value)
+            if err != nil {
+                return err
+            }
+            *field(c) = b
+            return nil
+        },
+    }
+}
+
+func listSetting(name, usage string, field func(*Config) *[]string) Setting {
+    return Setting{
+        Name:  name,
+        Usage: usage,
+        Get:   func(c *Config) string { return strings.Join(*field(c), ",") },
+        Set: func(c *Config, value string) error {
+            *field(c) = SplitList(value)
+            return nil
+        },
+    }
+}
+
+// ParseBool accepts the spellings of true and false used in flags, config files and
+// environment variables: true/false, yes/no, on/off and 1/0
+func ParseBool(value string) (bool, error) {
+    switch strings.ToLower(strings.TrimSpace(value)) {
+    case "true", "yes", "on", "1":
+        return true, nil
+    case "false", "no", "off", "0":
+        return false, nil
+    }
+    return false, fmt.Errorf("must be true or false")
+}
+
+// SplitList splits a comma separated value, dropping empty entries
+func SplitList(value string) []string {
+    var items []string
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
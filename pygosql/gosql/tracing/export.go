@@ -0,0 +1,203 @@
+// export.go
+package tracing
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// Exporter sends finished spans to a backend
+type Exporter interface {
+    Export(service string, spans []*Span) error // Called from a single goroutine with a batch of spans
+    Close() error                               // Releases the exporter's resources
+}
+
+// spanRecord is the JSON form of a span written by WriterExporter
+type spanRecord struct {
+    Service    string                 `json:"service"`
+    TraceID    string                 `json:"trace_id"`
+    SpanID     string                 `json:"span_id"`
+    ParentID   string                 `json:"parent_id,omitempty"`
+    Name       string                 `json:"name"`
+    Kind       string                 `json:"kind"`
+    Start      time.Time              `json:"start"`
+    DurationMS float64                `json:"duration_ms"`
+    Attributes map[string]interface{} `json:"attributes,omitempty"`
+    Error      string                 `json:"error,omitempty"`
+}
+
+// WriterExporter writes each span as a JSON line, for local inspection
+type WriterExporter struct {
+    w      io.Writer // Destination of span lines
+    closer io.Closer // Closed by Close, nil for stdout
+    mu     sync.Mutex
+}
+
+// NewWriterExporter writes spans to w without closing it
+func NewWriterExporter(w io.Writer) *WriterExporter {
+    return &WriterExporter{w: w}
+}
+
+// NewFileExporter appends spans to the file at path
+func NewFileExporter(path string) (*WriterExporter, error) {
+    file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open trace file: %w", err)
+    }
+    return &WriterExporter{w: file, closer: file}, nil
+}
+
+// Export writes the spans as JSON lines
+func (e *WriterExporter) Export(service string, spans []*Span) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    encoder := json.NewEncoder(e.w)
+    for _, span := range spans {
+        span.mu.Lock()
+        record := spanRecord{
+            Service:    service,
+            TraceID:    span.Context.TraceID.String(),
+            SpanID:     span.Context.SpanID.String(),
+            Name:       span.Name,
+            Kind:       kindName(span.Kind),
+            Start:      span.Start,
+            DurationMS: float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+            Attributes: span.Attributes,
+            Error:      span.Error,
+        }
+        if span.Parent.IsValid() {
+            record.ParentID = span.Parent.String()
+        }
+        err := encoder.Encode(record)
+        span.mu.Unlock()
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Close closes the file, if the exporter opened one
+func (e *WriterExporter) Close() error {
+    if e.closer != nil {
+        return e.closer.Close()
+    }
+    return nil
+}
+
+func kindName(kind int) string {
+    if kind == KindServer {
+        return "server"
+    }
+    return "internal"
+}
+
+// DefaultOTLPEndpoint is the traces URL of a collector running on this machine
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// OTLPExporter posts spans to an OpenTelemetry collector using OTLP/HTTP with JSON encoding
+type OTLPExporter struct {
+    Endpoint string       // Traces URL, such as DefaultOTLPEndpoint
+    Client   *http.Client // HTTP client used for exports
+}
+
+// NewOTLPExporter exports to endpoint, or DefaultOTLPEndpoint when it is empty
+func NewOTLPExporter(endpoint string) *OTLPExporter {
+    if endpoint == "" {
+        endpoint = DefaultOTLPEndpoint
+    }
+    return &OTLPExporter{Endpoint: endpoint, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Export posts one ExportTraceServiceRequest for the batch
+func (e *OTLPExporter) Export(service string, spans []*Span) error {
+    otlpSpans := make([]map[string]interface{}, 0, len(spans))
+    for _, span := range spans {
+        span.mu.Lock()
+        record := map[string]interface{}{
+            "traceId":           span.Context.TraceID.String(),
+            "spanId":            span.Context.SpanID.String(),
+            "name":              span.Name,
+            "kind":              span.Kind,
+            "startTimeUnixNano": strconv.FormatInt(span.Start.UnixNano(), 10),
+            "endTimeUnixNano":   strconv.FormatInt(span.End.UnixNano(), 10),
+            "attributes":        otlpAttributes(span.Attributes),
+            "status":            map[string]interface{}{"code": 1},
+        }
+        if span.Parent.IsValid() {
+            record["parentSpanId"] = span.Parent.String()
+        }
+        if span.Error != "" {
+            record["status"] = map[string]interface{}{"code": 2, "message": span.Error}
+        }
+        span.mu.Unlock()
+        otlpSpans = append(otlpSpans, record)
+    }
+
+    body, err := json.Marshal(map[string]interface{}{
+        "resourceSpans": []interface{}{map[string]interface{}{
+            "resource": map[string]interface{}{
+                "attributes": otlpAttributes(map[string]interface{}{"service.name": service}),
+            },
+            "scopeSpans": []interface{}{map[string]interface{}{
+                "scope": map[string]interface{}{"name": "gosql"},
+                "spans": otlpSpans,
+            }},
+        }},
+    })
+    if err != nil {
+        return err
+    }
+
+    resp, err := e.Client.Post(e.Endpoint, "application/json", bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("failed to export spans: %w", err)
+    }
+    defer resp.Body.Close()
+    io.Copy(io.Discard, resp.Body)
+    if resp.StatusCode/100 != 2 {
+        return fmt.Errorf("failed to export spans: collector returned %s", resp.Status)
+    }
+    return nil
+}
+
+// Close does nothing; pending batches are flushed by Tracer.Shutdown
+func (e *OTLPExporter) Close() error {
+    return nil
+}
+
+// otlpAttributes converts attributes to OTLP KeyValue objects in key order
+func otlpAttributes(attributes map[string]interface{}) []interface{} {
+    keys := make([]string, 0, len(attributes))
+    for key := range attributes {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    values := make([]interface{}, 0, len(keys))
+    for _, key := range keys {
+        var value map[string]interface{}
+        switch v := attributes[key].(type) {
+        case bool:
+            value = map[string]interface{}{"boolValue": v}
+        case int:
+            value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+        case int64:
+            value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+        case float64:
+            value = map[string]interface{}{"doubleValue": v}
+        default:
+            value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+        }
+        values = append(values, map[string]interface{}{"key": key, "value": value})
+    }
+    return values
+}
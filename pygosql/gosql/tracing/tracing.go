@@ -0,0 +1,279 @@
+// tracing.go
+package tracing
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+)
+
+// TraceID identifies a trace across services
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether the id is not all zeros
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// IsValid reports whether the id is not all zeros
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is the part of a span that crosses process boundaries
+type SpanContext struct {
+    TraceID TraceID // Trace the span belongs to
+    SpanID  SpanID  // Span id
+    Sampled bool    // Whether the trace is recorded
+}
+
+// Span kinds, numbered as in OTLP
+const (
+    KindInternal = 1 // Work inside the server
+    KindServer   = 2 // Handling of an incoming request
+)
+
+// Span is a timed operation within a trace
+type Span struct {
+    Context    SpanContext            // Ids of this span
+    Parent     SpanID                 // Parent span id, zero for a root span
+    Name       string                 // Operation name
+    Kind       int                    // KindInternal or KindServer
+    Start      time.Time              // Start time
+    End        time.Time              // End time, zero while running
+    Attributes map[string]interface{} // Attributes set on the span
+    Error      string                 // Error message, empty when the span succeeded
+    tracer     *Tracer                // Tracer that exports the span
+    mu         sync.Mutex             // Guards Attributes, Error and End
+}
+
+// SetAttribute records a key value pair on the span; it does nothing on a nil span
+func (s *Span) SetAttribute(key string, value interface{}) {
+    if s == nil {
+        return
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.Attributes[key] = value
+}
+
+// SetName renames the span, for example once the route is known; it does nothing on a nil span
+func (s *Span) SetName(name string) {
+    if s == nil {
+        return
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.Name = name
+}
+
+// SetError marks the span as failed; it does nothing on a nil span or nil error
+func (s *Span) SetError(err error) {
+    if s == nil || err == nil {
+        return
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.Error = err.Error()
+}
+
+// Finish ends the span and queues it for export; it does nothing on a nil span
+func (s *Span) Finish() {
+    if s == nil {
+        return
+    }
+    s.mu.Lock()
+    if !s.End.IsZero() {
+        s.mu.Unlock()
+        return
+    }
+    s.End = time.Now()
+    s.mu.Unlock()
+    s.tracer.enqueue(s)
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a context whose new spans are children of span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+    return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span, nil when the request is not traced
+func SpanFromContext(ctx context.Context) *Span {
+    span, _ := ctx.Value(spanKey{}).(*Span)
+    return span
+}
+
+// Start begins a child of the current span, returning a nil span when the request is not traced
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+    parent := SpanFromContext(ctx)
+    if parent == nil {
+        return ctx, nil
+    }
+    return parent.tracer.start(ctx, name, KindInternal, parent.Context, parent.Context.SpanID)
+}
+
+// Tracer creates spans and exports finished ones in batches from a background goroutine
+type Tracer struct {
+    exporter Exporter      // Destination of finished spans
+    service  string        // service.name resource attribute
+    queue    chan *Span    // Finished spans waiting for export
+    done     chan struct{} // Closed when the export goroutine exits
+    once     sync.Once     // Closes queue once
+}
+
+// NewTracer starts a tracer exporting to exporter under the given service name
+func NewTracer(exporter Exporter, service string) *Tracer {
+    t := &Tracer{
+        exporter: exporter,
+        service:  service,
+        queue:    make(chan *Span, 2048),
+        done:     make(chan struct{}),
+    }
+    go t.run()
+    return t
+}
+
+// StartServer begins the root span of an incoming request, continuing the client's trace
+// when the request carries a W3C traceparent header; a nil tracer returns a nil span
+func (t *Tracer) StartServer(ctx context.Context, name string, header http.Header) (context.Context, *Span) {
+    if t == nil {
+        return ctx, nil
+    }
+    parent, ok := Extract(header)
+    if ok && !parent.Sampled {
+        return ctx, nil
+    }
+    if !ok {
+        parent = SpanContext{TraceID: newTraceID(), Sampled: true}
+    }
+    return t.start(ctx, name, KindServer, parent, parent.SpanID)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, kind int, trace SpanContext, parent SpanID) (context.Context, *Span) {
+    span := &Span{
+        Context:    SpanContext{TraceID: trace.TraceID, SpanID: newSpanID(), Sampled: true},
+        Parent:     parent,
+        Name:       name,
+        Kind:       kind,
+        Start:      time.Now(),
+        Attributes: make(map[string]interface{}),
+        tracer:     t,
+    }
+    return ContextWithSpan(ctx, span), span
+}
+
+// enqueue hands a finished span to the export goroutine, dropping it when the queue is full
+func (t *Tracer) enqueue(span *Span) {
+    defer func() { recover() }() // The queue is closed after Shutdown
+    select {
+    case t.queue <- span:
+    default:
+    }
+}
+
+// run exports spans in batches of up to 256, or every second
+func (t *Tracer) run() {
+    defer close(t.done)
+    ticker := time.NewTicker(time.Second)
+    defer ticker.Stop()
+
+    var batch []*Span
+    flush := func() {
+        if len(batch) > 0 {
+            if err := t.exporter.Export(t.service, batch); err != nil {
+                slog.Warn("failed to export spans", "spans", len(batch), "error", err)
+            }
+            batch = nil
+        }
+    }
+    for {
+        select {
+        case span, ok := <-t.queue:
+            if !ok {
+                flush()
+                return
+            }
+            batch = append(batch, span)
+            if len(batch) >= 256 {
+                flush()
+            }
+        case <-ticker.C:
+            flush()
+        }
+    }
+}
+
+// Shutdown exports the queued spans and closes the exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+    if t == nil {
+        return nil
+    }
+    t.once.Do(func() { close(t.queue) })
+    select {
+    case <-t.done:
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+    return t.exporter.Close()
+}
+
+// Extract parses a W3C traceparent header: version-traceid-spanid-flags
+func Extract(header http.Header) (SpanContext, bool) {
+    value := strings.TrimSpace(header.Get("traceparent"))
+    parts := strings.Split(value, "-")
+    if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+        return SpanContext{}, false
+    }
+    if parts[0] == "00" && len(parts) != 4 {
+        return SpanContext{}, false
+    }
+
+    var sc SpanContext
+    flags, err := hex.DecodeString(parts[3])
+    if _, err1 := hex.Decode(sc.TraceID[:], []byte(parts[1])); err1 != nil || err != nil {
+        return SpanContext{}, false
+    }
+    if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+        return SpanContext{}, false
+    }
+    if !sc.TraceID.IsValid() || !sc.SpanID.IsValid() {
+        return SpanContext{}, false
+    }
+    sc.Sampled = flags[0]&1 == 1
+    return sc, true
+}
+
+// Traceparent formats a span context as a W3C traceparent value
+func Traceparent(sc SpanContext) string {
+    flags := "00"
+    if sc.Sampled {
+        flags = "01"
+    }
+    return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+func newTraceID() TraceID {
+    var id TraceID
+    for !id.IsValid() {
+        rand.Read(id[:])
+    }
+    return id
+}
+
+func newSpanID() SpanID {
+    var id SpanID
+    for !id.IsValid() {
+        rand.Read(id[:])
+    }
+    return id
+}
@@ -1,13 +1,16 @@
 import asyncio
+import json
+import os
+import re
+import signal
 from dataclasses import dataclass
 from functools import cached_property
 from pathlib import Path
 from typing import Optional, Dict, List, Any, Callable
+from urllib.parse import quote, urlsplit
 
 import aiohttp
 from loguru import logger as log
-from pygops import GoServer
-from toomanyports import PortManager
 
 
 @dataclass
@@ -19,6 +22,7 @@ class Route:
     table_name: str
     sql_path: Optional[str] = None
     description: Optional[str] = None
+    function: Optional[str] = None
 
     @property
     def operation(self) -> str:
@@ -35,7 +39,9 @@ class Route:
 
     @property
     def function_name(self) -> str:
-        """Generate function name from operation."""
+        """Function name: the server's name for SQL endpoints, else the operation."""
+        if self.function:
+            return self.function
         operation = self.operation.lower()
         # Handle special cases
         if operation == 'root':
@@ -127,7 +133,12 @@ class APIRequester:
 
     async def _make_request(self, route: Route, **kwargs) -> Dict[str, Any]:
         """Make HTTP request to a route."""
-        url = f"{self.base_url}{route.path}"
+        # Fill {param} route segments from matching keyword arguments
+        path = route.path
+        for name in re.findall(r"\{(\w+)(?:\.\.\.)?\}", route.path):
+            if name in kwargs:
+                path = re.sub(r"\{" + name + r"(?:\.\.\.)?\}", quote(str(kwargs.pop(name)), safe=""), path)
+        url = f"{self.base_url}{path}"
 
         # Determine request parameters based on method
         request_kwargs = {}
@@ -190,6 +201,9 @@ class PyGoSQL:
 
     instance: Optional['PyGoSQL'] = None
 
+    # Seconds to wait for the ready line; go run compiles the server first
+    start_timeout: float = 120
+
     def __init__(self,
                  sql_root: Path = None,
                  #go_file: Path = Path("./gosql/main.go"),
@@ -198,16 +212,25 @@ class PyGoSQL:
                  base_url: Optional[str] = "/api/v1",
                  debug: bool = False,
                  cors: bool = True,
+                 api_key: Optional[str] = None,
+                 unix_socket: Optional[Path] = None,
                  verbose: bool = False) -> None:
-        """Initialize PyGoSQL client."""
+        """Initialize PyGoSQL client.
+
+        Pass api_key when the server runs with -auth; it is sent as a bearer token.
+        Pass unix_socket to talk to the server over a socket only the current user can open,
+        instead of a TCP port. Without a port the server picks a free one and reports it.
+        """
         # Server configuration
-        self._port = port or PortManager.random_port()
+        self._port = port or 0
         self._go_file = Path(__file__).parent / "gosql" / "main.go"
         self._sql_root = Path.cwd() / "sql" if sql_root is None else sql_root
         self._db_path = Path.cwd() / "sql" / "app.db" if db_path is None else db_path
         self._base_url = base_url
         self._debug = debug
         self._cors = cors
+        self._api_key = api_key
+        self._unix_socket = unix_socket
         self._verbose = verbose
 
         # Initialize state
@@ -220,6 +243,11 @@ class PyGoSQL:
         self._health: Optional[Callable] = None
         self._docs: Optional[Callable] = None
 
+        # Server process and the JSON line it writes to stdout once it accepts requests
+        self._process: Optional[asyncio.subprocess.Process] = None
+        self._ready: Optional[Dict[str, Any]] = None
+        self._stdout_task: Optional[asyncio.Task] = None
+
         go_args = []
         for k, v in {
             'port': self._port,
@@ -227,26 +255,33 @@ class PyGoSQL:
             'db': self._db_path,                   # Changed from 'db_path' to 'db'
             'base': self._base_url,                # Changed from 'base_url' to 'base'
             'debug': self._debug,
-            'cors': self._cors
+            'cors': self._cors,
+            'socket': self._unix_socket,
+            'bind': 'none' if self._unix_socket else None,
+            'parent-pid': os.getpid(),  # Server exits if this process dies without stopping it
         }.items():
             if v is not None:
-                go_args.extend([f"-{k}", str(v).lower() if isinstance(v, bool) else str(v)])
-
-        # Remove verbose from go_args since it's not a Go flag
-        self.server = GoServer(go_file=self._go_file, go_args=go_args, verbose=verbose)
+                # -name=value, since Go's flag package takes booleans only in that form
+                go_args.append(f"-{k}={str(v).lower() if isinstance(v, bool) else v}")
+        self._go_args = go_args
 
         if self._verbose:
             props = "\n".join(f"{k}: {v}" for k, v in vars(self).items())
             log.success(f"{self} Successfully initialized!\n{props}")
 
-    @cached_property
+    @property
     def port(self) -> int:
-        """Get the server port."""
+        """Get the server port, as reported by the server once launched."""
         return self._port
 
-    @cached_property
+    @property
     def base_url(self) -> str:
-        """Get the base URL for API requests."""
+        """Get the base URL for API requests, from the server's ready line once launched."""
+        if self._unix_socket:
+            return "http://localhost"
+        if self._ready:
+            url = urlsplit(self._ready['base_url'])
+            return f"{url.scheme}://{url.netloc}"
         return f"http://localhost:{self.port}"
 
     @property
@@ -267,7 +302,8 @@ class PyGoSQL:
         await self._start_server()
 
         # Create HTTP session
-        self._session = aiohttp.ClientSession()
+        headers = {"Authorization": f"Bearer {self._api_key}"} if self._api_key else None
+        self._session = self._new_session(headers)
 
         # Discover routes
         await self._discover_routes()
@@ -285,21 +321,71 @@ class PyGoSQL:
 
         if self._verbose: log.info(f"PyGoSQL launched successfully with {len(self._routes)} routes")
 
-    async def _start_server(self) -> None:
-        """Start the GoSQL server process."""
-        await self.server.start()
-
-        # Wait a moment for server to be ready
-        await asyncio.sleep(1)
+    def _new_session(self, headers: Optional[Dict[str, str]] = None) -> aiohttp.ClientSession:
+        """Create an HTTP session, connected through the Unix socket when one is configured."""
+        connector = aiohttp.UnixConnector(path=str(self._unix_socket)) if self._unix_socket else None
+        return aiohttp.ClientSession(headers=headers, connector=connector)
 
-        # Verify server is running
+    async def _start_server(self) -> None:
+        """Start the GoSQL server process and wait for the ready line it writes to stdout."""
+        self._process = await asyncio.create_subprocess_exec(
+            "go", "run", self._go_file.name, *self._go_args,
+            cwd=str(self._go_file.parent),
+            stdout=asyncio.subprocess.PIPE,
+        )
         try:
-            async with aiohttp.ClientSession() as session:
-                async with session.get(f"{self.base_url}/health") as response:
-                    if response.status != 200:
-                        raise RuntimeError(f"Server health check failed: {response.status}")
-        except Exception as e:
-            raise RuntimeError(f"Failed to verify server startup: {e}")
+            self._ready = await asyncio.wait_for(self._read_ready_line(), timeout=self.start_timeout)
+        except asyncio.TimeoutError:
+            await self._stop_server()
+            raise RuntimeError(f"GoSQL server was not ready after {self.start_timeout}s")
+        except Exception:
+            await self._stop_server()
+            raise
+
+        self._port = self._ready['port']
+        self._stdout_task = asyncio.create_task(self._drain_stdout(self._process.stdout))
+        if self._verbose: log.debug(f"GoSQL server ready: {self._ready}")
+
+    async def _read_ready_line(self) -> Dict[str, Any]:
+        """Read stdout until the server's {"event": "ready"} line."""
+        while True:
+            line = await self._process.stdout.readline()
+            if not line:
+                code = await self._process.wait()
+                raise RuntimeError(f"GoSQL server exited with status {code} before it was ready")
+            try:
+                message = json.loads(line)
+            except ValueError:
+                message = None
+            if isinstance(message, dict) and message.get('event') == 'ready':
+                return message
+            if self._verbose: log.debug(f"gosql: {line.decode(errors='replace').rstrip()}")
+
+    async def _drain_stdout(self, stdout: asyncio.StreamReader) -> None:
+        """Keep reading stdout so the server never blocks on a full pipe."""
+        while line := await stdout.readline():
+            if self._verbose: log.debug(f"gosql: {line.decode(errors='replace').rstrip()}")
+
+    async def _stop_server(self) -> None:
+        """Stop the server process, killing it when it does not shut down in time."""
+        process, self._process = self._process, None
+        if process is None:
+            return
+        if process.returncode is None:
+            # go run does not pass signals on to the server it built, so signal the server itself
+            pid = self._ready['pid'] if self._ready else process.pid
+            try:
+                os.kill(pid, signal.SIGTERM)
+            except ProcessLookupError:
+                pass
+            try:
+                await asyncio.wait_for(process.wait(), timeout=10)
+            except asyncio.TimeoutError:
+                process.kill()
+                await process.wait()
+        if self._stdout_task:
+            await self._stdout_task
+            self._stdout_task = None
 
     async def _discover_routes(self) -> None:
         """Discover all routes by fetching root endpoint."""
@@ -318,7 +404,8 @@ class PyGoSQL:
                     path=endpoint_data['path'],
                     is_universal=endpoint_data['is_universal'],
                     table_name=endpoint_data.get('table_name', ''),
-                    sql_path=endpoint_data.get('sql_path')
+                    sql_path=endpoint_data.get('sql_path'),
+                    function=endpoint_data.get('function')
                 )
                 api_routes.append(route)
 
@@ -380,8 +467,8 @@ class PyGoSQL:
             await self._session.close()
             self._session = None
 
-        if self.server:
-            await self.server.stop()
+        await self._stop_server()
+        self._ready = None
 
         self._requester = None
         self._server_info = None
@@ -393,7 +480,7 @@ class PyGoSQL:
         """Dynamic namespace access."""
         log.debug(f"Attempting to retrieve attribute, {name}...")
         # Don't intercept private attributes or known instance attributes
-        if name.startswith('_') or name in ('server', 'logger', 'verbose', 'log'):  # Remove 'views' from here
+        if name.startswith('_') or name in ('logger', 'verbose', 'log'):  # Remove 'views' from here
             raise AttributeError(f"'{self.__class__.__name__}' object has no attribute '{name}'")
 
         # Check for class-level descriptors first (like your cached_property views)
@@ -1,11 +1,10 @@
+import asyncio
 import logging
 from functools import cached_property
 from pathlib import Path
 from typing import Optional, Dict, List, Any, Callable
 
 import aiohttp
-from pygops import GoServer
-from toomanyports import PortManager
 
 
 def setup_logging(verbose: bool = False) -> logging.Logger:
@@ -21,10 +20,12 @@ class Route:
     table_name: str
     sql_path: Optional[str]
     description: Optional[str]
+    function: Optional[str]
 
     def __init__(self, method: str, path: str, is_universal: bool,
                  table_name: str, sql_path: Optional[str] = None,
-                 description: Optional[str] = None) -> None:
+                 description: Optional[str] = None,
+                 function: Optional[str] = None) -> None:
         """Initialize Route."""
         ...
 
@@ -40,7 +41,7 @@ class Route:
 
     @property
     def function_name(self) -> str:
-        """Generate function name from operation."""
+        """Function name: the server's name for SQL endpoints, else the operation."""
 
 
 class NamespaceObject:
@@ -155,10 +156,12 @@ class PyGoSQL:
                  base_url: Optional[str] = "/api/v1",
                  debug: bool = False,
                  cors: bool = True,
+                 api_key: Optional[str] = None,
+                 unix_socket: Optional[Path] = None,
                  verbose: bool = False) -> None:
         """Initialize PyGoSQL client."""
         # Server configuration
-        self._port = port or PortManager.random_port()
+        self._port = port or 0
         self._go_file = go_file
         self._db_path = db_path
         self._sql_root = sql_root
@@ -177,34 +180,19 @@ class PyGoSQL:
         self._health: Optional[Callable] = None
         self._docs: Optional[Callable] = None
 
-        # Build kwargs for GoServer, excluding None values
-        server_kwargs = {
-            'go_file': self._go_file,
-            'port': self._port,
-            'sql_root': self._sql_root,
-            'verbose': verbose
-        }
-
-        server_kwargs.update({
-            k: v for k, v in {
-                'db_path': self._db_path,
-                'base_url': self._base_url,
-                'debug': self._debug,
-                'cors': self._cors
-            }.items() if v is not None
-        })
-
-        self.server = GoServer(**server_kwargs)
+        # Server process and the JSON line it writes to stdout once it accepts requests
+        self._process: Optional[asyncio.subprocess.Process] = None
+        self._ready: Optional[Dict[str, Any]] = None
 
-    @cached_property
+    @property
     def port(self) -> int:
-        """Get the server port."""
+        """Get the server port, as reported by the server once launched."""
         return self._port
 
     @property
     def base_url(self) -> str:
-        """Get the base URL for API requests."""
-        return f"http://localhost:{self.port}"
+        """Get the base URL for API requests, from the server's ready line once launched."""
+        ...
 
     @property
     def server_info(self) -> Optional[Dict[str, Any]]: